@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"github.com/araquach/phorest-datahub/internal/api"
+	"github.com/araquach/phorest-datahub/internal/config"
+	"github.com/araquach/phorest-datahub/internal/db"
+	"github.com/araquach/phorest-datahub/internal/repos"
+)
+
+func main() {
+	_ = godotenv.Load()
+
+	cfg := config.Load()
+	logger := cfg.Logger
+
+	gdb, err := db.Open(cfg.DatabaseURL)
+	if err != nil {
+		logger.Fatalf("DB connection failed: %v", err)
+	}
+	defer db.Close(gdb)
+
+	if err := db.HealthCheck(gdb, 3*time.Second); err != nil {
+		logger.Fatalf("DB health check failed: %v", err)
+	}
+	logger.Println("✅ Database connection healthy.")
+
+	// Runs started by a previous API process can never finish now.
+	if n, err := repos.NewSyncRunsRepo(gdb, logger).MarkAbandoned("api", 0); err != nil {
+		logger.Printf("⚠️  could not mark abandoned sync runs: %v", err)
+	} else if n > 0 {
+		logger.Printf("ℹ️ Marked %d abandoned sync run(s) as failed", n)
+	}
+
+	server, err := api.NewServer(gdb, cfg, logger)
+	if err != nil {
+		logger.Fatalf("❌ %v", err)
+	}
+	srv := server.HTTPServer()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		logger.Printf("🌐 API listening on %s", srv.Addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Fatalf("API server failed: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	logger.Println("🛑 Shutting down API…")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Printf("⚠️  graceful shutdown failed: %v", err)
+	}
+}
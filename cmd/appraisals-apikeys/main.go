@@ -0,0 +1,136 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"github.com/araquach/phorest-datahub/internal/api"
+	"github.com/araquach/phorest-datahub/internal/config"
+	"github.com/araquach/phorest-datahub/internal/db"
+	"github.com/araquach/phorest-datahub/internal/models"
+	"github.com/araquach/phorest-datahub/internal/repos"
+)
+
+const usage = `usage:
+  appraisals-apikeys create -name NAME [-scopes read:kpis,read:clients-pii,admin:sync] [-branches ID,ID] [-expires 2026-12-31]
+  appraisals-apikeys list
+  appraisals-apikeys revoke -prefix PREFIX`
+
+func main() {
+	_ = godotenv.Load()
+
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cfg := config.Load()
+	logger := cfg.Logger
+
+	gdb, err := db.Open(cfg.DatabaseURL)
+	if err != nil {
+		logger.Fatalf("DB connection failed: %v", err)
+	}
+	defer db.Close(gdb)
+
+	repo := repos.NewAPIKeysRepo(gdb, logger)
+
+	switch os.Args[1] {
+	case "create":
+		fs := flag.NewFlagSet("create", flag.ExitOnError)
+		name := fs.String("name", "", "who/what the key is for (required)")
+		scopes := fs.String("scopes", "", "comma-separated scopes: "+strings.Join(api.KnownScopes, ", "))
+		branches := fs.String("branches", "", "comma-separated branch IDs this key may see (default: all)")
+		expires := fs.String("expires", "", "expiry date YYYY-MM-DD (default: never)")
+		_ = fs.Parse(os.Args[2:])
+
+		if *name == "" {
+			logger.Fatalf("-name is required")
+		}
+
+		k := &models.APIKey{
+			Name:      *name,
+			Scopes:    *scopes,
+			BranchIDs: *branches,
+		}
+		for _, s := range k.ScopeList() {
+			if !slices.Contains(api.KnownScopes, s) {
+				logger.Fatalf("unknown scope %q (known: %s)", s, strings.Join(api.KnownScopes, ", "))
+			}
+		}
+		if *expires != "" {
+			t, err := time.Parse("2006-01-02", *expires)
+			if err != nil {
+				logger.Fatalf("invalid -expires: %v", err)
+			}
+			k.ExpiresAt = &t
+		}
+
+		plaintext, prefix, hash, err := api.NewAPIKey()
+		if err != nil {
+			logger.Fatalf("generate key: %v", err)
+		}
+		k.KeyPrefix = prefix
+		k.KeyHash = hash
+
+		if err := repo.Create(k); err != nil {
+			logger.Fatalf("create key: %v", err)
+		}
+
+		logger.Printf("✅ Created API key %q (prefix %s)", k.Name, k.KeyPrefix)
+		fmt.Println("API key (shown once, store it now):")
+		fmt.Println(plaintext)
+
+	case "list":
+		keys, err := repo.List()
+		if err != nil {
+			logger.Fatalf("list keys: %v", err)
+		}
+		for _, k := range keys {
+			state := "active"
+			switch {
+			case k.RevokedAt != nil:
+				state = "revoked"
+			case k.ExpiresAt != nil && k.ExpiresAt.Before(time.Now()):
+				state = "expired"
+			}
+			branches := k.BranchIDs
+			if branches == "" {
+				branches = "ALL"
+			}
+			lastUsed := "never"
+			if k.LastUsedAt != nil {
+				lastUsed = k.LastUsedAt.UTC().Format(time.RFC3339)
+			}
+			fmt.Printf("%s  %-8s  %-24s  scopes=[%s]  branches=[%s]  last_used=%s\n",
+				k.KeyPrefix, state, k.Name, k.Scopes, branches, lastUsed)
+		}
+
+	case "revoke":
+		fs := flag.NewFlagSet("revoke", flag.ExitOnError)
+		prefix := fs.String("prefix", "", "key prefix to revoke (required)")
+		_ = fs.Parse(os.Args[2:])
+
+		if *prefix == "" {
+			logger.Fatalf("-prefix is required")
+		}
+		ok, err := repo.Revoke(*prefix)
+		if err != nil {
+			logger.Fatalf("revoke key: %v", err)
+		}
+		if !ok {
+			logger.Fatalf("no active key with prefix %s", *prefix)
+		}
+		logger.Printf("✅ Revoked API key %s", *prefix)
+
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
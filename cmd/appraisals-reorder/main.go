@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"github.com/araquach/phorest-datahub/internal/config"
+	"github.com/araquach/phorest-datahub/internal/db"
+	"github.com/araquach/phorest-datahub/internal/reports"
+	"github.com/araquach/phorest-datahub/internal/services"
+)
+
+// appraisals-reorder writes a stock reorder list (products at or below their
+// minimum, topped up to max) per branch as HTML and/or CSV.
+func main() {
+	_ = godotenv.Load()
+
+	branchID := flag.String("branch", "", "Phorest branch ID (default: every configured branch)")
+	format := flag.String("format", "both", "output format: html, csv or both")
+	outDir := flag.String("out", "data/reports", "output directory")
+	flag.Parse()
+
+	cfg := config.Load()
+	logger := cfg.Logger
+
+	if *format != "html" && *format != "csv" && *format != "both" {
+		logger.Fatalf("-format must be html, csv or both")
+	}
+
+	branches := []string{*branchID}
+	if *branchID == "" {
+		branches = branches[:0]
+		for _, b := range cfg.Branches {
+			branches = append(branches, b.BranchID)
+		}
+	}
+
+	gdb, err := db.Open(cfg.DatabaseURL)
+	if err != nil {
+		logger.Fatalf("DB connection failed: %v", err)
+	}
+	defer db.Close(gdb)
+
+	if err := os.MkdirAll(*outDir, 0o755); err != nil {
+		logger.Fatalf("create output dir %q: %v", *outDir, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	builder := reports.NewReorderBuilder(gdb, logger)
+	stamp := time.Now().UTC().Format("20060102")
+	for _, id := range branches {
+		doc, err := builder.Build(ctx, id)
+		if err != nil {
+			logger.Fatalf("build reorder list: %v", err)
+		}
+		base := filepath.Join(*outDir, fmt.Sprintf("reorder_%s_%s", id, stamp))
+
+		if *format == "html" || *format == "both" {
+			if err := writeFile(base+".html", func(f *os.File) error { return reports.RenderReorderHTML(f, doc) }); err != nil {
+				logger.Fatalf("write HTML reorder list: %v", err)
+			}
+			logger.Printf("📝 Wrote %s.html", base)
+		}
+		if *format == "csv" || *format == "both" {
+			if err := writeFile(base+".csv", func(f *os.File) error { return reports.WriteReorderCSV(f, doc) }); err != nil {
+				logger.Fatalf("write CSV reorder list: %v", err)
+			}
+			logger.Printf("📝 Wrote %s.csv", base)
+		}
+		logger.Printf("📦 %s: %d product(s) to reorder, %s", doc.BranchName, doc.Lines,
+			reports.FormatValue(services.KindMoney, doc.Cost, doc.Currency))
+	}
+}
+
+func writeFile(path string, render func(f *os.File) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := render(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
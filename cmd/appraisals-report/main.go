@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"github.com/araquach/phorest-datahub/internal/config"
+	"github.com/araquach/phorest-datahub/internal/db"
+	"github.com/araquach/phorest-datahub/internal/reports"
+	"github.com/araquach/phorest-datahub/internal/services"
+)
+
+func main() {
+	_ = godotenv.Load()
+
+	staffID := flag.String("staff", "", "Phorest staff ID (required)")
+	branchID := flag.String("branch", "", "Phorest branch ID (default: the stylist's primary branch)")
+	fromStr := flag.String("from", "", "period start YYYY-MM-DD (default: start of last quarter)")
+	toStr := flag.String("to", "", "period end YYYY-MM-DD, inclusive (default: end of last quarter)")
+	format := flag.String("format", "both", "output format: html, pdf or both")
+	outDir := flag.String("out", "data/reports", "output directory")
+	flag.Parse()
+
+	cfg := config.Load()
+	logger := cfg.Logger
+
+	if *staffID == "" {
+		logger.Fatalf("-staff is required")
+	}
+
+	period, err := parsePeriod(*fromStr, *toStr, time.Now().UTC())
+	if err != nil {
+		logger.Fatalf("invalid period: %v", err)
+	}
+
+	gdb, err := db.Open(cfg.DatabaseURL)
+	if err != nil {
+		logger.Fatalf("DB connection failed: %v", err)
+	}
+	defer db.Close(gdb)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	builder := reports.NewAppraisalBuilder(gdb, logger)
+	rep, err := builder.Build(ctx, *staffID, *branchID, period)
+	if err != nil {
+		logger.Fatalf("build appraisal report: %v", err)
+	}
+
+	if err := os.MkdirAll(*outDir, 0o755); err != nil {
+		logger.Fatalf("create output dir %q: %v", *outDir, err)
+	}
+	base := filepath.Join(*outDir, fmt.Sprintf("appraisal_%s_%s_%s",
+		slug(rep.StaffName), period.From.Format("20060102"), period.To.Format("20060102")))
+
+	if *format == "html" || *format == "both" {
+		if err := writeFile(base+".html", func(f *os.File) error { return reports.RenderHTML(f, rep) }); err != nil {
+			logger.Fatalf("write HTML report: %v", err)
+		}
+		logger.Printf("📝 Wrote %s.html", base)
+	}
+	if *format == "pdf" || *format == "both" {
+		if err := writeFile(base+".pdf", func(f *os.File) error { return reports.RenderPDF(f, rep) }); err != nil {
+			logger.Fatalf("write PDF report: %v", err)
+		}
+		logger.Printf("📝 Wrote %s.pdf", base)
+	}
+
+	logger.Printf("✅ Appraisal report for %s (%s) complete.", rep.StaffName, period)
+}
+
+// parsePeriod defaults to the last complete calendar quarter, which is how
+// appraisal decks are currently put together.
+func parsePeriod(fromStr, toStr string, now time.Time) (services.Period, error) {
+	qStartMonth := time.Month(((int(now.Month())-1)/3)*3 + 1)
+	thisQuarter := time.Date(now.Year(), qStartMonth, 1, 0, 0, 0, 0, time.UTC)
+	from := thisQuarter.AddDate(0, -3, 0)
+	to := thisQuarter.AddDate(0, 0, -1)
+
+	var err error
+	if fromStr != "" {
+		if from, err = time.Parse("2006-01-02", fromStr); err != nil {
+			return services.Period{}, fmt.Errorf("-from: %w", err)
+		}
+	}
+	if toStr != "" {
+		if to, err = time.Parse("2006-01-02", toStr); err != nil {
+			return services.Period{}, fmt.Errorf("-to: %w", err)
+		}
+	}
+	if to.Before(from) {
+		return services.Period{}, fmt.Errorf("-to %s is before -from %s", to.Format("2006-01-02"), from.Format("2006-01-02"))
+	}
+	return services.NewPeriod(from, to), nil
+}
+
+func writeFile(path string, render func(f *os.File) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := render(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func slug(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == ' ' || r == '-' || r == '_':
+			b.WriteByte('_')
+		}
+	}
+	if b.Len() == 0 {
+		return "staff"
+	}
+	return b.String()
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"github.com/araquach/phorest-datahub/internal/config"
+	"github.com/araquach/phorest-datahub/internal/db"
+	"github.com/araquach/phorest-datahub/internal/reports"
+	"github.com/araquach/phorest-datahub/internal/repos"
+	"github.com/araquach/phorest-datahub/internal/services"
+)
+
+// appraisals-stock writes stock reports per branch as CSV:
+//
+//	valuation   stock value at cost and retail at the end of -date (month-end figures)
+//	movements   opening/closing/net stock per product for -from..-to
+//	dead-stock  products in stock with no retail sales in the last -days
+//	shrinkage   stock decreases over -from..-to not explained by retail sales,
+//	            saved to stock_shrinkage
+//	backbar     estimated professional product cost per stylist and service
+//	            over -from..-to
+func main() {
+	_ = godotenv.Load()
+
+	report := flag.String("report", "valuation", "report: valuation, movements, dead-stock, shrinkage or backbar")
+	branchID := flag.String("branch", "", "Phorest branch ID (default: every configured branch)")
+	date := flag.String("date", "", "valuation / dead-stock date YYYY-MM-DD (default: today)")
+	from := flag.String("from", "", "movements / shrinkage / backbar start date YYYY-MM-DD (default: first of last month)")
+	to := flag.String("to", "", "movements / shrinkage / backbar end date YYYY-MM-DD, inclusive (default: end of last month)")
+	days := flag.Int("days", 90, "dead-stock: days without a sale")
+	movedOnly := flag.Bool("moved-only", false, "movements: skip products whose stock didn't change")
+	outDir := flag.String("out", "data/reports", "output directory")
+	flag.Parse()
+
+	cfg := config.Load()
+	logger := cfg.Logger
+
+	asOf, err := parseDay(*date, time.Now().UTC())
+	if err != nil {
+		logger.Fatalf("-date: %v", err)
+	}
+	monthStart := time.Date(asOf.Year(), asOf.Month(), 1, 0, 0, 0, 0, time.UTC)
+	start, err := parseDay(*from, monthStart.AddDate(0, -1, 0))
+	if err != nil {
+		logger.Fatalf("-from: %v", err)
+	}
+	end, err := parseDay(*to, monthStart.AddDate(0, 0, -1))
+	if err != nil {
+		logger.Fatalf("-to: %v", err)
+	}
+	period := services.NewPeriod(start, end)
+
+	switch *report {
+	case "valuation", "movements", "shrinkage", "backbar":
+	case "dead-stock":
+		if *days < 1 {
+			logger.Fatalf("-days must be at least 1")
+		}
+	default:
+		logger.Fatalf("-report must be valuation, movements, dead-stock, shrinkage or backbar")
+	}
+
+	branches := []string{*branchID}
+	if *branchID == "" {
+		branches = branches[:0]
+		for _, b := range cfg.Branches {
+			branches = append(branches, b.BranchID)
+		}
+	}
+
+	gdb, err := db.Open(cfg.DatabaseURL)
+	if err != nil {
+		logger.Fatalf("DB connection failed: %v", err)
+	}
+	defer db.Close(gdb)
+
+	if err := os.MkdirAll(*outDir, 0o755); err != nil {
+		logger.Fatalf("create output dir %q: %v", *outDir, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	stock := services.NewStockService(gdb, logger)
+	shrinkage := repos.NewStockShrinkageRepo(gdb, logger)
+	for _, id := range branches {
+		var (
+			name  string
+			write func(io.Writer) error
+		)
+		switch *report {
+		case "valuation":
+			v, err := stock.Valuation(ctx, id, asOf)
+			if err != nil {
+				logger.Fatalf("stock valuation for %s: %v", id, err)
+			}
+			logger.Printf("📦 %s: %d product(s), %g units, cost %.2f, retail %.2f (%d uncosted)",
+				id, v.Products, v.Units, v.CostValue, v.RetailValue, v.Uncosted)
+			name = fmt.Sprintf("stock_valuation_%s_%s.csv", id, asOf.Format("20060102"))
+			write = func(w io.Writer) error { return reports.WriteValuationCSV(w, v) }
+
+		case "movements":
+			moves, err := stock.Movements(ctx, id, period, *movedOnly)
+			if err != nil {
+				logger.Fatalf("stock movements for %s: %v", id, err)
+			}
+			logger.Printf("📦 %s: %d product(s) %s..%s", id, len(moves),
+				period.From.Format("2006-01-02"), period.To.Format("2006-01-02"))
+			name = fmt.Sprintf("stock_movements_%s_%s_%s.csv", id, period.From.Format("20060102"), period.To.Format("20060102"))
+			write = func(w io.Writer) error { return reports.WriteMovementsCSV(w, id, period, moves) }
+
+		case "dead-stock":
+			lines, err := stock.DeadStock(ctx, id, *days, asOf)
+			if err != nil {
+				logger.Fatalf("dead stock for %s: %v", id, err)
+			}
+			logger.Printf("📦 %s: %d product(s) unsold in %d days", id, len(lines), *days)
+			name = fmt.Sprintf("dead_stock_%s_%s_%dd.csv", id, asOf.Format("20060102"), *days)
+			write = func(w io.Writer) error { return reports.WriteDeadStockCSV(w, id, *days, lines) }
+
+		case "shrinkage":
+			rows, err := stock.Shrinkage(ctx, id, period)
+			if err != nil {
+				logger.Fatalf("shrinkage for %s: %v", id, err)
+			}
+			if err := shrinkage.ReplacePeriod(id, period.From, period.To, rows); err != nil {
+				logger.Fatalf("save shrinkage for %s: %v", id, err)
+			}
+			sum := services.SummariseShrinkage(rows)
+			logger.Printf("🚨 %s: %d of %d product(s) flagged, %g unit(s) shrinkage (cost %.2f), %g unit(s) professional use (cost %.2f)",
+				id, sum.Flagged, sum.Products, sum.Shrinkage, sum.ShrinkageCost, sum.ProfessionalUse, sum.ProfessionalCost)
+			name = fmt.Sprintf("shrinkage_%s_%s_%s.csv", id, period.From.Format("20060102"), period.To.Format("20060102"))
+			write = func(w io.Writer) error { return reports.WriteShrinkageCSV(w, rows) }
+
+		case "backbar":
+			rep, err := stock.BackBarUsage(ctx, id, period)
+			if err != nil {
+				logger.Fatalf("back-bar usage for %s: %v", id, err)
+			}
+			logger.Printf("📦 %s: %g service(s), product cost %.2f (%.2f per service, %d uncosted product(s))",
+				id, rep.Services, rep.ProductCost, rep.CostPerService, rep.Uncosted)
+			name = fmt.Sprintf("backbar_%s_%s_%s.csv", id, period.From.Format("20060102"), period.To.Format("20060102"))
+			write = func(w io.Writer) error { return reports.WriteBackBarCSV(w, rep) }
+		}
+
+		path := filepath.Join(*outDir, name)
+		if err := writeFile(path, write); err != nil {
+			logger.Fatalf("write %s: %v", path, err)
+		}
+		logger.Printf("📝 Wrote %s", path)
+	}
+}
+
+func parseDay(s string, def time.Time) (time.Time, error) {
+	if s == "" {
+		return def, nil
+	}
+	return time.Parse("2006-01-02", s)
+}
+
+func writeFile(path string, write func(w io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
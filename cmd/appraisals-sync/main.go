@@ -3,16 +3,28 @@ package main
 import (
 	"context"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/joho/godotenv"
 
+	"github.com/araquach/phorest-datahub/internal/alerts"
+	"github.com/araquach/phorest-datahub/internal/analysis"
 	"github.com/araquach/phorest-datahub/internal/config"
 	"github.com/araquach/phorest-datahub/internal/db"
+	"github.com/araquach/phorest-datahub/internal/models"
+	"github.com/araquach/phorest-datahub/internal/notify"
 	"github.com/araquach/phorest-datahub/internal/phorest"
+	"github.com/araquach/phorest-datahub/internal/repos"
 )
 
+// Exit codes: 0 = every sync succeeded, 3 = partial failure (some branches or
+// entities failed, the rest were saved), 1 = nothing synced or startup failed.
 func main() {
+	os.Exit(run())
+}
+
+func run() int {
 	_ = godotenv.Load()
 
 	cfg := config.Load()
@@ -48,27 +60,84 @@ func main() {
 
 	logger.Println("✅ Startup complete. Ready to sync Phorest data.")
 
-	runner := phorest.NewRunner(gdb, cfg, logger)
+	runner, err := phorest.NewRunner(gdb, cfg, logger)
+	if err != nil {
+		logger.Fatalf("❌ %v", err)
+	}
+
+	// Parse strategies up front: a bad value must not leave a sync run
+	// recorded as running.
+	var (
+		reviewStrategy  phorest.ReviewStrategy
+		productStrategy phorest.ProductStrategy
+	)
+	if os.Getenv("RUN_REVIEWS_SYNC") == "1" || os.Getenv("RUN_REVIEWS_INCREMENTAL") == "1" {
+		if reviewStrategy, err = phorest.ParseReviewStrategy(os.Getenv("REVIEWS_STRATEGY")); err != nil {
+			logger.Printf("❌ REVIEWS_STRATEGY: %v", err)
+			return 1
+		}
+	}
+	if os.Getenv("RUN_PRODUCTS_SYNC") == "1" {
+		if productStrategy, err = phorest.ParseProductStrategy(os.Getenv("PRODUCTS_STRATEGY")); err != nil {
+			logger.Printf("❌ PRODUCTS_STRATEGY: %v", err)
+			return 1
+		}
+	}
+
+	// Record this invocation so watermark history can point back at it.
+	runs := repos.NewSyncRunsRepo(gdb, logger)
+	if n, err := runs.MarkAbandoned("cli", 24*time.Hour); err == nil && n > 0 {
+		logger.Printf("ℹ️ Marked %d abandoned CLI sync run(s) as failed", n)
+	}
+	runRec := &models.SyncRun{Kind: phorest.SyncKindScheduled, TriggeredBy: "cli"}
+	if err := runs.Start(runRec); err != nil {
+		logger.Printf("⚠️  could not record sync run: %v", err)
+		runRec = nil
+	} else {
+		runner.RunID = &runRec.ID
+		logger.Printf("🧾 Sync run #%d", runRec.ID)
+	}
 
 	// ---------- BOOTSTRAP PHASE ----------
 
+	// Bootstrap failures stop the run; say so and close the run record
+	// before exiting.
+	fatal := func(what string, err error) int {
+		_ = runner.Notify.Publish(context.Background(), notify.Event{
+			Type:     notify.EventSyncFailed,
+			Severity: notify.SeverityCritical,
+			BranchID: "ALL",
+			Title:    what + " failed",
+			Body:     err.Error(),
+		})
+		logger.Printf("❌ %s failed: %v", what, err)
+		if runRec != nil {
+			if ferr := runs.Finish(runRec.ID, models.SyncRunFailed, err.Error(), ""); ferr != nil {
+				logger.Printf("⚠️  could not record sync run result: %v", ferr)
+			}
+		}
+		return 1
+	}
+
 	// Clients + transactions from local CSVs (only on fresh DB)
 	if err := runner.BootstrapFromCSVsIfNeeded(); err != nil {
-		logger.Fatalf("CSV bootstrap failed: %v", err)
+		return fatal("CSV bootstrap", err)
 	}
 
 	// Reviews from local CSV backups (only on fresh DB)
 	if err := runner.BootstrapReviewsFromCSVsIfNeeded(); err != nil {
-		logger.Fatalf("Reviews CSV bootstrap failed: %v", err)
+		return fatal("Reviews CSV bootstrap", err)
 	}
 
 	// ---------- ONGOING “EVERY RUN” API SYNC ----------
 
-	if err := runner.SyncStaffFromAPI(); err != nil {
-		logger.Fatalf("staff sync failed: %v", err)
+	// Sync errors are recorded in runner.Report and summarised at the end;
+	// one entity failing doesn't stop the others.
+	if err := runner.SyncStaffFromAPI(context.Background()); err != nil {
+		logger.Printf("staff sync ended with errors: %v", err)
 	}
 
-	if err := runner.SyncBranchesFromAPI(); err != nil {
+	if err := runner.SyncBranchesFromAPI(context.Background()); err != nil {
 		logger.Printf("branch sync ended with errors: %v", err)
 	}
 
@@ -82,10 +151,10 @@ func main() {
 		defer cancel()
 
 		if err := runner.RunIncrementalClientsSync(ctx); err != nil {
-			logger.Fatalf("CLIENT_CSV incremental sync failed: %v", err)
+			logger.Printf("❌ CLIENT_CSV incremental sync failed: %v", err)
+		} else {
+			logger.Println("✅ Incremental CLIENT_CSV sync complete.")
 		}
-
-		logger.Println("✅ Incremental CLIENT_CSV sync complete.")
 	}
 
 	// Transactions incremental (TRANSACTIONS_CSV)
@@ -96,31 +165,127 @@ func main() {
 		defer cancel()
 
 		if err := runner.RunIncrementalTransactionsSync(ctx); err != nil {
-			logger.Fatalf("TRANSACTIONS_CSV incremental sync failed: %v", err)
+			logger.Printf("❌ TRANSACTIONS_CSV incremental sync ended with errors: %v", err)
+		} else {
+			logger.Println("✅ Incremental TRANSACTIONS_CSV sync complete.")
+		}
+	}
+
+	// Reviews: REVIEWS_STRATEGY picks incremental (default), full, latest or
+	// reconcile. RUN_REVIEWS_INCREMENTAL=1 is kept as an alias for cron jobs.
+	if os.Getenv("RUN_REVIEWS_SYNC") == "1" || os.Getenv("RUN_REVIEWS_INCREMENTAL") == "1" {
+		strategy := reviewStrategy
+		latestN, _ := strconv.Atoi(os.Getenv("REVIEWS_LATEST_N"))
+
+		logger.Printf("🚀 Running REVIEWS sync (strategy=%s)…", strategy)
+
+		timeout := 10 * time.Minute
+		if strategy == phorest.ReviewStrategyFull || strategy == phorest.ReviewStrategyReconcile {
+			timeout = 60 * time.Minute
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		if err := runner.SyncReviews(ctx, strategy, latestN); err != nil {
+			logger.Printf("❌ REVIEWS sync ended with errors: %v", err)
+		} else {
+			logger.Println("✅ REVIEWS sync complete.")
+		}
+	}
+
+	// Re-run the offline review analysis on its own (e.g. after a lexicon change).
+	if os.Getenv("RUN_REVIEW_ANALYSIS") == "1" {
+		if _, err := analysis.NewPipeline(gdb, logger).Run(context.Background()); err != nil {
+			logger.Printf("❌ REVIEW ANALYSIS ended with errors: %v", err)
 		}
+	}
 
-		logger.Println("✅ Incremental TRANSACTIONS_CSV sync complete.")
+	// Products: PRODUCTS_STRATEGY=reconcile forces a full listing that marks
+	// products Phorest no longer returns as removed (otherwise one runs every
+	// PRODUCTS_RECONCILE_EVERY).
+	if os.Getenv("RUN_PRODUCTS_SYNC") == "1" {
+		strategy := productStrategy
+		logger.Printf("🚀 Running PRODUCTS sync (strategy=%s)…", strategy)
+		if err := runner.SyncProductsFromAPI(context.Background(), strategy); err != nil {
+			logger.Printf("❌ PRODUCTS sync ended with errors: %v", err)
+		} else {
+			logger.Println("✅ PRODUCTS sync complete.")
+		}
 	}
 
-	// Reviews incremental
-	if os.Getenv("RUN_REVIEWS_INCREMENTAL") == "1" {
-		logger.Println("🚀 Running incremental REVIEWS sync…")
+	// Appointments incremental (appointments_api watermark)
+	if os.Getenv("RUN_APPOINTMENTS_SYNC") == "1" {
+		logger.Println("🚀 Running incremental APPOINTMENTS sync…")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+		defer cancel()
+
+		if err := runner.RunIncrementalAppointmentsSync(ctx); err != nil {
+			logger.Printf("❌ APPOINTMENTS sync ended with errors: %v", err)
+		} else {
+			logger.Println("✅ APPOINTMENTS sync complete.")
+		}
+	}
+
+	// Service catalogue: durations, list prices and their history
+	if os.Getenv("RUN_SERVICES_SYNC") == "1" {
+		logger.Println("🚀 Running SERVICES sync…")
 
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
 		defer cancel()
 
-		if err := runner.RunIncrementalReviewsSync(ctx); err != nil {
-			logger.Fatalf("REVIEWS incremental sync failed: %v", err)
+		if err := runner.SyncServicesFromAPI(ctx); err != nil {
+			logger.Printf("❌ SERVICES sync ended with errors: %v", err)
+		} else {
+			logger.Println("✅ SERVICES sync complete.")
+		}
+	}
+
+	// Staff rosters: timetables, breaks and time off (for utilisation)
+	if os.Getenv("RUN_ROSTER_SYNC") == "1" {
+		logger.Println("🚀 Running ROSTER sync…")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+		defer cancel()
+
+		if err := runner.SyncRosterFromAPI(ctx); err != nil {
+			logger.Printf("❌ ROSTER sync ended with errors: %v", err)
+		} else {
+			logger.Println("✅ ROSTER sync complete.")
 		}
+	}
+
+	// ---------- CHECKS + NOTIFICATIONS ----------
 
-		logger.Println("✅ Incremental REVIEWS sync complete.")
+	now := time.Now().UTC()
+	if err := runner.CheckFreshness(context.Background(), now); err != nil {
+		logger.Printf("⚠️  freshness check failed: %v", err)
 	}
 
-	if os.Getenv("RUN_PRODUCTS_SYNC") == "1" {
-		logger.Println("🚀 Running PRODUCTS sync…")
-		if err := runner.SyncProductsFromAPI(); err != nil {
-			logger.Fatalf("products sync failed: %v", err)
+	branchIDs := make([]string, 0, len(cfg.Branches))
+	for _, b := range cfg.Branches {
+		branchIDs = append(branchIDs, b.BranchID)
+	}
+	appraisals := alerts.NewAppraisalMonitor(gdb, logger, cfg.AppraisalDueLead)
+	appraisals.Hooks = append(appraisals.Hooks, alerts.NotifyHook(runner.Notify))
+	if err := appraisals.Run(context.Background(), branchIDs, now); err != nil {
+		logger.Printf("⚠️  appraisal due check failed: %v", err)
+	}
+
+	runner.Report.Log(logger)
+	runner.NotifyFailures(context.Background())
+
+	if runRec != nil {
+		status := models.SyncRunSucceeded
+		switch runner.Report.Status() {
+		case phorest.SyncPartial:
+			status = models.SyncRunPartial
+		case phorest.SyncFailed:
+			status = models.SyncRunFailed
+		}
+		if err := runs.Finish(runRec.ID, status, "", ""); err != nil {
+			logger.Printf("⚠️  could not record sync run result: %v", err)
 		}
-		logger.Println("✅ PRODUCTS sync complete.")
 	}
+	return runner.Report.ExitCode()
 }
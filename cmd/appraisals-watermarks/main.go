@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"os/user"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"github.com/araquach/phorest-datahub/internal/config"
+	"github.com/araquach/phorest-datahub/internal/db"
+	"github.com/araquach/phorest-datahub/internal/repos"
+)
+
+const usage = `usage:
+  appraisals-watermarks list
+  appraisals-watermarks history [-entity ENTITY] [-branch ID|ALL] [-limit 50]
+  appraisals-watermarks set    -entity ENTITY -branch ID|ALL -to 2026-01-31[T15:04:05Z] [-reason TEXT] [-yes]
+  appraisals-watermarks rewind -entity ENTITY -branch ID|ALL -to 2026-01-31[T15:04:05Z] [-reason TEXT] [-yes]
+
+set moves a watermark to any value; rewind only moves it backwards.
+Moving a watermark backwards asks for confirmation unless -yes is given.`
+
+func main() {
+	_ = godotenv.Load()
+
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cfg := config.Load()
+	logger := cfg.Logger
+
+	gdb, err := db.Open(cfg.DatabaseURL)
+	if err != nil {
+		logger.Fatalf("DB connection failed: %v", err)
+	}
+	defer db.Close(gdb)
+
+	repo := repos.NewWatermarksRepo(gdb, logger)
+
+	switch os.Args[1] {
+	case "list":
+		rows, err := repo.List()
+		if err != nil {
+			logger.Fatalf("list watermarks: %v", err)
+		}
+		for _, wm := range rows {
+			branch := "ALL"
+			if wm.BranchID != nil {
+				branch = *wm.BranchID
+			}
+			fmt.Printf("%-18s %-24s %-25s updated %s\n",
+				wm.Entity, branch, formatTS(wm.LastUpdatedPhorest), wm.UpdatedAt.UTC().Format(time.RFC3339))
+		}
+
+	case "history":
+		fs := flag.NewFlagSet("history", flag.ExitOnError)
+		entity := fs.String("entity", "", "only this entity")
+		branch := fs.String("branch", "", "only this branch ID (or ALL)")
+		limit := fs.Int("limit", 50, "max rows")
+		_ = fs.Parse(os.Args[2:])
+
+		rows, err := repo.History(*entity, *branch, *limit)
+		if err != nil {
+			logger.Fatalf("watermark history: %v", err)
+		}
+		for _, h := range rows {
+			run := "-"
+			if h.RunID != nil {
+				run = fmt.Sprintf("#%d", *h.RunID)
+			}
+			line := fmt.Sprintf("%s  %-18s %-24s %s → %s  by=%s run=%s",
+				h.ChangedAt.UTC().Format(time.RFC3339), h.Entity, h.BranchID,
+				formatTS(h.OldValue), formatTS(h.NewValue), h.ChangedBy, run)
+			if h.Reason != "" {
+				line += "  reason=" + h.Reason
+			}
+			fmt.Println(line)
+		}
+
+	case "set", "rewind":
+		cmd := os.Args[1]
+		fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+		entity := fs.String("entity", "", "watermark entity (required): "+strings.Join(entityNames(), ", "))
+		branch := fs.String("branch", "", "branch ID, or ALL for global entities (required)")
+		to := fs.String("to", "", "new value, YYYY-MM-DD or RFC3339 (required)")
+		reason := fs.String("reason", "", "why the watermark is being moved (stored in history)")
+		yes := fs.Bool("yes", false, "don't ask for confirmation")
+		_ = fs.Parse(os.Args[2:])
+
+		if *entity == "" || *branch == "" || *to == "" {
+			logger.Fatalf("-entity, -branch and -to are required")
+		}
+		global, known := repos.WatermarkEntities[*entity]
+		if !known {
+			logger.Fatalf("unknown entity %q (known: %s)", *entity, strings.Join(entityNames(), ", "))
+		}
+		if global && *branch != "ALL" {
+			logger.Fatalf("%s is a global watermark; use -branch ALL", *entity)
+		}
+		if !global && !configuredBranch(cfg, *branch) {
+			logger.Fatalf("branch %q is not configured (SITE_n_BRANCH_ID)", *branch)
+		}
+
+		target, err := parseTS(*to)
+		if err != nil {
+			logger.Fatalf("invalid -to: %v", err)
+		}
+
+		current, err := repo.GetLastUpdated(*entity, *branch)
+		if err != nil {
+			logger.Fatalf("read current watermark: %v", err)
+		}
+		backwards := current != nil && target.Before(*current)
+
+		if cmd == "rewind" && !backwards {
+			logger.Fatalf("rewind only moves backwards: current %s, requested %s (use set to move forwards)",
+				formatTS(current), formatTS(&target))
+		}
+
+		fmt.Printf("%s/%s: %s → %s\n", *entity, *branch, formatTS(current), formatTS(&target))
+		if backwards && !*yes {
+			fmt.Println("This moves the watermark BACKWARDS; the next sync will re-fetch everything after the new value.")
+			if !confirm("Type 'yes' to continue: ") {
+				fmt.Println("Aborted.")
+				os.Exit(1)
+			}
+		}
+
+		if _, err := repo.Set(*entity, *branch, target, repos.WatermarkChangedByManual, reasonWithUser(*reason)); err != nil {
+			logger.Fatalf("set watermark: %v", err)
+		}
+		logger.Printf("✅ %s/%s watermark now %s", *entity, *branch, formatTS(&target))
+
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func parseTS(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.UTC(), nil
+	}
+	return time.Parse("2006-01-02", s)
+}
+
+func formatTS(t *time.Time) string {
+	if t == nil {
+		return "(none)"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func entityNames() []string {
+	names := make([]string, 0, len(repos.WatermarkEntities))
+	for name := range repos.WatermarkEntities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func configuredBranch(cfg *config.Config, branchID string) bool {
+	for _, b := range cfg.Branches {
+		if b.BranchID == branchID {
+			return true
+		}
+	}
+	return false
+}
+
+func confirm(prompt string) bool {
+	fmt.Print(prompt)
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	return strings.TrimSpace(line) == "yes"
+}
+
+// reasonWithUser prefixes the OS user so manual changes can be traced.
+func reasonWithUser(reason string) string {
+	who := "unknown"
+	if u, err := user.Current(); err == nil {
+		who = u.Username
+	}
+	if reason == "" {
+		return who
+	}
+	return who + ": " + reason
+}
@@ -0,0 +1,73 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/araquach/phorest-datahub/internal/models"
+	"github.com/araquach/phorest-datahub/internal/repos"
+	"gorm.io/gorm"
+)
+
+// KindAppraisalDue is raised by AppraisalMonitor.
+const KindAppraisalDue = "appraisal_due"
+
+// AppraisalMonitor raises an appraisal_due alert for each stylist whose
+// appraisal target period ends within Lead, and resolves it once the period
+// has passed.
+type AppraisalMonitor struct {
+	targets *repos.AppraisalTargetsRepo
+	repo    *repos.AlertsRepo
+	lg      *log.Logger
+	Hooks   []Hook
+
+	Lead time.Duration
+}
+
+func NewAppraisalMonitor(db *gorm.DB, lg *log.Logger, lead time.Duration) *AppraisalMonitor {
+	return &AppraisalMonitor{
+		targets: repos.NewAppraisalTargetsRepo(db, lg),
+		repo:    repos.NewAlertsRepo(db, lg),
+		lg:      lg,
+		Hooks:   []Hook{LogHook(lg)},
+		Lead:    lead,
+	}
+}
+
+// Run checks every branch as of asOf. A failing branch doesn't stop the others.
+func (m *AppraisalMonitor) Run(ctx context.Context, branchIDs []string, asOf time.Time) error {
+	today := time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, time.UTC)
+	due, err := m.targets.DueBetween(today, today.Add(m.Lead))
+	if err != nil {
+		return fmt.Errorf("appraisal targets due: %w", err)
+	}
+
+	byBranch := map[string][]models.Alert{}
+	for _, d := range due {
+		days := int(d.PeriodEnd.Sub(today).Hours() / 24)
+		left := float64(days)
+		byBranch[d.BranchID] = append(byBranch[d.BranchID], models.Alert{
+			Kind:     KindAppraisalDue,
+			Severity: models.AlertWarning,
+			BranchID: d.BranchID,
+			StaffID:  d.StaffID,
+			Title:    fmt.Sprintf("Appraisal due by %s", d.PeriodEnd.Format("2 Jan 2006")),
+			Detail:   fmt.Sprintf("%d target(s) end on %s (%d day(s) left)", d.Targets, d.PeriodEnd.Format("2006-01-02"), days),
+			Value:    &left,
+		})
+	}
+
+	var errs []error
+	for _, branchID := range branchIDs {
+		if branchID == "" {
+			continue
+		}
+		if err := applyAlerts(ctx, m.repo, m.lg, m.Hooks, KindAppraisalDue, branchID, byBranch[branchID]); err != nil {
+			errs = append(errs, fmt.Errorf("branch %s: %w", branchID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,118 @@
+// Package alerts detects things managers should hear about before appraisal
+// time, stores them in the alerts table and passes new ones to hooks.
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/araquach/phorest-datahub/internal/models"
+	"github.com/araquach/phorest-datahub/internal/notify"
+	"github.com/araquach/phorest-datahub/internal/repos"
+)
+
+// Hook is told about every newly raised alert, and again on re-detection
+// while an open alert's earlier notification failed (not otherwise for
+// alerts that are already open).
+type Hook interface {
+	AlertRaised(ctx context.Context, a models.Alert) error
+}
+
+// HookFunc adapts a function to Hook.
+type HookFunc func(ctx context.Context, a models.Alert) error
+
+func (f HookFunc) AlertRaised(ctx context.Context, a models.Alert) error { return f(ctx, a) }
+
+// LogHook writes alerts to a logger; it's the default when nothing else is configured.
+func LogHook(lg *log.Logger) Hook {
+	return HookFunc(func(_ context.Context, a models.Alert) error {
+		who := a.StaffID
+		if who == "" {
+			who = "branch"
+		}
+		lg.Printf("🚨 [%s] %s/%s: %s — %s", a.Severity, a.BranchID, who, a.Title, a.Detail)
+		return nil
+	})
+}
+
+// NotifyHook publishes alerts through the notification dispatcher. A failed
+// delivery leaves the alert un-notified.
+func NotifyHook(d *notify.Dispatcher) Hook {
+	return HookFunc(func(ctx context.Context, a models.Alert) error {
+		evType, ok := alertEvents[a.Kind]
+		if !ok {
+			evType = "alert." + a.Kind
+		}
+		data := map[string]any{"alert_id": a.ID, "kind": a.Kind}
+		if a.Value != nil {
+			data["value"] = *a.Value
+		}
+		if a.Baseline != nil {
+			data["baseline"] = *a.Baseline
+		}
+		return d.Publish(ctx, notify.Event{
+			Type:       evType,
+			Severity:   a.Severity,
+			BranchID:   a.BranchID,
+			StaffID:    a.StaffID,
+			Title:      a.Title,
+			Body:       a.Detail,
+			Data:       data,
+			OccurredAt: a.RaisedAt,
+			Key:        fmt.Sprintf("alert|%d", a.ID),
+		})
+	})
+}
+
+// alertEvents maps alert kinds to notification event types.
+var alertEvents = map[string]string{
+	KindRatingDrop:   notify.EventRatingAlert,
+	KindLowRatingRun: notify.EventRatingAlert,
+	KindAppraisalDue: notify.EventAppraisalDue,
+}
+
+// applyAlerts raises the firing alerts of kind, runs hooks for new ones (and
+// for open ones never notified, so a failed delivery is retried) and
+// resolves the open alerts of kind in the branch that no longer fire.
+func applyAlerts(ctx context.Context, repo *repos.AlertsRepo, lg *log.Logger, hooks []Hook, kind, branchID string, firing []models.Alert) error {
+	staff := make([]string, 0, len(firing))
+	for i := range firing {
+		a := &firing[i]
+		staff = append(staff, a.StaffID)
+
+		raised, err := repo.Raise(a)
+		if err != nil {
+			return fmt.Errorf("raise %s alert for %s: %w", kind, a.StaffID, err)
+		}
+		if raised || a.NotifiedAt == nil {
+			runHooks(ctx, repo, lg, hooks, *a)
+		}
+	}
+
+	n, err := repo.ResolveExcept(kind, branchID, staff)
+	if err != nil {
+		return fmt.Errorf("resolve %s alerts: %w", kind, err)
+	}
+	if n > 0 {
+		lg.Printf("✅ %s: resolved %d %s alert(s)", branchID, n, kind)
+	}
+	return nil
+}
+
+// runHooks runs every hook; hook failures are logged and the alert stays
+// un-notified.
+func runHooks(ctx context.Context, repo *repos.AlertsRepo, lg *log.Logger, hooks []Hook, a models.Alert) {
+	ok := true
+	for _, h := range hooks {
+		if err := h.AlertRaised(ctx, a); err != nil {
+			lg.Printf("⚠️  alert #%d hook failed: %v", a.ID, err)
+			ok = false
+		}
+	}
+	if ok {
+		if err := repo.MarkNotified(a.ID); err != nil {
+			lg.Printf("⚠️  mark alert #%d notified: %v", a.ID, err)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/araquach/phorest-datahub/internal/models"
+	"github.com/araquach/phorest-datahub/internal/repos"
+	"github.com/araquach/phorest-datahub/internal/services"
+	"gorm.io/gorm"
+)
+
+// Alert kinds raised by RatingMonitor.
+const (
+	KindRatingDrop   = "rating_drop"
+	KindLowRatingRun = "low_rating_run"
+)
+
+// RatingMonitor compares each stylist's recent review ratings with their own
+// baseline and raises alerts for significant drops and runs of 1–2 star
+// reviews. Alerts that no longer fire are resolved.
+type RatingMonitor struct {
+	trends *services.RatingTrendsService
+	repo   *repos.AlertsRepo
+	lg     *log.Logger
+	Hooks  []Hook
+
+	// Drop detection: the 30-day average must be at least MinDrop stars
+	// below baseline and DropZ standard errors below it (CriticalZ → critical),
+	// with enough reviews on both sides to mean anything.
+	MinRecent   int64
+	MinBaseline int64
+	MinDrop     float64
+	DropZ       float64
+	CriticalZ   float64
+
+	// Low-star runs: the RunLength most recent reviews are all 1–2 stars,
+	// or at least LowIn30 of them in the last 30 days.
+	RunLength int
+	LowIn30   int64
+}
+
+func NewRatingMonitor(db *gorm.DB, lg *log.Logger) *RatingMonitor {
+	return &RatingMonitor{
+		trends:      services.NewRatingTrendsService(db, lg),
+		repo:        repos.NewAlertsRepo(db, lg),
+		lg:          lg,
+		Hooks:       []Hook{LogHook(lg)},
+		MinRecent:   3,
+		MinBaseline: 10,
+		MinDrop:     0.3,
+		DropZ:       2.0,
+		CriticalZ:   3.0,
+		RunLength:   3,
+		LowIn30:     3,
+	}
+}
+
+// Run evaluates every branch as of asOf. A failing branch doesn't stop the others.
+func (m *RatingMonitor) Run(ctx context.Context, branchIDs []string, asOf time.Time) error {
+	var errs []error
+	for _, branchID := range branchIDs {
+		if branchID == "" {
+			continue
+		}
+		if err := m.runBranch(ctx, branchID, asOf); err != nil {
+			errs = append(errs, fmt.Errorf("branch %s: %w", branchID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *RatingMonitor) runBranch(ctx context.Context, branchID string, asOf time.Time) error {
+	trends, err := m.trends.BranchTrends(ctx, branchID, asOf)
+	if err != nil {
+		return fmt.Errorf("rating trends: %w", err)
+	}
+	latest, err := m.trends.LatestRatings(ctx, branchID, asOf, m.RunLength)
+	if err != nil {
+		return fmt.Errorf("latest ratings: %w", err)
+	}
+	runs := lowRuns(latest)
+
+	var drops, lows []models.Alert
+	for _, t := range trends {
+		if a, ok := m.checkDrop(branchID, t); ok {
+			drops = append(drops, a)
+		}
+		if a, ok := m.checkLowRun(branchID, t, runs[t.StaffID]); ok {
+			lows = append(lows, a)
+		}
+	}
+
+	if err := applyAlerts(ctx, m.repo, m.lg, m.Hooks, KindRatingDrop, branchID, drops); err != nil {
+		return err
+	}
+	return applyAlerts(ctx, m.repo, m.lg, m.Hooks, KindLowRatingRun, branchID, lows)
+}
+
+// checkDrop is a one-sided z-test of the 30-day mean against the baseline.
+func (m *RatingMonitor) checkDrop(branchID string, t services.RatingTrend) (models.Alert, bool) {
+	if t.Count30 < m.MinRecent || t.BaselineCount < m.MinBaseline || t.Avg30 == nil || t.BaselineAvg == nil {
+		return models.Alert{}, false
+	}
+	drop := *t.BaselineAvg - *t.Avg30
+	if drop < m.MinDrop {
+		return models.Alert{}, false
+	}
+
+	// A perfectly consistent baseline (sd 0) would make any dip infinitely
+	// significant; floor it at half a star.
+	sd := 0.5
+	if t.BaselineSD != nil && *t.BaselineSD > sd {
+		sd = *t.BaselineSD
+	}
+	z := drop / (sd / math.Sqrt(float64(t.Count30)))
+	if z < m.DropZ {
+		return models.Alert{}, false
+	}
+
+	sev := models.AlertWarning
+	if z >= m.CriticalZ {
+		sev = models.AlertCritical
+	}
+	return models.Alert{
+		Kind:     KindRatingDrop,
+		Severity: sev,
+		BranchID: branchID,
+		StaffID:  t.StaffID,
+		Title:    fmt.Sprintf("Average rating down %.1f stars", drop),
+		Detail: fmt.Sprintf("30-day average %.2f from %d reviews vs %.2f baseline from %d reviews (z=%.1f)",
+			*t.Avg30, t.Count30, *t.BaselineAvg, t.BaselineCount, z),
+		Value:    t.Avg30,
+		Baseline: t.BaselineAvg,
+	}, true
+}
+
+func (m *RatingMonitor) checkLowRun(branchID string, t services.RatingTrend, run int) (models.Alert, bool) {
+	// A streak only counts while it's current (its latest review is recent).
+	streak := m.RunLength > 0 && run >= m.RunLength && t.Low30 > 0
+	cluster := m.LowIn30 > 0 && t.Low30 >= m.LowIn30
+	if !streak && !cluster {
+		return models.Alert{}, false
+	}
+
+	sev := models.AlertWarning
+	if streak && cluster {
+		sev = models.AlertCritical
+	}
+	low := float64(t.Low30)
+	a := models.Alert{
+		Kind:     KindLowRatingRun,
+		Severity: sev,
+		BranchID: branchID,
+		StaffID:  t.StaffID,
+		Value:    &low,
+	}
+	if streak {
+		a.Title = fmt.Sprintf("Last %d reviews were 1–2 stars", run)
+	} else {
+		a.Title = fmt.Sprintf("%d reviews of 1–2 stars in 30 days", t.Low30)
+	}
+	a.Detail = fmt.Sprintf("%d of %d reviews in the last 30 days were 1–2 stars", t.Low30, t.Count30)
+	return a, true
+}
+
+// lowRuns counts, per stylist, how many of their latest reviews in a row
+// (newest first) were 1–2 stars.
+func lowRuns(latest []services.RecentRating) map[string]int {
+	out := map[string]int{}
+	broken := map[string]bool{}
+	for _, r := range latest {
+		if broken[r.StaffID] {
+			continue
+		}
+		if r.Rating <= 2 {
+			out[r.StaffID]++
+		} else {
+			broken[r.StaffID] = true
+		}
+	}
+	return out
+}
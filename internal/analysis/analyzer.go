@@ -0,0 +1,243 @@
+// Package analysis scores review text offline: lexicon-based sentiment, topic
+// tagging and praise/complaint snippets. Nothing here touches the network.
+package analysis
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Sentiment labels.
+const (
+	LabelPositive = "positive"
+	LabelNeutral  = "neutral"
+	LabelNegative = "negative"
+)
+
+// Snippet kinds.
+const (
+	SnippetPraise    = "praise"
+	SnippetComplaint = "complaint"
+)
+
+const (
+	// labelThreshold is the |score| below which a review counts as neutral.
+	labelThreshold = 0.05
+	// snippetThreshold is the |sentence score| needed to quote it as praise
+	// or a complaint.
+	snippetThreshold = 0.3
+	// normAlpha squashes raw lexicon sums into -1..1 (as in VADER).
+	normAlpha = 15
+	// maxSnippetLen keeps quoted sentences short enough for reports.
+	maxSnippetLen = 240
+)
+
+// TopicScore is how a review talks about one topic.
+type TopicScore struct {
+	Topic     string
+	Mentions  int
+	Sentiment float64 // mean score of the sentences mentioning it, -1..1
+}
+
+// Snippet is one quotable sentence.
+type Snippet struct {
+	Kind  string // SnippetPraise or SnippetComplaint
+	Topic string // one of Topics, or TopicGeneral
+	Text  string
+	Score float64
+}
+
+// Result is the analysis of one review.
+type Result struct {
+	Sentiment float64 // -1..1
+	Label     string
+	Topics    []TopicScore
+	Snippets  []Snippet
+}
+
+// Analyze scores one review's text. Empty text gives a neutral result.
+func Analyze(text string) Result {
+	res := Result{Label: LabelNeutral}
+
+	var total float64
+	topics := map[string]*TopicScore{}
+
+	for _, sent := range splitSentences(text) {
+		tokens := tokenize(sent)
+		if len(tokens) == 0 {
+			continue
+		}
+		raw := scoreTokens(tokens)
+		total += raw
+		score := normalise(raw)
+
+		sentTopics := findTopics(sent, tokens)
+		for _, t := range sentTopics {
+			ts := topics[t]
+			if ts == nil {
+				ts = &TopicScore{Topic: t}
+				topics[t] = ts
+			}
+			// running mean of sentence scores
+			ts.Sentiment = (ts.Sentiment*float64(ts.Mentions) + score) / float64(ts.Mentions+1)
+			ts.Mentions++
+		}
+
+		kind := ""
+		switch {
+		case score >= snippetThreshold:
+			kind = SnippetPraise
+		case score <= -snippetThreshold:
+			kind = SnippetComplaint
+		}
+		if kind == "" {
+			continue
+		}
+		if len(sentTopics) == 0 {
+			sentTopics = []string{TopicGeneral}
+		}
+		for _, t := range sentTopics {
+			res.Snippets = append(res.Snippets, Snippet{
+				Kind:  kind,
+				Topic: t,
+				Text:  trimSnippet(sent),
+				Score: round3(score),
+			})
+		}
+	}
+
+	res.Sentiment = round3(normalise(total))
+	switch {
+	case res.Sentiment >= labelThreshold:
+		res.Label = LabelPositive
+	case res.Sentiment <= -labelThreshold:
+		res.Label = LabelNegative
+	}
+
+	for _, t := range Topics {
+		if ts, ok := topics[t]; ok {
+			ts.Sentiment = round3(ts.Sentiment)
+			res.Topics = append(res.Topics, *ts)
+		}
+	}
+	return res
+}
+
+// scoreTokens sums lexicon weights, applying intensifiers to the next word and
+// flipping words preceded by a negator within negationWindow tokens.
+func scoreTokens(tokens []string) float64 {
+	var sum float64
+	for i, tok := range tokens {
+		w, ok := sentimentWords[tok]
+		if !ok {
+			continue
+		}
+		if i > 0 {
+			if m, ok := intensifiers[tokens[i-1]]; ok {
+				w *= m
+			}
+		}
+		for j := i - 1; j >= 0 && j >= i-negationWindow; j-- {
+			if negators[tokens[j]] {
+				w *= -0.75
+				break
+			}
+		}
+		sum += w
+	}
+	return sum
+}
+
+func normalise(x float64) float64 {
+	if x == 0 {
+		return 0
+	}
+	return x / math.Sqrt(x*x+normAlpha)
+}
+
+func findTopics(sentence string, tokens []string) []string {
+	seen := map[string]bool{}
+	for _, tok := range tokens {
+		if t, ok := topicWords[tok]; ok {
+			seen[t] = true
+		}
+	}
+	lower := strings.ToLower(sentence)
+	for phrase, t := range topicPhrases {
+		if strings.Contains(lower, phrase) {
+			seen[t] = true
+		}
+	}
+
+	out := make([]string, 0, len(seen))
+	for t := range seen {
+		out = append(out, t)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// splitSentences breaks text on ., !, ? and newlines, keeping the terminator.
+func splitSentences(text string) []string {
+	var out []string
+	var b strings.Builder
+	flush := func() {
+		if s := strings.TrimSpace(b.String()); s != "" {
+			out = append(out, s)
+		}
+		b.Reset()
+	}
+	for _, r := range text {
+		switch r {
+		case '\n', '\r':
+			flush()
+		case '.', '!', '?':
+			b.WriteRune(r)
+			flush()
+		default:
+			b.WriteRune(r)
+		}
+	}
+	flush()
+	return out
+}
+
+// tokenize lowercases and splits on anything that isn't a letter, digit,
+// apostrophe or hyphen. "£" is kept as its own token (price mentions).
+func tokenize(s string) []string {
+	var out []string
+	var b strings.Builder
+	flush := func() {
+		if b.Len() > 0 {
+			out = append(out, strings.Trim(b.String(), "'-"))
+			b.Reset()
+		}
+	}
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case r == '£':
+			flush()
+			out = append(out, "£")
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-':
+			b.WriteRune(r)
+		case r == '\'' || r == '’':
+			b.WriteRune('\'')
+		default:
+			flush()
+		}
+	}
+	flush()
+	return out
+}
+
+func trimSnippet(s string) string {
+	r := []rune(s)
+	if len(r) <= maxSnippetLen {
+		return s
+	}
+	return strings.TrimSpace(string(r[:maxSnippetLen-1])) + "…"
+}
+
+func round3(x float64) float64 { return math.Round(x*1000) / 1000 }
@@ -0,0 +1,158 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		label    string
+		topics   []string
+		snippets []string // "kind/topic" per snippet, in order
+	}{
+		{
+			name:  "empty",
+			text:  "",
+			label: LabelNeutral,
+		},
+		{
+			name:     "plain praise",
+			text:     "The colour is amazing.",
+			label:    LabelPositive,
+			topics:   []string{TopicColour},
+			snippets: []string{"praise/colour"},
+		},
+		{
+			// flipped and damped: negative, but too mild to quote
+			name:   "negated positive",
+			text:   "The colour was not good.",
+			label:  LabelNegative,
+			topics: []string{TopicColour},
+		},
+		{
+			name:     "negated negative",
+			text:     "Not bad at all.",
+			label:    LabelPositive,
+			snippets: []string{"praise/general"},
+		},
+		{
+			name:     "negator outside the window",
+			text:     "I would never say it was bad.",
+			label:    LabelNegative,
+			snippets: []string{"complaint/general"},
+		},
+		{
+			name:     "softened negative stays negative",
+			text:     "A bit disappointed with the fringe.",
+			label:    LabelNegative,
+			topics:   []string{TopicCut},
+			snippets: []string{"complaint/cut"},
+		},
+		{
+			name:   "mixed sentence",
+			text:   "Waited 40 minutes but the staff were lovely.",
+			label:  LabelPositive,
+			topics: []string{TopicFriendliness, TopicWaitTime},
+		},
+		{
+			name:     "complaint per sentence",
+			text:     "Lovely team! Overpriced for what it was.",
+			label:    LabelNegative,
+			topics:   []string{TopicFriendliness, TopicPrice},
+			snippets: []string{"praise/friendliness", "complaint/price"},
+		},
+		{
+			name:     "topic phrase",
+			text:     "She was running late",
+			label:    LabelNegative,
+			topics:   []string{TopicWaitTime},
+			snippets: []string{"complaint/wait_time"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := Analyze(tt.text)
+			if res.Label != tt.label {
+				t.Errorf("label = %q (score %v), want %q", res.Label, res.Sentiment, tt.label)
+			}
+
+			var topics []string
+			for _, ts := range res.Topics {
+				topics = append(topics, ts.Topic)
+			}
+			if !reflect.DeepEqual(topics, tt.topics) {
+				t.Errorf("topics = %v, want %v", topics, tt.topics)
+			}
+
+			var snippets []string
+			for _, s := range res.Snippets {
+				snippets = append(snippets, s.Kind+"/"+s.Topic)
+			}
+			if !reflect.DeepEqual(snippets, tt.snippets) {
+				t.Errorf("snippets = %v, want %v", snippets, tt.snippets)
+			}
+		})
+	}
+}
+
+func TestAnalyzeIntensifiers(t *testing.T) {
+	plain := Analyze("The cut was good.").Sentiment
+	tests := []struct {
+		text   string
+		higher bool
+	}{
+		{"The cut was very good.", true},
+		{"The cut was absolutely good.", true},
+		{"The cut was slightly good.", false},
+	}
+	for _, tt := range tests {
+		got := Analyze(tt.text).Sentiment
+		if (got > plain) != tt.higher {
+			t.Errorf("%q: score %v vs plain %v, want higher=%v", tt.text, got, plain, tt.higher)
+		}
+	}
+}
+
+func TestAnalyzeTopicSentiment(t *testing.T) {
+	res := Analyze("Love my colour. The colour faded and looks brassy.")
+	if len(res.Topics) != 1 || res.Topics[0].Topic != TopicColour {
+		t.Fatalf("topics = %+v, want colour only", res.Topics)
+	}
+	if got := res.Topics[0].Mentions; got != 2 {
+		t.Errorf("colour mentions = %d, want 2", got)
+	}
+}
+
+func TestSplitSentences(t *testing.T) {
+	got := splitSentences("Great cut! Would go again?\nYes.  ")
+	want := []string{"Great cut!", "Would go again?", "Yes."}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitSentences = %q, want %q", got, want)
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	got := tokenize("Didn’t love the blow-dry, £60!")
+	want := []string{"didn't", "love", "the", "blow-dry", "£", "60"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tokenize = %q, want %q", got, want)
+	}
+}
+
+func TestTrimSnippet(t *testing.T) {
+	short := "Lovely cut."
+	if got := trimSnippet(short); got != short {
+		t.Errorf("trimSnippet(short) = %q", got)
+	}
+	long := make([]rune, maxSnippetLen+10)
+	for i := range long {
+		long[i] = 'a'
+	}
+	if got := []rune(trimSnippet(string(long))); len(got) != maxSnippetLen || got[len(got)-1] != '…' {
+		t.Errorf("trimSnippet(long) has %d runes, ending %q", len(got), got[len(got)-1])
+	}
+}
@@ -0,0 +1,128 @@
+package analysis
+
+// The lexicon is deliberately small and salon-specific: a general-purpose
+// sentiment list misreads words like "cut", "dye" or "bleach", and we care
+// far more about "she listened" than about film-review vocabulary.
+//
+// Weights are roughly -3..+3. Bump LexiconVersion whenever anything here
+// changes so stored analyses are recomputed.
+
+// LexiconVersion is stored with every analysis; rows with an older version
+// are re-analysed.
+const LexiconVersion = "lexicon-v1"
+
+var sentimentWords = map[string]float64{
+	// positive
+	"amazing": 3, "fantastic": 3, "brilliant": 3, "outstanding": 3, "perfect": 3,
+	"incredible": 3, "wonderful": 3, "excellent": 3, "best": 3, "stunning": 3,
+	"superb": 3, "faultless": 3, "flawless": 3, "exceptional": 3, "phenomenal": 3,
+	"love": 2.5, "loved": 2.5, "loves": 2.5, "gorgeous": 2.5, "beautiful": 2.5,
+	"delighted": 2.5, "thrilled": 2.5, "fabulous": 2.5, "recommend": 2, "recommended": 2,
+	"great": 2, "lovely": 2, "happy": 2, "pleased": 2, "friendly": 2, "welcoming": 2,
+	"professional": 2, "talented": 2, "skilled": 2, "knowledgeable": 2, "attentive": 2,
+	"relaxing": 2, "relaxed": 1.5, "listened": 2, "listens": 2, "helpful": 2, "kind": 2,
+	"warm": 1.5, "good": 1.5, "nice": 1.5, "clean": 1, "comfortable": 1.5, "calm": 1,
+	"pampered": 2, "reasonable": 1.5, "worth": 1.5, "bargain": 1.5, "punctual": 1.5,
+	"prompt": 1, "efficient": 1.5, "quick": 1, "thank": 1, "thanks": 1, "impressed": 2,
+	"enjoyed": 2, "enjoy": 1.5, "fresh": 1, "vibrant": 1.5, "glossy": 1.5, "shiny": 1,
+	"healthy": 1, "natural": 0.5, "spot": 0.5, "welcome": 1.5, "polite": 1.5, "chatty": 1,
+	"patient": 1.5, "caring": 2, "genius": 2.5, "magic": 2, "wizard": 2, "confident": 1.5,
+
+	// negative
+	"terrible": -3, "awful": -3, "horrible": -3, "disgusting": -3, "worst": -3,
+	"ruined": -3, "disaster": -3, "appalling": -3, "horrendous": -3, "butchered": -3,
+	"rude": -2.5, "disappointed": -2.5, "disappointing": -2.5, "unprofessional": -2.5,
+	"unhappy": -2.5, "upset": -2, "damaged": -2.5, "patchy": -2, "uneven": -2, "brassy": -2,
+	"orange": -1.5, "wrong": -2, "bad": -2, "poor": -2, "rushed": -2, "careless": -2,
+	"dismissive": -2, "ignored": -2, "unfriendly": -2, "cold": -1, "late": -1.5, "waiting": -1,
+	"waited": -1.5, "delay": -1.5, "delayed": -1.5, "overpriced": -2.5, "expensive": -1,
+	"overcharged": -2.5, "pricey": -1, "dirty": -2, "messy": -1.5, "uncomfortable": -1.5,
+	"painful": -2, "burned": -2.5, "burnt": -2.5, "burning": -2, "frizzy": -1.5, "dry": -1,
+	"broken": -2, "breakage": -2, "short": -0.5, "shorter": -1, "mistake": -2, "complain": -2,
+	"complaint": -2, "refund": -2, "redo": -1.5, "fix": -1, "fixed": -0.5, "cancelled": -1,
+	"unreliable": -2, "shocking": -2.5, "annoyed": -2, "annoying": -2, "frustrated": -2,
+	"frustrating": -2, "sadly": -1.5, "unfortunately": -1.5, "meh": -1,
+}
+
+// negators flip the polarity of a sentiment word within negationWindow tokens.
+var negators = map[string]bool{
+	"not": true, "no": true, "never": true, "didn't": true, "didnt": true, "don't": true,
+	"dont": true, "wasn't": true, "wasnt": true, "isn't": true, "isnt": true, "won't": true,
+	"wont": true, "couldn't": true, "couldnt": true, "wouldn't": true, "wouldnt": true,
+	"hardly": true, "nothing": true, "without": true, "weren't": true, "werent": true,
+}
+
+const negationWindow = 3
+
+// intensifiers scale the next sentiment word.
+var intensifiers = map[string]float64{
+	"very": 1.4, "really": 1.4, "so": 1.3, "extremely": 1.6, "absolutely": 1.6,
+	"incredibly": 1.6, "super": 1.4, "totally": 1.4, "truly": 1.3, "completely": 1.5,
+	"highly": 1.4, "bit": 0.6, "slightly": 0.6, "somewhat": 0.7, "quite": 1.1,
+}
+
+// Topics reported by the analysis. "general" collects snippets that don't
+// mention any of the others.
+const (
+	TopicColour       = "colour"
+	TopicCut          = "cut"
+	TopicFriendliness = "friendliness"
+	TopicWaitTime     = "wait_time"
+	TopicPrice        = "price"
+	TopicGeneral      = "general"
+)
+
+// Topics lists the tagged topics in report order.
+var Topics = []string{TopicColour, TopicCut, TopicFriendliness, TopicWaitTime, TopicPrice}
+
+// topicWords maps single tokens to a topic.
+var topicWords = map[string]string{
+	"colour": TopicColour, "color": TopicColour, "coloured": TopicColour, "colored": TopicColour,
+	"colourist": TopicColour, "balayage": TopicColour, "highlights": TopicColour,
+	"lowlights": TopicColour, "foils": TopicColour, "toner": TopicColour, "toned": TopicColour,
+	"blonde": TopicColour, "blond": TopicColour, "brunette": TopicColour, "tint": TopicColour,
+	"roots": TopicColour, "bleach": TopicColour, "dye": TopicColour, "dyed": TopicColour,
+	"ombre": TopicColour, "brassy": TopicColour, "grey": TopicColour, "gloss": TopicColour,
+	"shade": TopicColour, "copper": TopicColour, "red": TopicColour, "babylights": TopicColour,
+
+	"cut": TopicCut, "haircut": TopicCut, "trim": TopicCut, "trimmed": TopicCut,
+	"fringe": TopicCut, "layers": TopicCut, "layered": TopicCut, "bob": TopicCut,
+	"restyle": TopicCut, "shape": TopicCut, "length": TopicCut, "shorter": TopicCut,
+	"uneven": TopicCut, "clippers": TopicCut, "fade": TopicCut, "blowdry": TopicCut,
+	"blow-dry": TopicCut, "style": TopicCut, "styled": TopicCut, "styling": TopicCut,
+
+	"friendly": TopicFriendliness, "unfriendly": TopicFriendliness, "welcoming": TopicFriendliness,
+	"welcome": TopicFriendliness, "welcomed": TopicFriendliness, "rude": TopicFriendliness,
+	"kind": TopicFriendliness, "polite": TopicFriendliness, "chatty": TopicFriendliness,
+	"attentive": TopicFriendliness, "warm": TopicFriendliness, "caring": TopicFriendliness,
+	"listened": TopicFriendliness, "listens": TopicFriendliness, "dismissive": TopicFriendliness,
+	"ignored": TopicFriendliness, "patient": TopicFriendliness, "lovely": TopicFriendliness,
+	"staff": TopicFriendliness, "team": TopicFriendliness, "reception": TopicFriendliness,
+	"receptionist": TopicFriendliness, "atmosphere": TopicFriendliness,
+
+	"wait": TopicWaitTime, "waiting": TopicWaitTime, "waited": TopicWaitTime,
+	"late": TopicWaitTime, "delay": TopicWaitTime, "delayed": TopicWaitTime,
+	"punctual": TopicWaitTime, "prompt": TopicWaitTime, "rushed": TopicWaitTime,
+	"quick": TopicWaitTime, "slow": TopicWaitTime, "hours": TopicWaitTime,
+	"overran": TopicWaitTime, "queue": TopicWaitTime,
+
+	"price": TopicPrice, "prices": TopicPrice, "priced": TopicPrice, "cost": TopicPrice,
+	"costly": TopicPrice, "expensive": TopicPrice, "cheap": TopicPrice, "cheaper": TopicPrice,
+	"value": TopicPrice, "overpriced": TopicPrice, "overcharged": TopicPrice,
+	"pricey": TopicPrice, "reasonable": TopicPrice, "bargain": TopicPrice, "charged": TopicPrice,
+	"money": TopicPrice, "worth": TopicPrice, "£": TopicPrice, "pay": TopicPrice, "paid": TopicPrice,
+}
+
+// topicPhrases catch multi-word mentions the token map can't.
+var topicPhrases = map[string]string{
+	"on time":       TopicWaitTime,
+	"kept waiting":  TopicWaitTime,
+	"kept me":       TopicWaitTime,
+	"running late":  TopicWaitTime,
+	"made me feel":  TopicFriendliness,
+	"at ease":       TopicFriendliness,
+	"value for":     TopicPrice,
+	"hair colour":   TopicColour,
+	"hair color":    TopicColour,
+	"grey coverage": TopicColour,
+}
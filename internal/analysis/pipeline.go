@@ -0,0 +1,98 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/araquach/phorest-datahub/internal/models"
+	"github.com/araquach/phorest-datahub/internal/repos"
+	"gorm.io/gorm"
+)
+
+// Pipeline analyses every review that's new, edited, or was scored by an
+// older lexicon, and stores the results.
+type Pipeline struct {
+	repo *repos.ReviewAnalysisRepo
+	lg   *log.Logger
+
+	BatchSize int
+}
+
+func NewPipeline(db *gorm.DB, lg *log.Logger) *Pipeline {
+	return &Pipeline{
+		repo:      repos.NewReviewAnalysisRepo(db, lg),
+		lg:        lg,
+		BatchSize: 500,
+	}
+}
+
+// Run processes pending reviews batch by batch and returns how many it analysed.
+func (p *Pipeline) Run(ctx context.Context) (int, error) {
+	p.lg.Printf("▶️ Starting REVIEW ANALYSIS (%s)...", LexiconVersion)
+
+	total := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+
+		pending, err := p.repo.ListPending(LexiconVersion, p.BatchSize)
+		if err != nil {
+			return total, fmt.Errorf("list pending reviews: %w", err)
+		}
+		if len(pending) == 0 {
+			break
+		}
+
+		sets := make([]repos.ReviewAnalysisSet, 0, len(pending))
+		now := time.Now().UTC()
+		for i := range pending {
+			sets = append(sets, toAnalysisSet(&pending[i], Analyze(pending[i].Text), now))
+		}
+		if err := p.repo.SaveMany(sets); err != nil {
+			return total, fmt.Errorf("save review analysis: %w", err)
+		}
+		total += len(sets)
+		p.lg.Printf("   analysed %d reviews (%d so far)", len(sets), total)
+
+		if len(pending) < p.BatchSize {
+			break
+		}
+	}
+
+	p.lg.Printf("✅ REVIEW ANALYSIS finished: %d review(s) analysed", total)
+	return total, nil
+}
+
+func toAnalysisSet(rv *models.Review, res Result, at time.Time) repos.ReviewAnalysisSet {
+	set := repos.ReviewAnalysisSet{
+		Analysis: models.ReviewAnalysis{
+			ReviewID:        rv.ReviewID,
+			Sentiment:       res.Sentiment,
+			Label:           res.Label,
+			ContentHash:     rv.ContentHash,
+			AnalyzerVersion: LexiconVersion,
+			AnalysedAt:      at,
+		},
+	}
+	for _, t := range res.Topics {
+		set.Topics = append(set.Topics, models.ReviewTopic{
+			ReviewID:  rv.ReviewID,
+			Topic:     t.Topic,
+			Mentions:  t.Mentions,
+			Sentiment: t.Sentiment,
+		})
+	}
+	for _, s := range res.Snippets {
+		set.Snippets = append(set.Snippets, models.ReviewSnippet{
+			ReviewID: rv.ReviewID,
+			Kind:     s.Kind,
+			Topic:    s.Topic,
+			Text:     s.Text,
+			Score:    s.Score,
+		})
+	}
+	return set
+}
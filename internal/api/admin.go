@@ -0,0 +1,299 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/araquach/phorest-datahub/internal/models"
+	"github.com/araquach/phorest-datahub/internal/phorest"
+)
+
+type startSyncRequest struct {
+	Kind     string `json:"kind"`
+	BranchID string `json:"branch_id"`
+	From     string `json:"from"`     // YYYY-MM-DD, transactions and appointments only
+	To       string `json:"to"`       // YYYY-MM-DD, transactions and appointments only
+	Strategy string `json:"strategy"` // reviews: incremental (default), full, latest, reconcile; products: incremental (default), reconcile
+	LatestN  int    `json:"latest_n"` // reviews "latest" strategy only
+}
+
+type syncRunDTO struct {
+	ID          int64      `json:"id"`
+	Kind        string     `json:"kind"`
+	BranchID    string     `json:"branch_id"`
+	WindowFrom  *time.Time `json:"window_from,omitempty"`
+	WindowTo    *time.Time `json:"window_to,omitempty"`
+	Strategy    string     `json:"strategy,omitempty"`
+	TriggeredBy string     `json:"triggered_by"`
+	RequestedBy string     `json:"requested_by,omitempty"`
+	Status      string     `json:"status"`
+	Error       string     `json:"error,omitempty"`
+	StartedAt   time.Time  `json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	LogURL      string     `json:"log_url"`
+}
+
+func toSyncRunDTO(r models.SyncRun) syncRunDTO {
+	return syncRunDTO{
+		ID:          r.ID,
+		Kind:        r.Kind,
+		BranchID:    r.BranchID,
+		WindowFrom:  r.WindowFrom,
+		WindowTo:    r.WindowTo,
+		Strategy:    r.Strategy,
+		TriggeredBy: r.TriggeredBy,
+		RequestedBy: r.RequestedBy,
+		Status:      r.Status,
+		Error:       r.Error,
+		StartedAt:   r.StartedAt,
+		FinishedAt:  r.FinishedAt,
+		LogURL:      fmt.Sprintf("/api/admin/syncs/%d/logs", r.ID),
+	}
+}
+
+// globalSyncKinds are not branch-scoped, so only unrestricted keys may start them.
+var globalSyncKinds = map[string]bool{
+	phorest.SyncKindBranches: true,
+	phorest.SyncKindClients:  true,
+}
+
+func (s *Server) handleStartSync(w http.ResponseWriter, r *http.Request) {
+	p := principalFrom(r.Context())
+
+	var req startSyncRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+
+	if !p.unrestricted() {
+		if globalSyncKinds[req.Kind] {
+			writeError(w, http.StatusForbidden, "branch-restricted keys cannot start a "+req.Kind+" sync")
+			return
+		}
+		if req.BranchID == "" || !p.canSeeBranch(req.BranchID) {
+			writeError(w, http.StatusForbidden, "branch_id must be one of this key's branches")
+			return
+		}
+	}
+
+	rr := phorest.RunRequest{
+		Kind:        req.Kind,
+		BranchID:    req.BranchID,
+		Strategy:    req.Strategy,
+		LatestN:     req.LatestN,
+		TriggeredBy: "api",
+		RequestedBy: p.key.KeyPrefix,
+	}
+	if req.From != "" || req.To != "" {
+		if req.Kind != phorest.SyncKindTransactions && req.Kind != phorest.SyncKindAppointments {
+			writeError(w, http.StatusBadRequest, "from/to are only supported for transactions and appointments syncs")
+			return
+		}
+		from, err1 := time.Parse("2006-01-02", req.From)
+		to, err2 := time.Parse("2006-01-02", req.To)
+		if err1 != nil || err2 != nil || to.Before(from) {
+			writeError(w, http.StatusBadRequest, "from and to must both be YYYY-MM-DD with from <= to")
+			return
+		}
+		rr.From, rr.To = &from, &to
+	}
+
+	run, err := s.syncs.Start(rr)
+	switch {
+	case errors.Is(err, phorest.ErrUnknownSyncKind):
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown kind %q (valid: %s)", req.Kind, strings.Join(phorest.SyncKinds, ", ")))
+		return
+	case errors.Is(err, phorest.ErrBadStrategy):
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, phorest.ErrUnknownBranch):
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, phorest.ErrRunConflict):
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		s.lg.Printf("❌ start sync: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to start sync")
+		return
+	}
+
+	rec, err := s.syncs.Runs().Get(run.ID)
+	if err != nil || rec == nil {
+		writeJSON(w, r, http.StatusAccepted, map[string]any{"id": run.ID, "status": models.SyncRunRunning})
+		return
+	}
+	writeJSON(w, r, http.StatusAccepted, toSyncRunDTO(*rec))
+}
+
+func (s *Server) handleListSyncs(w http.ResponseWriter, r *http.Request) {
+	p := principalFrom(r.Context())
+
+	limit := 50
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= 500 {
+			limit = n
+		}
+	}
+
+	rows, err := s.syncs.Runs().ListRecent(p.branches, limit)
+	if err != nil {
+		s.lg.Printf("❌ list syncs: %v", err)
+		writeError(w, http.StatusInternalServerError, "query failed")
+		return
+	}
+	out := make([]syncRunDTO, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, toSyncRunDTO(row))
+	}
+	writeJSON(w, r, http.StatusOK, map[string]any{"data": out})
+}
+
+// loadRun fetches a run by path ID and checks the caller may see its branch.
+func (s *Server) loadRun(w http.ResponseWriter, r *http.Request) (*models.SyncRun, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid run id")
+		return nil, false
+	}
+	rec, err := s.syncs.Runs().Get(id)
+	if err != nil {
+		s.lg.Printf("❌ get sync run %d: %v", id, err)
+		writeError(w, http.StatusInternalServerError, "query failed")
+		return nil, false
+	}
+	p := principalFrom(r.Context())
+	if rec == nil || !p.canSeeBranch(rec.BranchID) {
+		writeError(w, http.StatusNotFound, "sync run not found")
+		return nil, false
+	}
+	return rec, true
+}
+
+func (s *Server) handleGetSync(w http.ResponseWriter, r *http.Request) {
+	rec, ok := s.loadRun(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, r, http.StatusOK, toSyncRunDTO(*rec))
+}
+
+func (s *Server) handleCancelSync(w http.ResponseWriter, r *http.Request) {
+	rec, ok := s.loadRun(w, r)
+	if !ok {
+		return
+	}
+	if rec.Status != models.SyncRunRunning {
+		writeError(w, http.StatusConflict, "sync run is not running")
+		return
+	}
+	if !s.syncs.Cancel(rec.ID) {
+		writeError(w, http.StatusConflict, "sync run is not running in this process (started by cron/CLI?)")
+		return
+	}
+	writeJSON(w, r, http.StatusAccepted, map[string]any{"id": rec.ID, "status": "cancelling"})
+}
+
+// handleSyncLogs streams a run's log lines as Server-Sent Events:
+// "log" events carry one line each, a final "status" event carries the outcome.
+func (s *Server) handleSyncLogs(w http.ResponseWriter, r *http.Request) {
+	rec, ok := s.loadRun(w, r)
+	if !ok {
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	// Streams can outlive the server's WriteTimeout.
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(event, data string) bool {
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+			return false
+		}
+		return rc.Flush() == nil
+	}
+	sendStatus := func(status, errText string) {
+		b, _ := json.Marshal(map[string]string{"status": status, "error": errText})
+		send("status", string(b))
+	}
+
+	run := s.syncs.Get(rec.ID)
+	if run == nil {
+		// Finished (or started elsewhere): replay whatever was stored.
+		if rec.Log != "" {
+			for _, line := range strings.Split(rec.Log, "\n") {
+				if !send("log", line) {
+					return
+				}
+			}
+		}
+		sendStatus(rec.Status, rec.Error)
+		return
+	}
+
+	offset := 0
+	keepAlive := time.NewTicker(15 * time.Second)
+	defer keepAlive.Stop()
+
+	for {
+		lines, changed, done := run.LinesSince(offset)
+		for _, line := range lines {
+			if !send("log", line) {
+				return
+			}
+		}
+		offset += len(lines)
+
+		if done {
+			sendStatus(run.Status())
+			return
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-changed:
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil || rc.Flush() != nil {
+				return
+			}
+		}
+	}
+}
+
+// handleNotifications lists the notification delivery log, newest first.
+func (s *Server) handleNotifications(w http.ResponseWriter, r *http.Request) {
+	v := r.URL.Query()
+	q, ok := scopeBranches(w, r, s.db.WithContext(r.Context()).Model(&models.NotificationDelivery{}), "branch_id")
+	if !ok {
+		return
+	}
+	q = applyEq(q, v, map[string]string{
+		"branch_id":  "branch_id",
+		"event_type": "event_type",
+		"channel":    "channel",
+		"status":     "status",
+	})
+
+	var rows []models.NotificationDelivery
+	pg, total, ok := s.list(w, r, q, "created_at DESC, id DESC", &rows)
+	if !ok {
+		return
+	}
+	out := make([]notificationDeliveryDTO, 0, len(rows))
+	for _, d := range rows {
+		out = append(out, toNotificationDeliveryDTO(d))
+	}
+	writeList(w, r, out, pg, total)
+}
@@ -0,0 +1,211 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/araquach/phorest-datahub/internal/models"
+	"gorm.io/gorm"
+)
+
+// Scopes understood by the API. Any valid key may read non-PII data;
+// the scopes below unlock the sensitive parts.
+const (
+	ScopeReadKPIs       = "read:kpis"
+	ScopeReadClientsPII = "read:clients-pii"
+	ScopeAdminSync      = "admin:sync"
+)
+
+// KnownScopes is used to validate keys created from the CLI.
+var KnownScopes = []string{ScopeReadKPIs, ScopeReadClientsPII, ScopeAdminSync}
+
+const keyPrefixTag = "pdh"
+
+// NewAPIKey returns a fresh plaintext key, its public prefix and the hash to store.
+// Format: pdh_<prefix>_<secret>. The plaintext is shown once and never stored.
+func NewAPIKey() (plaintext, prefix, hash string, err error) {
+	p := make([]byte, 6)
+	sec := make([]byte, 24)
+	if _, err = rand.Read(p); err != nil {
+		return "", "", "", err
+	}
+	if _, err = rand.Read(sec); err != nil {
+		return "", "", "", err
+	}
+	prefix = hex.EncodeToString(p)
+	plaintext = fmt.Sprintf("%s_%s_%s", keyPrefixTag, prefix, hex.EncodeToString(sec))
+	return plaintext, prefix, HashAPIKey(plaintext), nil
+}
+
+// HashAPIKey is the stored form of a key.
+func HashAPIKey(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return hex.EncodeToString(sum[:])
+}
+
+// principal is the authenticated caller attached to the request context.
+type principal struct {
+	key      *models.APIKey
+	scopes   []string
+	branches []string // empty = all branches
+}
+
+func (p *principal) hasScope(scope string) bool {
+	return slices.Contains(p.scopes, scope)
+}
+
+func (p *principal) unrestricted() bool {
+	return len(p.branches) == 0
+}
+
+func (p *principal) canSeeBranch(branchID string) bool {
+	return p.unrestricted() || slices.Contains(p.branches, branchID)
+}
+
+type principalKey struct{}
+
+func principalFrom(ctx context.Context) *principal {
+	p, _ := ctx.Value(principalKey{}).(*principal)
+	return p
+}
+
+// requireKey authenticates the request from "Authorization: Bearer <key>" or "X-API-Key".
+func (s *Server) requireKey(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw := r.Header.Get("X-API-Key")
+		if raw == "" {
+			if h := r.Header.Get("Authorization"); strings.HasPrefix(h, "Bearer ") {
+				raw = strings.TrimSpace(strings.TrimPrefix(h, "Bearer "))
+			}
+		}
+		parts := strings.Split(raw, "_")
+		if len(parts) != 3 || parts[0] != keyPrefixTag {
+			writeError(w, http.StatusUnauthorized, "missing or malformed API key")
+			return
+		}
+
+		k, err := s.keys.FindActiveByPrefix(parts[1])
+		if err != nil {
+			s.lg.Printf("❌ api key lookup: %v", err)
+			writeError(w, http.StatusInternalServerError, "auth failed")
+			return
+		}
+		if k == nil || subtle.ConstantTimeCompare([]byte(k.KeyHash), []byte(HashAPIKey(raw))) != 1 {
+			writeError(w, http.StatusUnauthorized, "invalid API key")
+			return
+		}
+
+		if err := s.keys.TouchLastUsed(k.ID); err != nil {
+			s.lg.Printf("⚠️  api key %s: update last_used_at: %v", k.KeyPrefix, err)
+		}
+
+		// Let the audit middleware (outermost) know who this was.
+		if a, ok := w.(*auditRecorder); ok {
+			a.keyID = &k.ID
+			a.keyPrefix = k.KeyPrefix
+		}
+
+		p := &principal{key: k, scopes: k.ScopeList(), branches: k.BranchList()}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, p)))
+	})
+}
+
+// requireScope wraps a handler that needs a specific scope.
+func requireScope(scope string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p := principalFrom(r.Context())
+		if p == nil || !p.hasScope(scope) {
+			writeError(w, http.StatusForbidden, "API key lacks scope "+scope)
+			return
+		}
+		h(w, r)
+	}
+}
+
+// scopeBranches restricts q to the caller's branches on the given column.
+// An explicit branch_id outside the caller's branches is rejected with 403.
+func scopeBranches(w http.ResponseWriter, r *http.Request, q *gorm.DB, column string) (*gorm.DB, bool) {
+	p := principalFrom(r.Context())
+	if p == nil {
+		writeError(w, http.StatusUnauthorized, "not authenticated")
+		return q, false
+	}
+	if b := r.URL.Query().Get("branch_id"); b != "" && !p.canSeeBranch(b) {
+		writeError(w, http.StatusForbidden, "API key is not allowed to access branch "+b)
+		return q, false
+	}
+	if p.unrestricted() {
+		return q, true
+	}
+	return q.Where(column+" IN ?", p.branches), true
+}
+
+// auditRecorder captures the response status and the authenticated key for the audit log.
+type auditRecorder struct {
+	http.ResponseWriter
+	status    int
+	keyID     *int64
+	keyPrefix string
+}
+
+func (a *auditRecorder) WriteHeader(code int) {
+	a.status = code
+	a.ResponseWriter.WriteHeader(code)
+}
+
+// Flush keeps streaming responses working through the recorder.
+func (a *auditRecorder) Flush() {
+	if f, ok := a.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (a *auditRecorder) Unwrap() http.ResponseWriter {
+	return a.ResponseWriter
+}
+
+// audit writes one api_audit_log row per request and logs it to stdout.
+func (s *Server) audit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &auditRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		dur := time.Since(start)
+
+		s.lg.Printf("%s %s → %d (%s) key=%s", r.Method, r.URL.RequestURI(), rec.status,
+			dur.Round(time.Millisecond), valueOr(rec.keyPrefix, "-"))
+
+		if r.URL.Path == "/healthz" {
+			return
+		}
+		entry := &models.APIAuditLog{
+			APIKeyID:   rec.keyID,
+			KeyPrefix:  rec.keyPrefix,
+			Method:     r.Method,
+			Path:       r.URL.Path,
+			Query:      r.URL.RawQuery,
+			Status:     rec.status,
+			DurationMS: dur.Milliseconds(),
+			RemoteAddr: r.RemoteAddr,
+		}
+		if err := s.keys.InsertAudit(entry); err != nil {
+			s.lg.Printf("⚠️  api audit insert failed: %v", err)
+		}
+	})
+}
+
+func valueOr(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
@@ -0,0 +1,446 @@
+package api
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/araquach/phorest-datahub/internal/models"
+)
+
+// API payloads use snake_case keys matching the datahub column names.
+// Models are mapped explicitly so internal columns never leak by accident.
+
+type branchDTO struct {
+	BranchID     string   `json:"branch_id"`
+	Name         string   `json:"name"`
+	TimeZone     string   `json:"time_zone"`
+	City         string   `json:"city"`
+	PostalCode   string   `json:"postal_code"`
+	Country      string   `json:"country"`
+	CurrencyCode string   `json:"currency_code"`
+	Latitude     *float64 `json:"latitude,omitempty"`
+	Longitude    *float64 `json:"longitude,omitempty"`
+}
+
+func toBranchDTO(b models.Branch) branchDTO {
+	return branchDTO{
+		BranchID:     b.BranchID,
+		Name:         b.Name,
+		TimeZone:     b.TimeZone,
+		City:         b.City,
+		PostalCode:   b.PostalCode,
+		Country:      b.Country,
+		CurrencyCode: b.CurrencyCode,
+		Latitude:     b.Latitude,
+		Longitude:    b.Longitude,
+	}
+}
+
+type staffDTO struct {
+	StaffID           string     `json:"staff_id"`
+	BranchID          string     `json:"branch_id"`
+	FirstName         string     `json:"first_name"`
+	LastName          string     `json:"last_name"`
+	StaffCategoryID   string     `json:"staff_category_id"`
+	StaffCategoryName string     `json:"staff_category_name"`
+	StartDate         *time.Time `json:"start_date,omitempty"`
+	SelfEmployed      bool       `json:"self_employed"`
+	Archived          bool       `json:"archived"`
+	ImageURL          string     `json:"image_url,omitempty"`
+}
+
+func toStaffDTO(s models.Staff) staffDTO {
+	return staffDTO{
+		StaffID:           s.StaffID,
+		BranchID:          s.BranchID,
+		FirstName:         s.FirstName,
+		LastName:          s.LastName,
+		StaffCategoryID:   s.StaffCategoryID,
+		StaffCategoryName: s.StaffCategoryName,
+		StartDate:         s.StartDate,
+		SelfEmployed:      s.SelfEmployed,
+		Archived:          s.Archived,
+		ImageURL:          s.ImageURL,
+	}
+}
+
+// clientDTO: contact details, address, birth date and notes are PII and are
+// only populated when the caller is allowed to see them.
+type clientDTO struct {
+	ClientID         string     `json:"client_id"`
+	FirstName        string     `json:"first_name"`
+	LastName         string     `json:"last_name"`
+	Gender           string     `json:"gender,omitempty"`
+	CreatingBranchID string     `json:"creating_branch_id"`
+	PreferredStaffID string     `json:"preferred_staff_id,omitempty"`
+	ClientSince      *time.Time `json:"client_since,omitempty"`
+	FirstVisit       *time.Time `json:"first_visit,omitempty"`
+	LastVisit        *time.Time `json:"last_visit,omitempty"`
+	Archived         bool       `json:"archived"`
+	Deleted          bool       `json:"deleted"`
+	UpdatedAtPhorest *time.Time `json:"updated_at_phorest,omitempty"`
+	PIIRedacted      bool       `json:"pii_redacted"`
+
+	Mobile         string     `json:"mobile,omitempty"`
+	LandLine       string     `json:"land_line,omitempty"`
+	Email          string     `json:"email,omitempty"`
+	BirthDate      *time.Time `json:"birth_date,omitempty"`
+	StreetAddress1 string     `json:"street_address_1,omitempty"`
+	StreetAddress2 string     `json:"street_address_2,omitempty"`
+	City           string     `json:"city,omitempty"`
+	PostalCode     string     `json:"postal_code,omitempty"`
+	Notes          string     `json:"notes,omitempty"`
+}
+
+func toClientDTO(c models.Client, includePII bool) clientDTO {
+	out := clientDTO{
+		ClientID:         c.ClientID,
+		FirstName:        c.FirstName,
+		LastName:         c.LastName,
+		Gender:           c.Gender,
+		CreatingBranchID: c.CreatingBranchID,
+		PreferredStaffID: c.PreferredStaffID,
+		ClientSince:      c.ClientSince,
+		FirstVisit:       c.FirstVisit,
+		LastVisit:        c.LastVisit,
+		Archived:         c.Archived,
+		Deleted:          c.Deleted,
+		UpdatedAtPhorest: c.UpdatedAtPhorest,
+		PIIRedacted:      !includePII,
+	}
+	if !includePII {
+		out.LastName = initial(c.LastName)
+		return out
+	}
+	out.Mobile = c.Mobile
+	out.LandLine = c.LandLine
+	out.Email = c.Email
+	out.BirthDate = c.BirthDate
+	out.StreetAddress1 = c.StreetAddress1
+	out.StreetAddress2 = c.StreetAddress2
+	out.City = c.City
+	out.PostalCode = c.PostalCode
+	out.Notes = c.Notes
+	return out
+}
+
+type transactionDTO struct {
+	TransactionID    string     `json:"transaction_id"`
+	BranchID         string     `json:"branch_id"`
+	BranchName       string     `json:"branch_name"`
+	ClientID         string     `json:"client_id"`
+	ClientFirstName  string     `json:"client_first_name"`
+	ClientLastName   string     `json:"client_last_name"`
+	ClientSource     string     `json:"client_source"`
+	PurchasedDate    *time.Time `json:"purchased_date,omitempty"`
+	UpdatedAtPhorest *time.Time `json:"updated_at_phorest,omitempty"`
+}
+
+func toTransactionDTO(t models.Transaction, includePII bool) transactionDTO {
+	out := transactionDTO{
+		TransactionID:    t.TransactionID,
+		BranchID:         t.BranchID,
+		BranchName:       t.BranchName,
+		ClientID:         t.ClientID,
+		ClientFirstName:  t.ClientFirstName,
+		ClientLastName:   t.ClientLastName,
+		ClientSource:     t.ClientSource,
+		PurchasedDate:    t.PurchasedDate,
+		UpdatedAtPhorest: t.UpdatedAtPhorest,
+	}
+	if !includePII {
+		out.ClientLastName = initial(t.ClientLastName)
+	}
+	return out
+}
+
+type transactionItemDTO struct {
+	TransactionItemID   string     `json:"transaction_item_id"`
+	TransactionID       string     `json:"transaction_id"`
+	BranchID            string     `json:"branch_id"`
+	ClientID            string     `json:"client_id"`
+	ClientFirstName     string     `json:"client_first_name"`
+	ClientLastName      string     `json:"client_last_name"`
+	PurchasedDate       *time.Time `json:"purchased_date,omitempty"`
+	ItemType            string     `json:"item_type"`
+	Description         string     `json:"description"`
+	Quantity            float64    `json:"quantity"`
+	ServiceID           string     `json:"service_id,omitempty"`
+	ServiceName         string     `json:"service_name,omitempty"`
+	ServiceCategoryName string     `json:"service_category_name,omitempty"`
+	ProductID           string     `json:"product_id,omitempty"`
+	ProductName         string     `json:"product_name,omitempty"`
+	ProductBrandName    string     `json:"product_brand_name,omitempty"`
+	UnitPrice           float64    `json:"unit_price"`
+	OriginalPrice       float64    `json:"original_price"`
+	DiscountAmount      float64    `json:"discount_amount"`
+	TaxAmount           float64    `json:"tax_amount"`
+	TotalAmount         float64    `json:"total_amount"`
+	NetTotalAmount      float64    `json:"net_total_amount"`
+	GrossTotalAmount    float64    `json:"gross_total_amount"`
+	ProductCostPrice    float64    `json:"product_cost_price"`
+	StaffTips           float64    `json:"staff_tips"`
+	PaymentTypeNames    string     `json:"payment_type_names"`
+	Void                bool       `json:"void"`
+	StaffID             string     `json:"staff_id"`
+	StaffFirstName      string     `json:"staff_first_name"`
+	StaffLastName       string     `json:"staff_last_name"`
+	IsRequestedStaff    bool       `json:"is_requested_staff"`
+	AppointmentID       string     `json:"appointment_id,omitempty"`
+	AppointmentDate     *time.Time `json:"appointment_date,omitempty"`
+	OnlineBooking       bool       `json:"online_booking"`
+	UpdatedAtPhorest    *time.Time `json:"updated_at_phorest,omitempty"`
+}
+
+func toTransactionItemDTO(it models.TransactionItem, includePII bool) transactionItemDTO {
+	out := transactionItemDTO{
+		TransactionItemID:   it.TransactionItemID,
+		TransactionID:       it.TransactionID,
+		BranchID:            it.BranchID,
+		ClientID:            it.ClientID,
+		ClientFirstName:     it.ClientFirstName,
+		ClientLastName:      it.ClientLastName,
+		PurchasedDate:       it.PurchasedDate,
+		ItemType:            it.ItemType,
+		Description:         it.Description,
+		Quantity:            it.Quantity,
+		ServiceID:           it.ServiceID,
+		ServiceName:         it.ServiceName,
+		ServiceCategoryName: it.ServiceCategoryName,
+		ProductID:           it.ProductID,
+		ProductName:         it.ProductName,
+		ProductBrandName:    it.ProductBrandName,
+		UnitPrice:           it.UnitPrice,
+		OriginalPrice:       it.OriginalPrice,
+		DiscountAmount:      it.DiscountAmount,
+		TaxAmount:           it.TaxAmount,
+		TotalAmount:         it.TotalAmount,
+		NetTotalAmount:      it.NetTotalAmount,
+		GrossTotalAmount:    it.GrossTotalAmount,
+		ProductCostPrice:    it.ProductCostPrice,
+		StaffTips:           it.StaffTips,
+		PaymentTypeNames:    it.PaymentTypeNames,
+		Void:                it.Void != 0,
+		StaffID:             it.StaffID,
+		StaffFirstName:      it.StaffFirstName,
+		StaffLastName:       it.StaffLastName,
+		IsRequestedStaff:    it.IsRequestedStaff != 0,
+		AppointmentID:       it.AppointmentID,
+		AppointmentDate:     it.AppointmentDate,
+		OnlineBooking:       it.OnlineBooking != 0,
+		UpdatedAtPhorest:    it.UpdatedAtPhorest,
+	}
+	if !includePII {
+		out.ClientLastName = initial(it.ClientLastName)
+	}
+	return out
+}
+
+type reviewDTO struct {
+	ReviewID        string     `json:"review_id"`
+	BranchID        string     `json:"branch_id"`
+	ClientID        string     `json:"client_id"`
+	ClientFirstName string     `json:"client_first_name"`
+	ClientLastName  string     `json:"client_last_name"`
+	ReviewDate      *time.Time `json:"review_date,omitempty"`
+	VisitDate       *time.Time `json:"visit_date,omitempty"`
+	StaffID         string     `json:"staff_id"`
+	StaffFirstName  string     `json:"staff_first_name"`
+	StaffLastName   string     `json:"staff_last_name"`
+	Text            string     `json:"text"`
+	Rating          int        `json:"rating"`
+	FacebookReview  bool       `json:"facebook_review"`
+	TwitterReview   bool       `json:"twitter_review"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
+}
+
+func toReviewDTO(r models.Review, includePII bool) reviewDTO {
+	out := reviewDTO{
+		ReviewID:        r.ReviewID,
+		BranchID:        r.BranchID,
+		ClientID:        r.ClientID,
+		ClientFirstName: r.ClientFirstName,
+		ClientLastName:  r.ClientLastName,
+		ReviewDate:      r.ReviewDate,
+		VisitDate:       r.VisitDate,
+		StaffID:         r.StaffID,
+		StaffFirstName:  r.StaffFirstName,
+		StaffLastName:   r.StaffLastName,
+		Text:            r.Text,
+		Rating:          r.Rating,
+		FacebookReview:  r.FacebookReview,
+		TwitterReview:   r.TwitterReview,
+		DeletedAt:       r.DeletedAt,
+	}
+	if !includePII {
+		out.ClientLastName = initial(r.ClientLastName)
+	}
+	return out
+}
+
+// stockDTO is a ph_product_stock row joined with its ph_products master data.
+type stockDTO struct {
+	ProductID       string     `json:"product_id" gorm:"column:product_id"`
+	BranchID        string     `json:"branch_id" gorm:"column:branch_id"`
+	Name            string     `json:"name" gorm:"column:name"`
+	BrandName       *string    `json:"brand_name" gorm:"column:brand_name"`
+	CategoryName    *string    `json:"category_name" gorm:"column:category_name"`
+	TypeRaw         *string    `json:"type" gorm:"column:type_raw"`
+	Price           *float64   `json:"price" gorm:"column:price"`
+	MinQuantity     *float64   `json:"min_quantity" gorm:"column:min_quantity"`
+	MaxQuantity     *float64   `json:"max_quantity" gorm:"column:max_quantity"`
+	QuantityInStock *float64   `json:"quantity_in_stock" gorm:"column:quantity_in_stock"`
+	ReorderCount    *float64   `json:"reorder_count" gorm:"column:reorder_count"`
+	ReorderCost     *float64   `json:"reorder_cost" gorm:"column:reorder_cost"`
+	Archived        bool       `json:"archived" gorm:"column:archived"`
+	UpdatedAtPh     *time.Time `json:"updated_at_ph" gorm:"column:updated_at_ph"`
+	LastSyncedAt    time.Time  `json:"last_synced_at" gorm:"column:last_synced_at"`
+	RemovedAt       *time.Time `json:"removed_at,omitempty" gorm:"column:removed_at"`
+}
+
+type stockHistoryDTO struct {
+	ProductID       string    `json:"product_id"`
+	BranchID        string    `json:"branch_id"`
+	SnapshotTime    time.Time `json:"snapshot_time"`
+	QuantityInStock *float64  `json:"quantity_in_stock"`
+	Price           *float64  `json:"price"`
+	Source          string    `json:"source"`
+}
+
+func toStockHistoryDTO(h models.PhProductStockHistory) stockHistoryDTO {
+	return stockHistoryDTO{
+		ProductID:       h.ProductID,
+		BranchID:        h.BranchID,
+		SnapshotTime:    h.SnapshotTime,
+		QuantityInStock: h.QuantityInStock,
+		Price:           h.Price,
+		Source:          h.Source,
+	}
+}
+
+type productChangeDTO struct {
+	Entity    string    `json:"entity"`
+	ProductID string    `json:"product_id"`
+	BranchID  string    `json:"branch_id"`
+	Field     string    `json:"field"`
+	OldValue  *string   `json:"old_value"`
+	NewValue  *string   `json:"new_value"`
+	RunID     *int64    `json:"run_id,omitempty"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+func toProductChangeDTO(c models.ProductChange) productChangeDTO {
+	return productChangeDTO{
+		Entity:    c.Entity,
+		ProductID: c.ProductID,
+		BranchID:  c.BranchID,
+		Field:     c.Field,
+		OldValue:  c.OldValue,
+		NewValue:  c.NewValue,
+		RunID:     c.RunID,
+		ChangedAt: c.ChangedAt,
+	}
+}
+
+func initial(s string) string {
+	for _, r := range s {
+		return string(r) + "."
+	}
+	return ""
+}
+
+type alertDTO struct {
+	ID         int64      `json:"id"`
+	Kind       string     `json:"kind"`
+	Severity   string     `json:"severity"`
+	BranchID   string     `json:"branch_id"`
+	StaffID    string     `json:"staff_id,omitempty"`
+	Title      string     `json:"title"`
+	Detail     string     `json:"detail,omitempty"`
+	Value      *float64   `json:"value,omitempty"`
+	Baseline   *float64   `json:"baseline,omitempty"`
+	RaisedAt   time.Time  `json:"raised_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	NotifiedAt *time.Time `json:"notified_at,omitempty"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+func toAlertDTO(a models.Alert) alertDTO {
+	return alertDTO{
+		ID:         a.ID,
+		Kind:       a.Kind,
+		Severity:   a.Severity,
+		BranchID:   a.BranchID,
+		StaffID:    a.StaffID,
+		Title:      a.Title,
+		Detail:     a.Detail,
+		Value:      a.Value,
+		Baseline:   a.Baseline,
+		RaisedAt:   a.RaisedAt,
+		LastSeenAt: a.LastSeenAt,
+		NotifiedAt: a.NotifiedAt,
+		ResolvedAt: a.ResolvedAt,
+	}
+}
+
+type notificationDeliveryDTO struct {
+	ID          int64           `json:"id"`
+	EventType   string          `json:"event_type"`
+	Channel     string          `json:"channel"`
+	Severity    string          `json:"severity"`
+	BranchID    string          `json:"branch_id,omitempty"`
+	StaffID     string          `json:"staff_id,omitempty"`
+	Title       string          `json:"title"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      string          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	LastError   *string         `json:"last_error,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	DeliveredAt *time.Time      `json:"delivered_at,omitempty"`
+}
+
+func toNotificationDeliveryDTO(d models.NotificationDelivery) notificationDeliveryDTO {
+	return notificationDeliveryDTO{
+		ID:          d.ID,
+		EventType:   d.EventType,
+		Channel:     d.Channel,
+		Severity:    d.Severity,
+		BranchID:    d.BranchID,
+		StaffID:     d.StaffID,
+		Title:       d.Title,
+		Payload:     json.RawMessage(d.Payload),
+		Status:      d.Status,
+		Attempts:    d.Attempts,
+		LastError:   d.LastError,
+		CreatedAt:   d.CreatedAt,
+		DeliveredAt: d.DeliveredAt,
+	}
+}
+
+type serviceDTO struct {
+	ServiceID       string     `json:"service_id"`
+	BranchID        string     `json:"branch_id"`
+	Name            string     `json:"name"`
+	CategoryID      *string    `json:"category_id"`
+	CategoryName    *string    `json:"category_name"`
+	DurationMinutes *int       `json:"duration_minutes"`
+	Price           *float64   `json:"price"`
+	Archived        bool       `json:"archived"`
+	UpdatedAtPh     *time.Time `json:"updated_at_ph"`
+	LastSyncedAt    time.Time  `json:"last_synced_at"`
+}
+
+func toServiceDTO(s models.PhService) serviceDTO {
+	return serviceDTO{
+		ServiceID:       s.ID,
+		BranchID:        s.BranchID,
+		Name:            s.Name,
+		CategoryID:      s.CategoryID,
+		CategoryName:    s.CategoryName,
+		DurationMinutes: s.DurationMinutes,
+		Price:           s.Price,
+		Archived:        s.Archived,
+		UpdatedAtPh:     s.UpdatedAtPh,
+		LastSyncedAt:    s.LastSyncedAt,
+	}
+}
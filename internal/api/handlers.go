@@ -0,0 +1,951 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/araquach/phorest-datahub/internal/models"
+	"github.com/araquach/phorest-datahub/internal/reports"
+	"github.com/araquach/phorest-datahub/internal/services"
+	"gorm.io/gorm"
+)
+
+// includePII is true only when the caller's key has read:clients-pii AND it asked for PII.
+func (s *Server) includePII(r *http.Request) bool {
+	p := principalFrom(r.Context())
+	return p != nil && p.hasScope(ScopeReadClientsPII) && boolParam(r.URL.Query(), "include_pii")
+}
+
+// scopeClients limits clients to those created at, or with sales at, the caller's branches.
+func (s *Server) scopeClients(w http.ResponseWriter, r *http.Request, q *gorm.DB) (*gorm.DB, bool) {
+	p := principalFrom(r.Context())
+	if p == nil {
+		writeError(w, http.StatusUnauthorized, "not authenticated")
+		return q, false
+	}
+	if b := r.URL.Query().Get("branch_id"); b != "" && !p.canSeeBranch(b) {
+		writeError(w, http.StatusForbidden, "API key is not allowed to access branch "+b)
+		return q, false
+	}
+	if p.unrestricted() {
+		return q, true
+	}
+	return q.Where(`(clients.creating_branch_id IN ? OR EXISTS (
+		SELECT 1 FROM transactions t
+		WHERE t.client_id = clients.client_id AND t.branch_id IN ?))`, p.branches, p.branches), true
+}
+
+// list runs a paginated query: count the filtered set, then fetch one page into dst.
+func (s *Server) list(w http.ResponseWriter, r *http.Request, q *gorm.DB, order string, dst any) (pagination, int64, bool) {
+	pg, err := parsePagination(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return pg, 0, false
+	}
+
+	var total int64
+	if err := q.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		s.lg.Printf("❌ api count %s: %v", r.URL.Path, err)
+		writeError(w, http.StatusInternalServerError, "query failed")
+		return pg, 0, false
+	}
+	if err := pg.apply(q.Order(order)).Find(dst).Error; err != nil {
+		s.lg.Printf("❌ api list %s: %v", r.URL.Path, err)
+		writeError(w, http.StatusInternalServerError, "query failed")
+		return pg, 0, false
+	}
+	return pg, total, true
+}
+
+func (s *Server) handleBranches(w http.ResponseWriter, r *http.Request) {
+	q, ok := scopeBranches(w, r, s.db.WithContext(r.Context()).Model(&models.Branch{}), "branch_id")
+	if !ok {
+		return
+	}
+
+	var rows []models.Branch
+	pg, total, ok := s.list(w, r, q, "name", &rows)
+	if !ok {
+		return
+	}
+	out := make([]branchDTO, 0, len(rows))
+	for _, b := range rows {
+		out = append(out, toBranchDTO(b))
+	}
+	writeList(w, r, out, pg, total)
+}
+
+func (s *Server) handleStaff(w http.ResponseWriter, r *http.Request) {
+	v := r.URL.Query()
+	q, ok := scopeBranches(w, r, s.db.WithContext(r.Context()).Model(&models.Staff{}), "branch_id")
+	if !ok {
+		return
+	}
+	q = applyEq(q, v, map[string]string{
+		"branch_id": "branch_id",
+		"staff_id":  "staff_id",
+	})
+	if !boolParam(v, "include_archived") {
+		q = q.Where("archived = false")
+	}
+
+	var rows []models.Staff
+	pg, total, ok := s.list(w, r, q, "branch_id, first_name, last_name", &rows)
+	if !ok {
+		return
+	}
+	out := make([]staffDTO, 0, len(rows))
+	for _, st := range rows {
+		out = append(out, toStaffDTO(st))
+	}
+	writeList(w, r, out, pg, total)
+}
+
+func (s *Server) handleClients(w http.ResponseWriter, r *http.Request) {
+	v := r.URL.Query()
+	q, ok := s.scopeClients(w, r, s.db.WithContext(r.Context()).Model(&models.Client{}))
+	if !ok {
+		return
+	}
+	q = applyEq(q, v, map[string]string{
+		"branch_id":          "creating_branch_id",
+		"preferred_staff_id": "preferred_staff_id",
+	})
+	if since := v.Get("updated_since"); since != "" {
+		t, err := time.Parse("2006-01-02", since)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid updated_since (want YYYY-MM-DD)")
+			return
+		}
+		q = q.Where("updated_at_phorest >= ?", t)
+	}
+	if !boolParam(v, "include_deleted") {
+		q = q.Where("COALESCE(deleted, false) = false")
+	}
+
+	var rows []models.Client
+	pg, total, ok := s.list(w, r, q, "client_id", &rows)
+	if !ok {
+		return
+	}
+	pii := s.includePII(r)
+	out := make([]clientDTO, 0, len(rows))
+	for _, c := range rows {
+		out = append(out, toClientDTO(c, pii))
+	}
+	writeList(w, r, out, pg, total)
+}
+
+func (s *Server) handleClient(w http.ResponseWriter, r *http.Request) {
+	q, ok := s.scopeClients(w, r, s.db.WithContext(r.Context()).Model(&models.Client{}))
+	if !ok {
+		return
+	}
+
+	var c models.Client
+	err := q.Where("client_id = ?", r.PathValue("clientID")).First(&c).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		writeError(w, http.StatusNotFound, "client not found")
+		return
+	}
+	if err != nil {
+		s.lg.Printf("❌ api client: %v", err)
+		writeError(w, http.StatusInternalServerError, "query failed")
+		return
+	}
+	writeJSON(w, r, http.StatusOK, toClientDTO(c, s.includePII(r)))
+}
+
+func (s *Server) handleTransactions(w http.ResponseWriter, r *http.Request) {
+	v := r.URL.Query()
+	dr, err := parseDateRange(v)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	q, ok := scopeBranches(w, r, s.db.WithContext(r.Context()).Model(&models.Transaction{}), "branch_id")
+	if !ok {
+		return
+	}
+	q = applyEq(q, v, map[string]string{
+		"branch_id": "branch_id",
+		"client_id": "client_id",
+	})
+	q = dr.apply(q, "purchased_date")
+	if staffID := v.Get("staff_id"); staffID != "" {
+		// Transactions have no staff column; match any line sold by that stylist.
+		q = q.Where(`EXISTS (
+			SELECT 1 FROM transaction_items ti
+			WHERE ti.transaction_id = transactions.transaction_id AND ti.staff_id = ?)`, staffID)
+	}
+
+	var rows []models.Transaction
+	pg, total, ok := s.list(w, r, q, "purchased_date DESC, purchase_time DESC, transaction_id", &rows)
+	if !ok {
+		return
+	}
+	pii := s.includePII(r)
+	out := make([]transactionDTO, 0, len(rows))
+	for _, t := range rows {
+		out = append(out, toTransactionDTO(t, pii))
+	}
+	writeList(w, r, out, pg, total)
+}
+
+func (s *Server) handleTransactionItems(w http.ResponseWriter, r *http.Request) {
+	v := r.URL.Query()
+	dr, err := parseDateRange(v)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	q, ok := scopeBranches(w, r, s.db.WithContext(r.Context()).Model(&models.TransactionItem{}), "branch_id")
+	if !ok {
+		return
+	}
+	q = applyEq(q, v, map[string]string{
+		"branch_id":      "branch_id",
+		"staff_id":       "staff_id",
+		"client_id":      "client_id",
+		"transaction_id": "transaction_id",
+		"item_type":      "item_type",
+		"service_id":     "service_id",
+		"product_id":     "product_id",
+	})
+	q = dr.apply(q, "purchased_date")
+	if !boolParam(v, "include_void") {
+		q = q.Where("COALESCE(void, 0) = 0")
+	}
+
+	var rows []models.TransactionItem
+	pg, total, ok := s.list(w, r, q, "purchased_date DESC, purchase_time DESC, transaction_item_id", &rows)
+	if !ok {
+		return
+	}
+	pii := s.includePII(r)
+	out := make([]transactionItemDTO, 0, len(rows))
+	for _, it := range rows {
+		out = append(out, toTransactionItemDTO(it, pii))
+	}
+	writeList(w, r, out, pg, total)
+}
+
+func (s *Server) handleReviews(w http.ResponseWriter, r *http.Request) {
+	v := r.URL.Query()
+	dr, err := parseDateRange(v)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	q, ok := scopeBranches(w, r, s.db.WithContext(r.Context()).Model(&models.Review{}), "branch_id")
+	if !ok {
+		return
+	}
+	q = applyEq(q, v, map[string]string{
+		"branch_id": "branch_id",
+		"staff_id":  "staff_id",
+		"client_id": "client_id",
+	})
+	q = dr.apply(q, "review_date")
+	if !boolParam(v, "include_deleted") {
+		q = q.Where("deleted_at IS NULL")
+	}
+	for param, op := range map[string]string{"min_rating": ">=", "max_rating": "<="} {
+		if s := v.Get(param); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "invalid "+param)
+				return
+			}
+			q = q.Where("rating "+op+" ?", n)
+		}
+	}
+
+	var rows []models.Review
+	pg, total, ok := s.list(w, r, q, "review_date DESC, review_id", &rows)
+	if !ok {
+		return
+	}
+	pii := s.includePII(r)
+	out := make([]reviewDTO, 0, len(rows))
+	for _, rv := range rows {
+		out = append(out, toReviewDTO(rv, pii))
+	}
+	writeList(w, r, out, pg, total)
+}
+
+func (s *Server) handleProductStock(w http.ResponseWriter, r *http.Request) {
+	v := r.URL.Query()
+	q := s.db.WithContext(r.Context()).
+		Table("ph_product_stock AS s").
+		Select(`s.product_id, s.branch_id, p.name, p.brand_name, p.category_name, p.type_raw,
+			s.price, s.min_quantity, s.max_quantity, s.quantity_in_stock,
+			s.reorder_count, s.reorder_cost, s.archived, s.updated_at_ph, s.last_synced_at, s.removed_at`).
+		Joins("JOIN ph_products p ON p.id = s.product_id")
+	q, ok := scopeBranches(w, r, q, "s.branch_id")
+	if !ok {
+		return
+	}
+	q = applyEq(q, v, map[string]string{
+		"branch_id":  "s.branch_id",
+		"product_id": "s.product_id",
+		"brand_name": "p.brand_name",
+	})
+	if !boolParam(v, "include_archived") {
+		q = q.Where("s.archived = false AND s.removed_at IS NULL")
+	}
+
+	var rows []stockDTO
+	pg, total, ok := s.list(w, r, q, "p.brand_name, p.name, s.branch_id", &rows)
+	if !ok {
+		return
+	}
+	writeList(w, r, rows, pg, total)
+}
+
+func (s *Server) handleProductStockHistory(w http.ResponseWriter, r *http.Request) {
+	v := r.URL.Query()
+	dr, err := parseDateRange(v)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	q, ok := scopeBranches(w, r, s.db.WithContext(r.Context()).Model(&models.PhProductStockHistory{}), "branch_id")
+	if !ok {
+		return
+	}
+	q = applyEq(q, v, map[string]string{
+		"branch_id":  "branch_id",
+		"product_id": "product_id",
+	})
+	q = dr.apply(q, "snapshot_time::date")
+
+	var rows []models.PhProductStockHistory
+	pg, total, ok := s.list(w, r, q, "snapshot_time DESC, id DESC", &rows)
+	if !ok {
+		return
+	}
+	out := make([]stockHistoryDTO, 0, len(rows))
+	for _, h := range rows {
+		out = append(out, toStockHistoryDTO(h))
+	}
+	writeList(w, r, out, pg, total)
+}
+
+// handleProductChanges lists logged product/stock field changes, newest
+// first. Filter with product_id, branch_id, entity, field and from/to.
+func (s *Server) handleProductChanges(w http.ResponseWriter, r *http.Request) {
+	v := r.URL.Query()
+	dr, err := parseDateRange(v)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	q, ok := scopeBranches(w, r, s.db.WithContext(r.Context()).Model(&models.ProductChange{}), "branch_id")
+	if !ok {
+		return
+	}
+	q = applyEq(q, v, map[string]string{
+		"branch_id":  "branch_id",
+		"product_id": "product_id",
+		"entity":     "entity",
+		"field":      "field",
+	})
+	q = dr.apply(q, "changed_at::date")
+
+	var rows []models.ProductChange
+	pg, total, ok := s.list(w, r, q, "changed_at DESC, id DESC", &rows)
+	if !ok {
+		return
+	}
+	out := make([]productChangeDTO, 0, len(rows))
+	for _, c := range rows {
+		out = append(out, toProductChangeDTO(c))
+	}
+	writeList(w, r, out, pg, total)
+}
+
+// kpiSnapshotDTO is one stylist's KPIs for the requested period.
+type kpiSnapshotDTO struct {
+	StaffID  string             `json:"staff_id"`
+	BranchID string             `json:"branch_id"`
+	From     string             `json:"from"`
+	To       string             `json:"to"`
+	Metrics  map[string]float64 `json:"metrics"`
+}
+
+// branchPeriod reads the required branch_id (checked against the key's
+// branches) and a from/to period defaulting to the last 30 days.
+func branchPeriod(w http.ResponseWriter, r *http.Request) (string, services.Period, bool) {
+	branchID, ok := requireBranch(w, r)
+	if !ok {
+		return "", services.Period{}, false
+	}
+	dr, err := parseDateRange(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return "", services.Period{}, false
+	}
+	to := time.Now().UTC()
+	if dr.To != nil {
+		to = *dr.To
+	}
+	from := to.AddDate(0, 0, -29)
+	if dr.From != nil {
+		from = *dr.From
+	}
+	return branchID, services.NewPeriod(from, to), true
+}
+
+// handleKPIs returns a KPI snapshot per stylist for a branch and date range
+// (defaults to the last 30 days). Not paginated: one row per active stylist.
+func (s *Server) handleKPIs(w http.ResponseWriter, r *http.Request) {
+	v := r.URL.Query()
+	branchID, p, ok := branchPeriod(w, r)
+	if !ok {
+		return
+	}
+
+	var err error
+	var rows []services.StaffKPIs
+	if staffID := v.Get("staff_id"); staffID != "" {
+		var k services.StaffKPIs
+		k, err = s.kpis.StaffKPIs(r.Context(), staffID, branchID, p)
+		rows = []services.StaffKPIs{k}
+	} else {
+		rows, err = s.kpis.BranchKPIs(r.Context(), branchID, p)
+	}
+	if err != nil {
+		s.lg.Printf("❌ api kpis: %v", err)
+		writeError(w, http.StatusInternalServerError, "query failed")
+		return
+	}
+
+	out := make([]kpiSnapshotDTO, 0, len(rows))
+	for _, k := range rows {
+		m := make(map[string]float64, len(services.KPIMetrics))
+		for _, def := range services.KPIMetrics {
+			m[def.Key], _ = k.Metric(def.Key)
+		}
+		out = append(out, kpiSnapshotDTO{
+			StaffID:  k.StaffID,
+			BranchID: branchID,
+			From:     p.From.Format("2006-01-02"),
+			To:       p.To.Format("2006-01-02"),
+			Metrics:  m,
+		})
+	}
+	writeJSON(w, r, http.StatusOK, map[string]any{"data": out})
+}
+
+// reviewInsightsDTO is one stylist's review sentiment/topic rollup.
+type reviewInsightsDTO struct {
+	BranchID string `json:"branch_id"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	services.StaffReviewInsights
+}
+
+// handleReviewInsights returns per-stylist review sentiment, topics and
+// praise/complaint quotes for a branch and date range (defaults to the last
+// 30 days). Not paginated: one row per reviewed stylist.
+func (s *Server) handleReviewInsights(w http.ResponseWriter, r *http.Request) {
+	branchID, p, ok := branchPeriod(w, r)
+	if !ok {
+		return
+	}
+
+	var err error
+	var rows []services.StaffReviewInsights
+	if staffID := r.URL.Query().Get("staff_id"); staffID != "" {
+		var in services.StaffReviewInsights
+		in, err = s.insights.StaffInsights(r.Context(), staffID, branchID, p)
+		rows = []services.StaffReviewInsights{in}
+	} else {
+		rows, err = s.insights.BranchInsights(r.Context(), branchID, p)
+	}
+	if err != nil {
+		s.lg.Printf("❌ api review insights: %v", err)
+		writeError(w, http.StatusInternalServerError, "query failed")
+		return
+	}
+
+	out := make([]reviewInsightsDTO, 0, len(rows))
+	for _, in := range rows {
+		out = append(out, reviewInsightsDTO{
+			BranchID:            branchID,
+			From:                p.From.Format("2006-01-02"),
+			To:                  p.To.Format("2006-01-02"),
+			StaffReviewInsights: in,
+		})
+	}
+	writeJSON(w, r, http.StatusOK, map[string]any{"data": out})
+}
+
+// handleRatingTrends returns rolling 30/90-day review averages per stylist
+// with their own 12-month baseline, as of ?as_of (default today).
+func (s *Server) handleRatingTrends(w http.ResponseWriter, r *http.Request) {
+	branchID, ok := requireBranch(w, r)
+	if !ok {
+		return
+	}
+	v := r.URL.Query()
+	asOf := time.Now().UTC()
+	if raw := v.Get("as_of"); raw != "" {
+		t, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "as_of must be YYYY-MM-DD")
+			return
+		}
+		asOf = t
+	}
+
+	rows, err := s.trends.BranchTrends(r.Context(), branchID, asOf)
+	if err != nil {
+		s.lg.Printf("❌ api rating trends: %v", err)
+		writeError(w, http.StatusInternalServerError, "query failed")
+		return
+	}
+	writeJSON(w, r, http.StatusOK, map[string]any{
+		"branch_id": branchID,
+		"as_of":     asOf.Format("2006-01-02"),
+		"data":      rows,
+	})
+}
+
+// handleAlerts lists alerts, newest first. Open alerts only unless
+// ?include_resolved=true.
+func (s *Server) handleAlerts(w http.ResponseWriter, r *http.Request) {
+	v := r.URL.Query()
+	q, ok := scopeBranches(w, r, s.db.WithContext(r.Context()).Model(&models.Alert{}), "branch_id")
+	if !ok {
+		return
+	}
+	q = applyEq(q, v, map[string]string{
+		"branch_id": "branch_id",
+		"staff_id":  "staff_id",
+		"kind":      "kind",
+		"severity":  "severity",
+	})
+	if !boolParam(v, "include_resolved") {
+		q = q.Where("resolved_at IS NULL")
+	}
+
+	var rows []models.Alert
+	pg, total, ok := s.list(w, r, q, "raised_at DESC, id DESC", &rows)
+	if !ok {
+		return
+	}
+	out := make([]alertDTO, 0, len(rows))
+	for _, a := range rows {
+		out = append(out, toAlertDTO(a))
+	}
+	writeList(w, r, out, pg, total)
+}
+
+// handleReorder returns a branch's stock reorder list, grouped by brand, as
+// JSON (default), CSV (?format=csv) or an HTML page (?format=html).
+func (s *Server) handleReorder(w http.ResponseWriter, r *http.Request) {
+	branchID, ok := requireBranch(w, r)
+	if !ok {
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "csv" && format != "html" {
+		writeError(w, http.StatusBadRequest, "format must be json, csv or html")
+		return
+	}
+
+	doc, err := s.reorder.Build(r.Context(), branchID)
+	if err != nil {
+		s.lg.Printf("❌ api reorder: %v", err)
+		writeError(w, http.StatusInternalServerError, "query failed")
+		return
+	}
+
+	switch format {
+	case "csv":
+		s.writeCSVFile(w, fmt.Sprintf("reorder_%s_%s.csv", branchID, doc.GeneratedAt.Format("20060102")),
+			func(out io.Writer) error { return reports.WriteReorderCSV(out, doc) })
+	case "html":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := reports.RenderReorderHTML(w, doc); err != nil {
+			s.lg.Printf("❌ api reorder html: %v", err)
+		}
+	default:
+		writeJSON(w, r, http.StatusOK, map[string]any{
+			"branch_name": doc.BranchName,
+			"currency":    doc.Currency,
+			"data":        doc.ReorderReport,
+		})
+	}
+}
+
+// requireBranch reads the required branch_id and checks the key may see it.
+func requireBranch(w http.ResponseWriter, r *http.Request) (string, bool) {
+	branchID := r.URL.Query().Get("branch_id")
+	if branchID == "" {
+		writeError(w, http.StatusBadRequest, "branch_id is required")
+		return "", false
+	}
+	if p := principalFrom(r.Context()); p == nil || !p.canSeeBranch(branchID) {
+		writeError(w, http.StatusForbidden, "API key is not allowed to access branch "+branchID)
+		return "", false
+	}
+	return branchID, true
+}
+
+// wantCSV reports whether ?format=csv was asked for (anything but csv/json is a 400).
+func wantCSV(w http.ResponseWriter, r *http.Request) (csv, ok bool) {
+	switch r.URL.Query().Get("format") {
+	case "", "json":
+		return false, true
+	case "csv":
+		return true, true
+	}
+	writeError(w, http.StatusBadRequest, "format must be json or csv")
+	return false, false
+}
+
+// writeCSVFile streams a CSV download.
+func (s *Server) writeCSVFile(w http.ResponseWriter, filename string, write func(io.Writer) error) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	if err := write(w); err != nil {
+		s.lg.Printf("❌ api csv %s: %v", filename, err)
+	}
+}
+
+// handleStockValuation values a branch's stock at cost and retail at the end
+// of ?date (default today).
+func (s *Server) handleStockValuation(w http.ResponseWriter, r *http.Request) {
+	branchID, ok := requireBranch(w, r)
+	if !ok {
+		return
+	}
+	asCSV, ok := wantCSV(w, r)
+	if !ok {
+		return
+	}
+	asOf := time.Now().UTC()
+	if raw := r.URL.Query().Get("date"); raw != "" {
+		t, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "date must be YYYY-MM-DD")
+			return
+		}
+		asOf = t
+	}
+
+	v, err := s.stock.Valuation(r.Context(), branchID, asOf)
+	if err != nil {
+		s.lg.Printf("❌ api stock valuation: %v", err)
+		writeError(w, http.StatusInternalServerError, "query failed")
+		return
+	}
+	if asCSV {
+		s.writeCSVFile(w, fmt.Sprintf("stock_valuation_%s_%s.csv", branchID, v.AsOf.Format("20060102")),
+			func(out io.Writer) error { return reports.WriteValuationCSV(out, v) })
+		return
+	}
+	writeJSON(w, r, http.StatusOK, map[string]any{"data": v})
+}
+
+// handleStockMovements returns opening/closing/net stock per product for a
+// period (default the last 30 days). ?moved_only=true drops unchanged products.
+func (s *Server) handleStockMovements(w http.ResponseWriter, r *http.Request) {
+	branchID, p, ok := branchPeriod(w, r)
+	if !ok {
+		return
+	}
+	asCSV, ok := wantCSV(w, r)
+	if !ok {
+		return
+	}
+
+	rows, err := s.stock.Movements(r.Context(), branchID, p, boolParam(r.URL.Query(), "moved_only"))
+	if err != nil {
+		s.lg.Printf("❌ api stock movements: %v", err)
+		writeError(w, http.StatusInternalServerError, "query failed")
+		return
+	}
+	if asCSV {
+		s.writeCSVFile(w, fmt.Sprintf("stock_movements_%s_%s_%s.csv", branchID, p.From.Format("20060102"), p.To.Format("20060102")),
+			func(out io.Writer) error { return reports.WriteMovementsCSV(out, branchID, p, rows) })
+		return
+	}
+	writeJSON(w, r, http.StatusOK, map[string]any{
+		"branch_id": branchID,
+		"from":      p.From.Format("2006-01-02"),
+		"to":        p.To.Format("2006-01-02"),
+		"data":      rows,
+	})
+}
+
+// handleDeadStock lists products in stock with no retail sales in the last
+// ?days (default 90).
+func (s *Server) handleDeadStock(w http.ResponseWriter, r *http.Request) {
+	branchID, ok := requireBranch(w, r)
+	if !ok {
+		return
+	}
+	asCSV, ok := wantCSV(w, r)
+	if !ok {
+		return
+	}
+	days := 90
+	if raw := r.URL.Query().Get("days"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > 3650 {
+			writeError(w, http.StatusBadRequest, "days must be between 1 and 3650")
+			return
+		}
+		days = n
+	}
+
+	rows, err := s.stock.DeadStock(r.Context(), branchID, days, time.Now().UTC())
+	if err != nil {
+		s.lg.Printf("❌ api dead stock: %v", err)
+		writeError(w, http.StatusInternalServerError, "query failed")
+		return
+	}
+	if asCSV {
+		s.writeCSVFile(w, fmt.Sprintf("dead_stock_%s_%dd.csv", branchID, days),
+			func(out io.Writer) error { return reports.WriteDeadStockCSV(out, branchID, days, rows) })
+		return
+	}
+	writeJSON(w, r, http.StatusOK, map[string]any{
+		"branch_id": branchID,
+		"days":      days,
+		"data":      rows,
+	})
+}
+
+// handleShrinkage returns the stored stock_shrinkage rows for a branch and
+// period (default the last 30 days) with totals per brand. Rows are written
+// by appraisals-stock -report=shrinkage or POST /api/products/shrinkage; an
+// unreconciled period returns no rows. ?flagged=true keeps products flagged
+// as shrinkage.
+func (s *Server) handleShrinkage(w http.ResponseWriter, r *http.Request) {
+	branchID, p, ok := branchPeriod(w, r)
+	if !ok {
+		return
+	}
+	asCSV, ok := wantCSV(w, r)
+	if !ok {
+		return
+	}
+	q := r.URL.Query()
+
+	rows, err := s.shrinkage.List(branchID, p.From, p.To, false)
+	if err != nil {
+		s.lg.Printf("❌ api shrinkage: %v", err)
+		writeError(w, http.StatusInternalServerError, "query failed")
+		return
+	}
+	summary := services.SummariseShrinkage(rows)
+	if boolParam(q, "flagged") {
+		kept := rows[:0]
+		for _, row := range rows {
+			if row.Flagged {
+				kept = append(kept, row)
+			}
+		}
+		rows = kept
+	}
+
+	if asCSV {
+		s.writeCSVFile(w, fmt.Sprintf("shrinkage_%s_%s_%s.csv", branchID, p.From.Format("20060102"), p.To.Format("20060102")),
+			func(out io.Writer) error { return reports.WriteShrinkageCSV(out, rows) })
+		return
+	}
+	writeJSON(w, r, http.StatusOK, map[string]any{
+		"branch_id": branchID,
+		"from":      p.From.Format("2006-01-02"),
+		"to":        p.To.Format("2006-01-02"),
+		"summary":   summary,
+		"data":      rows,
+	})
+}
+
+// handleRecomputeShrinkage reconciles a branch and period (?branch_id&from&to,
+// default the last 30 days) and replaces its stored stock_shrinkage rows.
+func (s *Server) handleRecomputeShrinkage(w http.ResponseWriter, r *http.Request) {
+	branchID, p, ok := branchPeriod(w, r)
+	if !ok {
+		return
+	}
+
+	rows, err := s.stock.Shrinkage(r.Context(), branchID, p)
+	if err == nil {
+		err = s.shrinkage.ReplacePeriod(branchID, p.From, p.To, rows)
+	}
+	if err != nil {
+		s.lg.Printf("❌ api recompute shrinkage: %v", err)
+		writeError(w, http.StatusInternalServerError, "reconcile failed")
+		return
+	}
+	writeJSON(w, r, http.StatusOK, map[string]any{
+		"branch_id": branchID,
+		"from":      p.From.Format("2006-01-02"),
+		"to":        p.To.Format("2006-01-02"),
+		"summary":   services.SummariseShrinkage(rows),
+	})
+}
+
+// handleBackBar estimates professional (back-bar) product cost per stylist
+// and per service for a period (default the last 30 days). ?staff_id= keeps
+// one stylist.
+func (s *Server) handleBackBar(w http.ResponseWriter, r *http.Request) {
+	branchID, p, ok := branchPeriod(w, r)
+	if !ok {
+		return
+	}
+	asCSV, ok := wantCSV(w, r)
+	if !ok {
+		return
+	}
+
+	rep, err := s.stock.BackBarUsage(r.Context(), branchID, p)
+	if err != nil {
+		s.lg.Printf("❌ api back-bar usage: %v", err)
+		writeError(w, http.StatusInternalServerError, "query failed")
+		return
+	}
+	if staffID := r.URL.Query().Get("staff_id"); staffID != "" {
+		kept := []services.StaffBackBar{}
+		if st := rep.ForStaff(staffID); st != nil {
+			kept = append(kept, *st)
+		}
+		rep.Staff = kept
+	}
+
+	if asCSV {
+		s.writeCSVFile(w, fmt.Sprintf("backbar_%s_%s_%s.csv", branchID, p.From.Format("20060102"), p.To.Format("20060102")),
+			func(out io.Writer) error { return reports.WriteBackBarCSV(out, rep) })
+		return
+	}
+	writeJSON(w, r, http.StatusOK, map[string]any{
+		"branch_id": branchID,
+		"from":      p.From.Format("2006-01-02"),
+		"to":        p.To.Format("2006-01-02"),
+		"data":      rep,
+	})
+}
+
+// handleUtilisation reports booked vs rostered minutes per stylist by day
+// and week (?branch_id&from&to, optional staff_id, ?format=csv for daily rows).
+func (s *Server) handleUtilisation(w http.ResponseWriter, r *http.Request) {
+	branchID, p, ok := branchPeriod(w, r)
+	if !ok {
+		return
+	}
+	asCSV, ok := wantCSV(w, r)
+	if !ok {
+		return
+	}
+
+	rep, err := s.util.Utilisation(r.Context(), branchID, p)
+	if err != nil {
+		s.lg.Printf("❌ api utilisation: %v", err)
+		writeError(w, http.StatusInternalServerError, "query failed")
+		return
+	}
+	if staffID := r.URL.Query().Get("staff_id"); staffID != "" {
+		kept := []services.StaffUtilisation{}
+		if st := rep.ForStaff(staffID); st != nil {
+			kept = append(kept, *st)
+		}
+		rep.Staff = kept
+	}
+
+	if asCSV {
+		s.writeCSVFile(w, fmt.Sprintf("utilisation_%s_%s_%s.csv", branchID, p.From.Format("20060102"), p.To.Format("20060102")),
+			func(out io.Writer) error { return reports.WriteUtilisationCSV(out, rep) })
+		return
+	}
+	writeJSON(w, r, http.StatusOK, map[string]any{
+		"branch_id": branchID,
+		"from":      p.From.Format("2006-01-02"),
+		"to":        p.To.Format("2006-01-02"),
+		"data":      rep,
+	})
+}
+
+// handleServices lists the synced service catalogue. Filter with branch_id,
+// service_id and category_name; archived services need include_archived=1.
+func (s *Server) handleServices(w http.ResponseWriter, r *http.Request) {
+	v := r.URL.Query()
+	q, ok := scopeBranches(w, r, s.db.WithContext(r.Context()).Model(&models.PhService{}), "branch_id")
+	if !ok {
+		return
+	}
+	q = applyEq(q, v, map[string]string{
+		"branch_id":     "branch_id",
+		"service_id":    "id",
+		"category_name": "category_name",
+	})
+	if !boolParam(v, "include_archived") {
+		q = q.Where("archived = false")
+	}
+
+	var rows []models.PhService
+	pg, total, ok := s.list(w, r, q, "category_name, name, branch_id", &rows)
+	if !ok {
+		return
+	}
+	out := make([]serviceDTO, 0, len(rows))
+	for _, sv := range rows {
+		out = append(out, toServiceDTO(sv))
+	}
+	writeList(w, r, out, pg, total)
+}
+
+// handlePriceRealisation reports realised service revenue against list
+// price per stylist and service (?branch_id&from&to, optional staff_id,
+// ?format=csv).
+func (s *Server) handlePriceRealisation(w http.ResponseWriter, r *http.Request) {
+	branchID, p, ok := branchPeriod(w, r)
+	if !ok {
+		return
+	}
+	asCSV, ok := wantCSV(w, r)
+	if !ok {
+		return
+	}
+
+	rep, err := s.pricing.PriceRealisation(r.Context(), branchID, p)
+	if err != nil {
+		s.lg.Printf("❌ api price realisation: %v", err)
+		writeError(w, http.StatusInternalServerError, "query failed")
+		return
+	}
+	if staffID := r.URL.Query().Get("staff_id"); staffID != "" {
+		kept := []services.StaffRealisation{}
+		if st := rep.ForStaff(staffID); st != nil {
+			kept = append(kept, *st)
+		}
+		rep.Staff = kept
+	}
+
+	if asCSV {
+		s.writeCSVFile(w, fmt.Sprintf("price_realisation_%s_%s_%s.csv", branchID, p.From.Format("20060102"), p.To.Format("20060102")),
+			func(out io.Writer) error { return reports.WritePriceRealisationCSV(out, rep) })
+		return
+	}
+	writeJSON(w, r, http.StatusOK, map[string]any{
+		"branch_id": branchID,
+		"from":      p.From.Format("2006-01-02"),
+		"to":        p.To.Format("2006-01-02"),
+		"data":      rep,
+	})
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+// pagination is zero-based like the Phorest API (page=0 is the first page).
+type pagination struct {
+	Page int
+	Size int
+}
+
+func parsePagination(q url.Values) (pagination, error) {
+	pg := pagination{Page: 0, Size: defaultPageSize}
+
+	if v := q.Get("page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return pg, fmt.Errorf("invalid page %q", v)
+		}
+		pg.Page = n
+	}
+	if v := q.Get("size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return pg, fmt.Errorf("invalid size %q", v)
+		}
+		if n > maxPageSize {
+			n = maxPageSize
+		}
+		pg.Size = n
+	}
+	return pg, nil
+}
+
+func (pg pagination) apply(q *gorm.DB) *gorm.DB {
+	return q.Offset(pg.Page * pg.Size).Limit(pg.Size)
+}
+
+// dateRange is an optional inclusive [from, to] date filter.
+type dateRange struct {
+	From *time.Time
+	To   *time.Time
+}
+
+func parseDateRange(q url.Values) (dateRange, error) {
+	var dr dateRange
+	for _, f := range []struct {
+		name string
+		dst  **time.Time
+	}{{"from", &dr.From}, {"to", &dr.To}} {
+		v := q.Get(f.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return dr, fmt.Errorf("invalid %s %q (want YYYY-MM-DD)", f.name, v)
+		}
+		*f.dst = &t
+	}
+	if dr.From != nil && dr.To != nil && dr.To.Before(*dr.From) {
+		return dr, fmt.Errorf("to is before from")
+	}
+	return dr, nil
+}
+
+// apply adds the range to a date column (e.g. "purchased_date").
+func (dr dateRange) apply(q *gorm.DB, column string) *gorm.DB {
+	if dr.From != nil {
+		q = q.Where(column+" >= ?", *dr.From)
+	}
+	if dr.To != nil {
+		q = q.Where(column+" <= ?", *dr.To)
+	}
+	return q
+}
+
+// applyEq adds "column = ?" for each non-empty query parameter in params (param name → column).
+func applyEq(q *gorm.DB, values url.Values, params map[string]string) *gorm.DB {
+	for param, column := range params {
+		if v := strings.TrimSpace(values.Get(param)); v != "" {
+			q = q.Where(column+" = ?", v)
+		}
+	}
+	return q
+}
+
+func boolParam(q url.Values, name string) bool {
+	switch strings.ToLower(q.Get(name)) {
+	case "1", "true", "yes", "y":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// pageInfo mirrors the Phorest "page" block so dashboard code can page both the same way.
+type pageInfo struct {
+	Size          int   `json:"size"`
+	TotalElements int64 `json:"totalElements"`
+	TotalPages    int   `json:"totalPages"`
+	Number        int   `json:"number"`
+}
+
+type listResponse struct {
+	Data any      `json:"data"`
+	Page pageInfo `json:"page"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// writeJSON encodes v, sets a strong ETag over the body and honours If-None-Match.
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, v any) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "encode response")
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, no-cache")
+
+	if status == http.StatusOK && etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+func writeList(w http.ResponseWriter, r *http.Request, data any, pg pagination, total int64) {
+	pages := 0
+	if pg.Size > 0 {
+		pages = int((total + int64(pg.Size) - 1) / int64(pg.Size))
+	}
+	writeJSON(w, r, http.StatusOK, listResponse{
+		Data: data,
+		Page: pageInfo{
+			Size:          pg.Size,
+			TotalElements: total,
+			TotalPages:    pages,
+			Number:        pg.Page,
+		},
+	})
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	body, _ := json.Marshal(errorResponse{Error: msg})
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		part = strings.TrimPrefix(part, "W/")
+		if part == "*" || part == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/araquach/phorest-datahub/internal/config"
+	"github.com/araquach/phorest-datahub/internal/phorest"
+	"github.com/araquach/phorest-datahub/internal/reports"
+	"github.com/araquach/phorest-datahub/internal/repos"
+	"github.com/araquach/phorest-datahub/internal/services"
+	"gorm.io/gorm"
+)
+
+// Server is the JSON API over the datahub tables, plus admin sync control.
+type Server struct {
+	db    *gorm.DB
+	cfg   *config.Config
+	lg    *log.Logger
+	kpis  *services.KPIService
+	keys  *repos.APIKeysRepo
+	syncs *phorest.RunManager
+
+	insights  *services.ReviewInsightsService
+	trends    *services.RatingTrendsService
+	reorder   *reports.ReorderBuilder
+	stock     *services.StockService
+	shrinkage *repos.StockShrinkageRepo
+	util      *services.UtilisationService
+	pricing   *services.PriceRealisationService
+}
+
+func NewServer(db *gorm.DB, cfg *config.Config, lg *log.Logger) (*Server, error) {
+	runner, err := phorest.NewRunner(db, cfg, lg)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{
+		db:    db,
+		cfg:   cfg,
+		lg:    lg,
+		kpis:  services.NewKPIService(db, lg),
+		keys:  repos.NewAPIKeysRepo(db, lg),
+		syncs: phorest.NewRunManager(runner),
+
+		insights:  services.NewReviewInsightsService(db, lg),
+		trends:    services.NewRatingTrendsService(db, lg),
+		reorder:   reports.NewReorderBuilder(db, lg),
+		stock:     services.NewStockService(db, lg),
+		shrinkage: repos.NewStockShrinkageRepo(db, lg),
+		util:      services.NewUtilisationService(db, lg),
+		pricing:   services.NewPriceRealisationService(db, lg),
+	}, nil
+}
+
+// Handler wires up all routes. Everything except /healthz needs an API key,
+// and every request is written to api_audit_log.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /healthz", s.handleHealth)
+
+	authed := func(pattern string, h http.HandlerFunc) {
+		mux.Handle(pattern, s.requireKey(h))
+	}
+
+	authed("GET /api/branches", s.handleBranches)
+	authed("GET /api/staff", s.handleStaff)
+	authed("GET /api/clients", s.handleClients)
+	authed("GET /api/clients/{clientID}", s.handleClient)
+	authed("GET /api/transactions", s.handleTransactions)
+	authed("GET /api/transaction-items", s.handleTransactionItems)
+	authed("GET /api/reviews", s.handleReviews)
+	authed("GET /api/reviews/insights", requireScope(ScopeReadKPIs, s.handleReviewInsights))
+	authed("GET /api/reviews/trends", requireScope(ScopeReadKPIs, s.handleRatingTrends))
+	authed("GET /api/alerts", requireScope(ScopeReadKPIs, s.handleAlerts))
+	authed("GET /api/products/stock", s.handleProductStock)
+	authed("GET /api/products/stock/history", s.handleProductStockHistory)
+	authed("GET /api/products/changes", s.handleProductChanges)
+	authed("GET /api/products/reorder", s.handleReorder)
+	authed("GET /api/products/valuation", requireScope(ScopeReadKPIs, s.handleStockValuation))
+	authed("GET /api/products/movements", s.handleStockMovements)
+	authed("GET /api/products/dead-stock", requireScope(ScopeReadKPIs, s.handleDeadStock))
+	authed("GET /api/products/shrinkage", requireScope(ScopeReadKPIs, s.handleShrinkage))
+	authed("POST /api/products/shrinkage", requireScope(ScopeAdminSync, s.handleRecomputeShrinkage))
+	authed("GET /api/products/backbar", requireScope(ScopeReadKPIs, s.handleBackBar))
+	authed("GET /api/services", s.handleServices)
+	authed("GET /api/services/price-realisation", requireScope(ScopeReadKPIs, s.handlePriceRealisation))
+	authed("GET /api/kpis", requireScope(ScopeReadKPIs, s.handleKPIs))
+	authed("GET /api/staff/utilisation", requireScope(ScopeReadKPIs, s.handleUtilisation))
+
+	authed("POST /api/admin/syncs", requireScope(ScopeAdminSync, s.handleStartSync))
+	authed("GET /api/admin/syncs", requireScope(ScopeAdminSync, s.handleListSyncs))
+	authed("GET /api/admin/syncs/{id}", requireScope(ScopeAdminSync, s.handleGetSync))
+	authed("GET /api/admin/syncs/{id}/logs", requireScope(ScopeAdminSync, s.handleSyncLogs))
+	authed("DELETE /api/admin/syncs/{id}", requireScope(ScopeAdminSync, s.handleCancelSync))
+	authed("GET /api/admin/notifications", requireScope(ScopeAdminSync, s.handleNotifications))
+
+	return s.audit(mux)
+}
+
+// HTTPServer returns an *http.Server with sane timeouts for the configured address.
+func (s *Server) HTTPServer() *http.Server {
+	return &http.Server{
+		Addr:              s.cfg.APIAddr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      60 * time.Second,
+		IdleTimeout:       120 * time.Second,
+	}
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	sqlDB, err := s.db.DB()
+	if err == nil {
+		err = sqlDB.PingContext(r.Context())
+	}
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, "database unavailable")
+		return
+	}
+	writeJSON(w, r, http.StatusOK, map[string]string{"status": "ok"})
+}
@@ -3,6 +3,9 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/araquach/phorest-datahub/internal/util"
 )
@@ -13,6 +16,15 @@ type BranchConfig struct {
 	BranchID string
 }
 
+// OverlapPolicy controls how far an incremental sync looks back before its
+// watermark, to catch Phorest edits (voids, corrections) that arrive late with
+// an older "updated" value. Upserts are idempotent, so re-pulled rows are cheap.
+type OverlapPolicy struct {
+	Overlap     time.Duration // re-pull this much before the watermark on every run
+	SweepWindow time.Duration // wider look-back used by the periodic sweep (0 = no sweep)
+	SweepEvery  time.Duration // minimum time between sweeps
+}
+
 // Config centralises all environment and runtime configuration.
 type Config struct {
 	Logger          *log.Logger
@@ -25,6 +37,43 @@ type Config struct {
 	ExportDir string
 
 	AutoMigrate bool
+
+	// Max branches synced in parallel by the Runner (SYNC_CONCURRENCY, default 3)
+	SyncConcurrency int
+
+	// Look-back per watermark entity (SYNC_OVERLAP_<ENTITY>, SYNC_SWEEP_WINDOW_<ENTITY>,
+	// SYNC_SWEEP_EVERY_<ENTITY>; durations like "72h" or "3d")
+	Overlaps map[string]OverlapPolicy
+
+	// Read-only HTTP API (cmd/appraisals-api)
+	APIAddr string
+
+	// Notifications: JSON file with channels and subscriptions (NOTIFY_CONFIG;
+	// unset = log only), how old a watermark may get before data counts as
+	// stale (NOTIFY_STALE_AFTER, default 3d) and how far ahead of a target
+	// period's end an appraisal is flagged as due (NOTIFY_APPRAISAL_LEAD, default 14d).
+	NotifyConfig     string
+	StaleAfter       time.Duration
+	AppraisalDueLead time.Duration
+
+	// Publish each branch's stock reorder list after a products sync
+	// (STOCK_REORDER_NOTIFY=1).
+	ReorderNotify bool
+
+	// How often an incremental products sync turns into a full reconcile
+	// that marks products Phorest no longer lists as removed
+	// (PRODUCTS_RECONCILE_EVERY, default 7d; 0 = only when asked for).
+	ProductsReconcileEvery time.Duration
+
+	// Appointments sync window: how far back the first sync of a branch
+	// reaches (APPOINTMENTS_BACKFILL, default 365d) and how far ahead of
+	// today every sync reads future bookings (APPOINTMENTS_LOOKAHEAD, default 90d).
+	AppointmentsBackfill  time.Duration
+	AppointmentsLookahead time.Duration
+
+	// How far ahead of today the roster sync reads staff timetables, breaks
+	// and time off (ROSTER_LOOKAHEAD, default 28d).
+	RosterLookahead time.Duration
 }
 
 // Load builds the Config struct, validating critical env vars.
@@ -40,6 +89,26 @@ func Load() *Config {
 		PhorestBusiness: getEnvOrFail(logger, "PHOREST_BUSINESS"),
 		AutoMigrate:     os.Getenv("AUTO_MIGRATE") == "1",
 		ExportDir:       getEnvOrDefault("EXPORT_DIR", "data/exports"),
+		APIAddr:         getEnvOrDefault("API_ADDR", ":8080"),
+		SyncConcurrency: getEnvIntOrDefault(logger, "SYNC_CONCURRENCY", 3),
+
+		NotifyConfig:     os.Getenv("NOTIFY_CONFIG"),
+		StaleAfter:       getEnvDurationOrDefault(logger, "NOTIFY_STALE_AFTER", 3*day),
+		AppraisalDueLead: getEnvDurationOrDefault(logger, "NOTIFY_APPRAISAL_LEAD", 14*day),
+		ReorderNotify:    os.Getenv("STOCK_REORDER_NOTIFY") == "1",
+
+		ProductsReconcileEvery: getEnvDurationOrDefault(logger, "PRODUCTS_RECONCILE_EVERY", 7*day),
+		AppointmentsBackfill:   getEnvDurationOrDefault(logger, "APPOINTMENTS_BACKFILL", 365*day),
+		AppointmentsLookahead:  getEnvDurationOrDefault(logger, "APPOINTMENTS_LOOKAHEAD", 90*day),
+		RosterLookahead:        getEnvDurationOrDefault(logger, "ROSTER_LOOKAHEAD", 28*day),
+		Overlaps: map[string]OverlapPolicy{
+			"transactions_csv": loadOverlap(logger, "transactions_csv", OverlapPolicy{3 * day, 60 * day, 7 * day}),
+			"clients_csv":      loadOverlap(logger, "clients_csv", OverlapPolicy{1 * day, 30 * day, 7 * day}),
+			"products_api":     loadOverlap(logger, "products_api", OverlapPolicy{1 * day, 0, 0}),
+			"reviews_api":      loadOverlap(logger, "reviews_api", OverlapPolicy{3 * day, 0, 0}),
+			"appointments_api": loadOverlap(logger, "appointments_api", OverlapPolicy{7 * day, 90 * day, 7 * day}),
+			"roster_api":       loadOverlap(logger, "roster_api", OverlapPolicy{14 * day, 0, 0}),
+		},
 		Branches: []BranchConfig{
 			{
 				Name:     getEnvOrDefault("SITE_1_NAME", "Jakata"),
@@ -58,9 +127,26 @@ func Load() *Config {
 
 	logger.Printf("✅ Loaded config for %d branches\n", len(cfg.Branches))
 	logger.Printf("📁 ExportDir: %s", cfg.ExportDir)
+	logger.Printf("🔀 Sync concurrency: %d branch(es) at a time", cfg.SyncConcurrency)
 	return cfg
 }
 
+// OverlapFor returns the look-back policy for a watermark entity (zero = none).
+func (c *Config) OverlapFor(entity string) OverlapPolicy {
+	return c.Overlaps[entity]
+}
+
+const day = 24 * time.Hour
+
+func loadOverlap(logger *log.Logger, entity string, def OverlapPolicy) OverlapPolicy {
+	key := strings.ToUpper(entity)
+	return OverlapPolicy{
+		Overlap:     getEnvDurationOrDefault(logger, "SYNC_OVERLAP_"+key, def.Overlap),
+		SweepWindow: getEnvDurationOrDefault(logger, "SYNC_SWEEP_WINDOW_"+key, def.SweepWindow),
+		SweepEvery:  getEnvDurationOrDefault(logger, "SYNC_SWEEP_EVERY_"+key, def.SweepEvery),
+	}
+}
+
 func getEnvOrFail(logger *log.Logger, key string) string {
 	val := os.Getenv(key)
 	if val == "" {
@@ -76,3 +162,33 @@ func getEnvOrDefault(key, def string) string {
 	}
 	return val
 }
+
+func getEnvIntOrDefault(logger *log.Logger, key string, def int) int {
+	val := os.Getenv(key)
+	if val == "" {
+		return def
+	}
+	n, err := strconv.Atoi(val)
+	if err != nil || n < 1 {
+		logger.Fatalf("❌ Environment variable %s must be a positive integer, got %q", key, val)
+	}
+	return n
+}
+
+// getEnvDurationOrDefault accepts Go durations ("36h") and whole days ("3d").
+func getEnvDurationOrDefault(logger *log.Logger, key string, def time.Duration) time.Duration {
+	val := os.Getenv(key)
+	if val == "" {
+		return def
+	}
+	if days, ok := strings.CutSuffix(val, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return time.Duration(n) * day
+		}
+	}
+	d, err := time.ParseDuration(val)
+	if err != nil || d < 0 {
+		logger.Fatalf("❌ Environment variable %s must be a duration like 72h or 3d, got %q", key, val)
+	}
+	return d
+}
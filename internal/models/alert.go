@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Alert severities.
+const (
+	AlertWarning  = "warning"
+	AlertCritical = "critical"
+)
+
+// Alert is something a manager should look at before appraisal time.
+type Alert struct {
+	ID         int64      `gorm:"column:id;primaryKey;autoIncrement"`
+	Kind       string     `gorm:"column:kind;not null"`
+	Severity   string     `gorm:"column:severity;not null"`
+	BranchID   string     `gorm:"column:branch_id;not null"`
+	StaffID    string     `gorm:"column:staff_id;not null"` // "" for branch-level alerts
+	Title      string     `gorm:"column:title;not null"`
+	Detail     string     `gorm:"column:detail"`
+	Value      *float64   `gorm:"column:value"`
+	Baseline   *float64   `gorm:"column:baseline"`
+	RaisedAt   time.Time  `gorm:"column:raised_at"`
+	LastSeenAt time.Time  `gorm:"column:last_seen_at"`
+	NotifiedAt *time.Time `gorm:"column:notified_at"`
+	ResolvedAt *time.Time `gorm:"column:resolved_at"`
+}
+
+func (Alert) TableName() string { return "alerts" }
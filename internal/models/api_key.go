@@ -0,0 +1,55 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// APIKey is a hashed API credential with scopes and an optional branch restriction.
+type APIKey struct {
+	ID         int64      `gorm:"column:id;primaryKey;autoIncrement"`
+	Name       string     `gorm:"column:name;not null"`
+	KeyPrefix  string     `gorm:"column:key_prefix;not null;uniqueIndex"`
+	KeyHash    string     `gorm:"column:key_hash;not null"`
+	Scopes     string     `gorm:"column:scopes"`     // comma-separated
+	BranchIDs  string     `gorm:"column:branch_ids"` // comma-separated; "" = all branches
+	ExpiresAt  *time.Time `gorm:"column:expires_at"`
+	RevokedAt  *time.Time `gorm:"column:revoked_at"`
+	LastUsedAt *time.Time `gorm:"column:last_used_at"`
+	CreatedAt  time.Time  `gorm:"column:created_at"`
+	UpdatedAt  time.Time  `gorm:"column:updated_at"`
+}
+
+func (APIKey) TableName() string { return "api_keys" }
+
+// ScopeList splits the stored scopes.
+func (k APIKey) ScopeList() []string { return splitCSV(k.Scopes) }
+
+// BranchList splits the stored branch restriction (empty = unrestricted).
+func (k APIKey) BranchList() []string { return splitCSV(k.BranchIDs) }
+
+// APIAuditLog records one API request.
+type APIAuditLog struct {
+	ID         int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	APIKeyID   *int64    `gorm:"column:api_key_id"`
+	KeyPrefix  string    `gorm:"column:key_prefix"`
+	Method     string    `gorm:"column:method"`
+	Path       string    `gorm:"column:path"`
+	Query      string    `gorm:"column:query"`
+	Status     int       `gorm:"column:status"`
+	DurationMS int64     `gorm:"column:duration_ms"`
+	RemoteAddr string    `gorm:"column:remote_addr"`
+	CreatedAt  time.Time `gorm:"column:created_at"`
+}
+
+func (APIAuditLog) TableName() string { return "api_audit_log" }
+
+func splitCSV(s string) []string {
+	var out []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
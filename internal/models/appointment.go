@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// Appointment is one booked service from the Phorest appointment API.
+// StartAt/EndAt hold the branch's wall-clock time (UTC location, no zone).
+type Appointment struct {
+	AppointmentID   string    `gorm:"column:appointment_id;primaryKey"`
+	BranchID        string    `gorm:"column:branch_id;not null"`
+	Version         int64     `gorm:"column:version"`
+	AppointmentDate time.Time `gorm:"column:appointment_date;type:date"`
+	StartAt         time.Time `gorm:"column:start_at"`
+	EndAt           time.Time `gorm:"column:end_at"`
+
+	ClientID    string `gorm:"column:client_id"`
+	StaffID     string `gorm:"column:staff_id"`
+	ServiceID   string `gorm:"column:service_id"`
+	ServiceName string `gorm:"column:service_name"`
+	RoomID      string `gorm:"column:room_id"`
+	BookingID   string `gorm:"column:booking_id"`
+
+	Price         *float64 `gorm:"column:price"`
+	DepositAmount *float64 `gorm:"column:deposit_amount"`
+
+	State           string `gorm:"column:state"`
+	ActivationState string `gorm:"column:activation_state"`
+	Source          string `gorm:"column:source"`
+	BookedOnline    bool   `gorm:"column:booked_online"`
+	StaffRequest    bool   `gorm:"column:staff_request"`
+	Confirmed       bool   `gorm:"column:confirmed"`
+	Cancelled       bool   `gorm:"column:cancelled"`
+	NoShow          bool   `gorm:"column:no_show"`
+
+	CreatedAtPhorest *time.Time `gorm:"column:created_at_phorest"`
+	UpdatedAtPhorest *time.Time `gorm:"column:updated_at_phorest"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (Appointment) TableName() string { return "appointments" }
+
+// Minutes is the appointment's booked length.
+func (a Appointment) Minutes() float64 {
+	return a.EndAt.Sub(a.StartAt).Minutes()
+}
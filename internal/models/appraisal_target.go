@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// AppraisalTarget is a manager-set goal for one stylist, one metric and one period.
+// Metric names match the keys exposed by services.StaffKPIs.Metric.
+type AppraisalTarget struct {
+	ID          int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	StaffID     string    `gorm:"column:staff_id;not null"`
+	BranchID    string    `gorm:"column:branch_id;not null"`
+	Metric      string    `gorm:"column:metric;not null"`
+	PeriodStart time.Time `gorm:"column:period_start;type:date"`
+	PeriodEnd   time.Time `gorm:"column:period_end;type:date"`
+	TargetValue float64   `gorm:"column:target_value"`
+	CreatedAt   time.Time `gorm:"column:created_at"`
+	UpdatedAt   time.Time `gorm:"column:updated_at"`
+}
+
+func (AppraisalTarget) TableName() string { return "appraisal_targets" }
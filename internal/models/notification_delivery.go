@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Notification delivery statuses.
+const (
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// NotificationDelivery is one event sent (or given up on) to one channel.
+type NotificationDelivery struct {
+	ID          int64      `gorm:"column:id;primaryKey;autoIncrement"`
+	EventType   string     `gorm:"column:event_type;not null"`
+	EventKey    string     `gorm:"column:event_key;not null"`
+	Channel     string     `gorm:"column:channel;not null"`
+	Severity    string     `gorm:"column:severity;not null"`
+	BranchID    string     `gorm:"column:branch_id;not null"`
+	StaffID     string     `gorm:"column:staff_id;not null"`
+	Title       string     `gorm:"column:title;not null"`
+	Payload     string     `gorm:"column:payload;type:jsonb"` // the event as JSON
+	Status      string     `gorm:"column:status;not null"`
+	Attempts    int        `gorm:"column:attempts"`
+	LastError   *string    `gorm:"column:last_error"`
+	CreatedAt   time.Time  `gorm:"column:created_at"`
+	DeliveredAt *time.Time `gorm:"column:delivered_at"`
+}
+
+func (NotificationDelivery) TableName() string { return "notification_deliveries" }
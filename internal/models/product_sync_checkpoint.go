@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// ProductSyncCheckpoint is how far a branch's products sync has committed.
+type ProductSyncCheckpoint struct {
+	BranchID      string     `gorm:"column:branch_id;primaryKey"`
+	ProductType   string     `gorm:"column:product_type"`
+	Reconcile     bool       `gorm:"column:reconcile"`
+	UpdatedAfter  *time.Time `gorm:"column:updated_after"`
+	UpdatedBefore *time.Time `gorm:"column:updated_before"`
+	NextPage      int        `gorm:"column:next_page"`
+	TotalPages    int        `gorm:"column:total_pages"`
+	Processed     int        `gorm:"column:processed"`
+	MaxUpdatedAt  *time.Time `gorm:"column:max_updated_at"`
+	RunID         *int64     `gorm:"column:run_id"`
+	StartedAt     time.Time  `gorm:"column:started_at"`
+	UpdatedAt     time.Time  `gorm:"column:updated_at"`
+}
+
+func (ProductSyncCheckpoint) TableName() string { return "product_sync_checkpoints" }
@@ -19,8 +19,26 @@ type Review struct {
 	FacebookReview  bool       `gorm:"column:facebook_review"`
 	TwitterReview   bool       `gorm:"column:twitter_review"`
 
+	ContentHash string     `gorm:"column:content_hash"` // sha256 of the fields Phorest can edit
+	DeletedAt   *time.Time `gorm:"column:deleted_at"`   // set when Phorest stops returning the review
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
 
 func (Review) TableName() string { return "reviews" }
+
+// ReviewChange records one field of a review changing between syncs
+// (Field "deleted" when reconcile marks it removed or it reappears).
+type ReviewChange struct {
+	ID        int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	ReviewID  string    `gorm:"column:review_id;not null"`
+	BranchID  string    `gorm:"column:branch_id;not null"`
+	Field     string    `gorm:"column:field;not null"`
+	OldValue  *string   `gorm:"column:old_value"`
+	NewValue  *string   `gorm:"column:new_value"`
+	RunID     *int64    `gorm:"column:run_id"`
+	ChangedAt time.Time `gorm:"column:changed_at"`
+}
+
+func (ReviewChange) TableName() string { return "review_changes" }
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// ReviewAnalysis is the offline sentiment score for one review.
+type ReviewAnalysis struct {
+	ReviewID        string    `gorm:"column:review_id;primaryKey"`
+	Sentiment       float64   `gorm:"column:sentiment"`
+	Label           string    `gorm:"column:label"`
+	ContentHash     string    `gorm:"column:content_hash"`
+	AnalyzerVersion string    `gorm:"column:analyzer_version"`
+	AnalysedAt      time.Time `gorm:"column:analysed_at"`
+}
+
+func (ReviewAnalysis) TableName() string { return "review_analysis" }
+
+// ReviewTopic is one topic a review mentions.
+type ReviewTopic struct {
+	ReviewID  string  `gorm:"column:review_id;primaryKey"`
+	Topic     string  `gorm:"column:topic;primaryKey"`
+	Mentions  int     `gorm:"column:mentions"`
+	Sentiment float64 `gorm:"column:sentiment"`
+}
+
+func (ReviewTopic) TableName() string { return "review_topics" }
+
+// ReviewSnippet is a quotable praise/complaint sentence from a review.
+type ReviewSnippet struct {
+	ID       int64   `gorm:"column:id;primaryKey;autoIncrement"`
+	ReviewID string  `gorm:"column:review_id"`
+	Kind     string  `gorm:"column:kind"`
+	Topic    string  `gorm:"column:topic"`
+	Text     string  `gorm:"column:text"`
+	Score    float64 `gorm:"column:score"`
+}
+
+func (ReviewSnippet) TableName() string { return "review_snippets" }
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// StaffWorkTimetable is one rostered working period. Times are the
+// branch's wall clock (UTC location, no zone), as in Appointment.
+type StaffWorkTimetable struct {
+	ID       int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	BranchID string    `gorm:"column:branch_id"`
+	StaffID  string    `gorm:"column:staff_id"`
+	WorkDate time.Time `gorm:"column:work_date;type:date"`
+	StartAt  time.Time `gorm:"column:start_at"`
+	EndAt    time.Time `gorm:"column:end_at"`
+	SyncedAt time.Time `gorm:"column:synced_at"`
+}
+
+func (StaffWorkTimetable) TableName() string { return "staff_work_timetables" }
+
+// StaffBreak is a break within a stylist's rostered day.
+type StaffBreak struct {
+	ID        int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	BranchID  string    `gorm:"column:branch_id"`
+	StaffID   string    `gorm:"column:staff_id"`
+	BreakDate time.Time `gorm:"column:break_date;type:date"`
+	StartAt   time.Time `gorm:"column:start_at"`
+	EndAt     time.Time `gorm:"column:end_at"`
+	Label     string    `gorm:"column:label"`
+	Paid      bool      `gorm:"column:paid"`
+	SyncedAt  time.Time `gorm:"column:synced_at"`
+}
+
+func (StaffBreak) TableName() string { return "staff_breaks" }
+
+// StaffTimeOff is an absence, possibly spanning several days.
+type StaffTimeOff struct {
+	ID       int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	BranchID string    `gorm:"column:branch_id"`
+	StaffID  string    `gorm:"column:staff_id"`
+	StartAt  time.Time `gorm:"column:start_at"`
+	EndAt    time.Time `gorm:"column:end_at"`
+	Reason   string    `gorm:"column:reason"`
+	SyncedAt time.Time `gorm:"column:synced_at"`
+}
+
+func (StaffTimeOff) TableName() string { return "staff_time_off" }
+
+// Roster is everything a roster sync fetched for one branch and window.
+type Roster struct {
+	Timetables []StaffWorkTimetable
+	Breaks     []StaffBreak
+	TimeOff    []StaffTimeOff
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// PhService is one branch's catalogue entry for a service.
+type PhService struct {
+	ID              string     `gorm:"column:id;primaryKey"` // serviceId
+	BranchID        string     `gorm:"column:branch_id;primaryKey"`
+	Name            string     `gorm:"column:name"`
+	CategoryID      *string    `gorm:"column:category_id"`
+	CategoryName    *string    `gorm:"column:category_name"`
+	DurationMinutes *int       `gorm:"column:duration_minutes"`
+	Price           *float64   `gorm:"column:price"` // list price
+	Archived        bool       `gorm:"column:archived"`
+	CreatedAtPh     *time.Time `gorm:"column:created_at_ph"`
+	UpdatedAtPh     *time.Time `gorm:"column:updated_at_ph"`
+	LastSyncedAt    time.Time  `gorm:"column:last_synced_at"`
+}
+
+func (PhService) TableName() string {
+	return "ph_services"
+}
+
+// PhServiceHistory is a catalogue entry as it was from SnapshotTime on.
+type PhServiceHistory struct {
+	ID              int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	ServiceID       string    `gorm:"column:service_id;not null"`
+	BranchID        string    `gorm:"column:branch_id;not null"`
+	SnapshotTime    time.Time `gorm:"column:snapshot_time"`
+	Name            string    `gorm:"column:name"`
+	CategoryName    *string   `gorm:"column:category_name"`
+	DurationMinutes *int      `gorm:"column:duration_minutes"`
+	Price           *float64  `gorm:"column:price"`
+	Archived        bool      `gorm:"column:archived"`
+	RunID           *int64    `gorm:"column:run_id"`
+}
+
+func (PhServiceHistory) TableName() string {
+	return "ph_service_history"
+}
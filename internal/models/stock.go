@@ -39,6 +39,7 @@ type PhProductStock struct {
 	CreatedAtPh     *time.Time `gorm:"column:created_at_ph"`
 	UpdatedAtPh     *time.Time `gorm:"column:updated_at_ph"`
 	LastSyncedAt    time.Time  `gorm:"column:last_synced_at"`
+	RemovedAt       *time.Time `gorm:"column:removed_at"` // no longer listed by Phorest for this branch
 
 	// Optional: relation to product if you want eager loading
 	Product *PhProduct `gorm:"foreignKey:ProductID;references:ID"`
@@ -61,3 +62,25 @@ type PhProductStockHistory struct {
 func (PhProductStockHistory) TableName() string {
 	return "ph_product_stock_history"
 }
+
+// ProductChange entities.
+const (
+	ProductChangeProduct = "product" // ph_products
+	ProductChangeStock   = "stock"   // ph_product_stock
+)
+
+// ProductChange records one field of a product or branch stock row changing
+// between syncs.
+type ProductChange struct {
+	ID        int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	Entity    string    `gorm:"column:entity;not null"`
+	ProductID string    `gorm:"column:product_id;not null"`
+	BranchID  string    `gorm:"column:branch_id;not null"`
+	Field     string    `gorm:"column:field;not null"`
+	OldValue  *string   `gorm:"column:old_value"`
+	NewValue  *string   `gorm:"column:new_value"`
+	RunID     *int64    `gorm:"column:run_id"`
+	ChangedAt time.Time `gorm:"column:changed_at"`
+}
+
+func (ProductChange) TableName() string { return "product_changes" }
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Stock usage classes for shrinkage attribution.
+const (
+	UsageRetail       = "retail"
+	UsageProfessional = "professional" // back-bar: PROFESSIONAL or COLOUR products
+)
+
+// StockShrinkage reconciles one product's stock decreases in a branch over a
+// period against its retail sales.
+type StockShrinkage struct {
+	BranchID         string    `gorm:"column:branch_id;primaryKey" json:"branch_id"`
+	ProductID        string    `gorm:"column:product_id;primaryKey" json:"product_id"`
+	PeriodStart      time.Time `gorm:"column:period_start;primaryKey" json:"period_start"`
+	PeriodEnd        time.Time `gorm:"column:period_end;primaryKey" json:"period_end"`
+	ProductName      string    `gorm:"column:product_name" json:"product_name"`
+	BrandName        string    `gorm:"column:brand_name" json:"brand_name"`
+	Usage            string    `gorm:"column:usage" json:"usage"`
+	Received         float64   `gorm:"column:received" json:"received"`
+	Removed          float64   `gorm:"column:removed" json:"removed"`
+	Sold             float64   `gorm:"column:sold" json:"sold"`
+	ProfessionalUse  float64   `gorm:"column:professional_use" json:"professional_use"`
+	Shrinkage        float64   `gorm:"column:shrinkage" json:"shrinkage"`
+	UnmatchedSales   float64   `gorm:"column:unmatched_sales" json:"unmatched_sales"`
+	UnitCost         *float64  `gorm:"column:unit_cost" json:"unit_cost"`
+	ProfessionalCost float64   `gorm:"column:professional_cost" json:"professional_cost"`
+	ShrinkageCost    float64   `gorm:"column:shrinkage_cost" json:"shrinkage_cost"`
+	Flagged          bool      `gorm:"column:flagged" json:"flagged"`
+	ComputedAt       time.Time `gorm:"column:computed_at" json:"computed_at"`
+}
+
+func (StockShrinkage) TableName() string { return "stock_shrinkage" }
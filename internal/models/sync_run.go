@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Sync run statuses.
+const (
+	SyncRunRunning   = "running"
+	SyncRunSucceeded = "succeeded"
+	SyncRunPartial   = "partial" // some branches/rows failed, the rest were saved
+	SyncRunFailed    = "failed"
+	SyncRunCancelled = "cancelled"
+)
+
+// SyncRun records one Runner sync invocation and its outcome.
+type SyncRun struct {
+	ID          int64      `gorm:"column:id;primaryKey;autoIncrement"`
+	Kind        string     `gorm:"column:kind;not null"`
+	BranchID    string     `gorm:"column:branch_id;not null"` // "ALL" when not branch-scoped
+	WindowFrom  *time.Time `gorm:"column:window_from;type:date"`
+	WindowTo    *time.Time `gorm:"column:window_to;type:date"`
+	Strategy    string     `gorm:"column:strategy"` // reviews: incremental, full, latest, reconcile
+	TriggeredBy string     `gorm:"column:triggered_by"`
+	RequestedBy string     `gorm:"column:requested_by"`
+	Status      string     `gorm:"column:status;not null"`
+	Error       string     `gorm:"column:error"`
+	Log         string     `gorm:"column:log"`
+	StartedAt   time.Time  `gorm:"column:started_at"`
+	FinishedAt  *time.Time `gorm:"column:finished_at"`
+}
+
+func (SyncRun) TableName() string { return "sync_runs" }
@@ -0,0 +1,175 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/araquach/phorest-datahub/internal/repos"
+	"gorm.io/gorm"
+)
+
+// Config is the NOTIFY_CONFIG file. ${VAR} references in channel settings
+// are expanded from the environment after parsing, so secrets and webhook
+// URLs can stay out of it (a bare $ is left alone):
+//
+//	{
+//	  "channels": {
+//	    "ops":    {"type": "slack", "url": "${SLACK_WEBHOOK_URL}"},
+//	    "hooks":  {"type": "webhook", "url": "https://example.com/datahub", "secret": "${NOTIFY_WEBHOOK_SECRET}"},
+//	    "owners": {"type": "smtp", "addr": "smtp.example.com:587", "username": "datahub",
+//	               "password": "${SMTP_PASSWORD}", "from": "datahub@example.com", "to": ["owner@example.com"]},
+//	    "audit":  {"type": "file", "path": "data/notifications.jsonl"}
+//	  },
+//	  "subscriptions": [
+//	    {"channel": "ops", "events": ["sync.*", "data.stale", "csv.schema_drift"], "min_severity": "warning"},
+//	    {"channel": "owners", "events": ["review.*", "appraisal.due", "stock.below_minimum"]},
+//	    {"channel": "audit", "events": ["*"]}
+//	  ],
+//	  "max_attempts": 3,
+//	  "backoff": "2s"
+//	}
+type Config struct {
+	Channels      map[string]ChannelConfig `json:"channels"`
+	Subscriptions []Subscription           `json:"subscriptions"`
+	MaxAttempts   int                      `json:"max_attempts"`
+	Backoff       string                   `json:"backoff"`
+}
+
+// ChannelConfig configures one channel; which fields apply depends on Type
+// (webhook, slack, smtp, file or log).
+type ChannelConfig struct {
+	Type    string `json:"type"`
+	URL     string `json:"url"`
+	Secret  string `json:"secret"`
+	Timeout string `json:"timeout"`
+
+	Addr     string   `json:"addr"`
+	Username string   `json:"username"`
+	Password string   `json:"password"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+
+	Path string `json:"path"`
+}
+
+// Load builds the Dispatcher from the NOTIFY_CONFIG file at path. With no
+// path every event goes to the log only. Deliveries are recorded in db.
+func Load(db *gorm.DB, path string, lg *log.Logger) (*Dispatcher, error) {
+	deliveries := repos.NewNotificationDeliveriesRepo(db, lg)
+	if path == "" {
+		return NewDispatcher(
+			map[string]Notifier{"log": &LogNotifier{Logger: lg}},
+			[]Subscription{{Channel: "log"}},
+			deliveries, lg,
+		), nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read notify config: %w", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("parse notify config %s: %w", path, err)
+	}
+
+	channels := make(map[string]Notifier, len(cfg.Channels))
+	for name, cc := range cfg.Channels {
+		n, err := cc.expandEnv().build(lg)
+		if err != nil {
+			return nil, fmt.Errorf("notify channel %q: %w", name, err)
+		}
+		channels[name] = n
+	}
+	for i, sub := range cfg.Subscriptions {
+		if _, ok := channels[sub.Channel]; !ok {
+			return nil, fmt.Errorf("notify subscription %d: unknown channel %q", i, sub.Channel)
+		}
+		if sub.MinSeverity != "" && sub.MinSeverity != SeverityInfo &&
+			sub.MinSeverity != SeverityWarning && sub.MinSeverity != SeverityCritical {
+			return nil, fmt.Errorf("notify subscription %d: unknown min_severity %q", i, sub.MinSeverity)
+		}
+	}
+
+	d := NewDispatcher(channels, cfg.Subscriptions, deliveries, lg)
+	if cfg.MaxAttempts > 0 {
+		d.MaxAttempts = cfg.MaxAttempts
+	}
+	if cfg.Backoff != "" {
+		b, err := time.ParseDuration(cfg.Backoff)
+		if err != nil {
+			return nil, fmt.Errorf("notify backoff: %w", err)
+		}
+		d.Backoff = b
+	}
+	lg.Printf("🔔 Notifications: %d channel(s), %d subscription(s) from %s", len(channels), len(cfg.Subscriptions), path)
+	return d, nil
+}
+
+// envRef matches a ${VAR} reference.
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandEnv replaces ${VAR} references with their environment values
+// (empty when unset). Values are used as-is, so they may contain $, " or \.
+func expandEnv(s string) string {
+	return envRef.ReplaceAllStringFunc(s, func(ref string) string {
+		return os.Getenv(ref[2 : len(ref)-1])
+	})
+}
+
+// expandEnv returns cc with ${VAR} references expanded in every string field.
+func (cc ChannelConfig) expandEnv() ChannelConfig {
+	for _, f := range []*string{&cc.Type, &cc.URL, &cc.Secret, &cc.Timeout, &cc.Addr, &cc.Username, &cc.Password, &cc.From, &cc.Path} {
+		*f = expandEnv(*f)
+	}
+	to := make([]string, len(cc.To))
+	for i, addr := range cc.To {
+		to[i] = expandEnv(addr)
+	}
+	cc.To = to
+	return cc
+}
+
+func (cc ChannelConfig) build(lg *log.Logger) (Notifier, error) {
+	var timeout time.Duration
+	if cc.Timeout != "" {
+		t, err := time.ParseDuration(cc.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("timeout: %w", err)
+		}
+		timeout = t
+	}
+
+	switch cc.Type {
+	case "webhook":
+		if cc.URL == "" {
+			return nil, fmt.Errorf("webhook needs a url")
+		}
+		if cc.Secret == "" {
+			lg.Printf("⚠️  notify: webhook %s has no secret; requests will be unsigned", cc.URL)
+		}
+		return NewWebhookNotifier(cc.URL, cc.Secret, timeout), nil
+	case "slack":
+		if cc.URL == "" {
+			return nil, fmt.Errorf("slack needs a url")
+		}
+		return NewSlackNotifier(cc.URL, timeout), nil
+	case "smtp":
+		if cc.Addr == "" || cc.From == "" || len(cc.To) == 0 {
+			return nil, fmt.Errorf("smtp needs addr, from and to")
+		}
+		return &SMTPNotifier{Addr: cc.Addr, Username: cc.Username, Password: cc.Password, From: cc.From, To: cc.To}, nil
+	case "file":
+		if cc.Path == "" {
+			return nil, fmt.Errorf("file needs a path")
+		}
+		return &FileNotifier{Path: cc.Path}, nil
+	case "log":
+		return &LogNotifier{Logger: lg}, nil
+	}
+	return nil, fmt.Errorf("unknown type %q", cc.Type)
+}
@@ -0,0 +1,171 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/araquach/phorest-datahub/internal/models"
+	"github.com/araquach/phorest-datahub/internal/repos"
+)
+
+// Subscription routes events to a channel.
+type Subscription struct {
+	Channel     string   `json:"channel"`
+	Events      []string `json:"events"`       // exact types, "prefix.*" or "*"; empty = all
+	Branches    []string `json:"branches"`     // empty = all (business-wide events always match)
+	MinSeverity string   `json:"min_severity"` // "" = info
+}
+
+func (s Subscription) matches(ev Event) bool {
+	if severityRank(ev.Severity) < severityRank(s.MinSeverity) {
+		return false
+	}
+	if len(s.Branches) > 0 && ev.BranchID != "" && ev.BranchID != "ALL" &&
+		!slices.Contains(s.Branches, ev.BranchID) {
+		return false
+	}
+	if len(s.Events) == 0 {
+		return true
+	}
+	for _, p := range s.Events {
+		switch {
+		case p == "*", p == ev.Type:
+			return true
+		case strings.HasSuffix(p, ".*") && strings.HasPrefix(ev.Type, strings.TrimSuffix(p, "*")):
+			return true
+		}
+	}
+	return false
+}
+
+// Dispatcher fans events out to subscribed channels, retrying failed sends
+// with exponential backoff and logging every delivery. A nil *Dispatcher
+// drops events, so callers don't need to check.
+type Dispatcher struct {
+	channels map[string]Notifier
+	subs     []Subscription
+	log      *repos.NotificationDeliveriesRepo // nil = don't record
+	lg       *log.Logger
+
+	MaxAttempts int
+	Backoff     time.Duration // first retry delay; doubles each attempt
+}
+
+func NewDispatcher(channels map[string]Notifier, subs []Subscription, deliveries *repos.NotificationDeliveriesRepo, lg *log.Logger) *Dispatcher {
+	return &Dispatcher{
+		channels:    channels,
+		subs:        subs,
+		log:         deliveries,
+		lg:          lg,
+		MaxAttempts: 3,
+		Backoff:     2 * time.Second,
+	}
+}
+
+// Publish delivers ev to every channel subscribed to it (once per channel,
+// however many subscriptions match). It returns the failed deliveries, which
+// callers normally just log: a notification problem shouldn't fail a sync.
+func (d *Dispatcher) Publish(ctx context.Context, ev Event) error {
+	if d == nil {
+		return nil
+	}
+	if ev.OccurredAt.IsZero() {
+		ev.OccurredAt = time.Now().UTC()
+	}
+	if ev.Severity == "" {
+		ev.Severity = SeverityInfo
+	}
+
+	var errs []error
+	seen := map[string]bool{}
+	for _, sub := range d.subs {
+		if seen[sub.Channel] || !sub.matches(ev) {
+			continue
+		}
+		seen[sub.Channel] = true
+		if err := d.deliver(ctx, sub.Channel, ev); err != nil {
+			errs = append(errs, fmt.Errorf("%s → %s: %w", ev.Type, sub.Channel, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, channel string, ev Event) error {
+	n, ok := d.channels[channel]
+	if !ok {
+		return fmt.Errorf("unknown channel %q", channel)
+	}
+
+	if ev.Key != "" && d.log != nil {
+		done, err := d.log.Delivered(channel, ev.Key)
+		if err != nil {
+			d.lg.Printf("⚠️  notify: check earlier deliveries of %s: %v", ev.Key, err)
+		} else if done {
+			return nil
+		}
+	}
+
+	attempts, err := d.sendWithRetry(ctx, n, ev)
+	d.record(channel, ev, attempts, err)
+	return err
+}
+
+func (d *Dispatcher) sendWithRetry(ctx context.Context, n Notifier, ev Event) (int, error) {
+	maxAttempts := max(d.MaxAttempts, 1)
+	delay := d.Backoff
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = n.Send(ctx, ev); err == nil {
+			return attempt, nil
+		}
+		if attempt >= maxAttempts || isPermanent(err) {
+			return attempt, err
+		}
+		select {
+		case <-ctx.Done():
+			return attempt, errors.Join(err, ctx.Err())
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+func (d *Dispatcher) record(channel string, ev Event, attempts int, sendErr error) {
+	if d.log == nil {
+		return
+	}
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		payload = []byte("{}")
+	}
+	row := &models.NotificationDelivery{
+		EventType: ev.Type,
+		EventKey:  ev.Key,
+		Channel:   channel,
+		Severity:  ev.Severity,
+		BranchID:  ev.BranchID,
+		StaffID:   ev.StaffID,
+		Title:     ev.Title,
+		Payload:   string(payload),
+		Status:    models.DeliveryDelivered,
+		Attempts:  attempts,
+	}
+	if sendErr != nil {
+		msg := sendErr.Error()
+		row.Status = models.DeliveryFailed
+		row.LastError = &msg
+	} else {
+		now := time.Now().UTC()
+		row.DeliveredAt = &now
+	}
+	if err := d.log.Insert(row); err != nil {
+		d.lg.Printf("⚠️  notify: record delivery of %s to %s: %v", ev.Type, channel, err)
+	}
+}
@@ -0,0 +1,84 @@
+// Package notify sends datahub events (failed syncs, stale data, low reviews,
+// ...) to the channels subscribed to them: signed webhooks, Slack-compatible
+// webhooks, email and a local file or log sink. Every delivery is retried and
+// written to notification_deliveries.
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Event types. Subscriptions match them exactly, by prefix ("sync.*") or "*".
+const (
+	EventSyncFailed     = "sync.failed"
+	EventDataStale      = "data.stale"
+	EventCSVSchemaDrift = "csv.schema_drift"
+	EventLowReview      = "review.low"
+	EventRatingAlert    = "review.rating_alert"
+	EventStockBelowMin  = "stock.below_minimum"
+	EventStockReorder   = "stock.reorder"
+	EventAppraisalDue   = "appraisal.due"
+)
+
+// EventTypes lists every event the datahub publishes.
+var EventTypes = []string{
+	EventSyncFailed, EventDataStale, EventCSVSchemaDrift, EventLowReview,
+	EventRatingAlert, EventStockBelowMin, EventStockReorder, EventAppraisalDue,
+}
+
+// Severities, lowest first. Subscriptions can ask for a minimum.
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+func severityRank(s string) int {
+	switch s {
+	case SeverityCritical:
+		return 2
+	case SeverityWarning:
+		return 1
+	}
+	return 0
+}
+
+// Event is one thing worth telling someone about. It is sent as JSON to
+// webhooks and stored as the delivery payload.
+type Event struct {
+	Type       string         `json:"type"`
+	Severity   string         `json:"severity"`
+	BranchID   string         `json:"branch_id,omitempty"`
+	StaffID    string         `json:"staff_id,omitempty"`
+	Title      string         `json:"title"`
+	Body       string         `json:"body,omitempty"`
+	Data       map[string]any `json:"data,omitempty"`
+	OccurredAt time.Time      `json:"occurred_at"`
+
+	// Key makes delivery at-most-once per channel: an event whose key was
+	// already delivered to a channel is not sent there again. "" = no dedupe.
+	Key string `json:"key,omitempty"`
+}
+
+// Text renders the event as plain text for chat and email.
+func (e Event) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] %s", e.Severity, e.Title)
+	if e.Body != "" {
+		b.WriteString("\n")
+		b.WriteString(e.Body)
+	}
+	var where []string
+	if e.BranchID != "" {
+		where = append(where, "branch "+e.BranchID)
+	}
+	if e.StaffID != "" {
+		where = append(where, "staff "+e.StaffID)
+	}
+	if len(where) > 0 {
+		fmt.Fprintf(&b, "\n(%s)", strings.Join(where, ", "))
+	}
+	return b.String()
+}
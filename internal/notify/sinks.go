@@ -0,0 +1,89 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SMTPNotifier emails the event as plain text.
+type SMTPNotifier struct {
+	Addr     string // host:port
+	Username string // "" = no auth
+	Password string
+	From     string
+	To       []string
+}
+
+func (n *SMTPNotifier) Send(ctx context.Context, ev Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(n.To) == 0 {
+		return Permanent(fmt.Errorf("smtp: no recipients"))
+	}
+
+	var auth smtp.Auth
+	if n.Username != "" {
+		host := n.Addr
+		if i := strings.LastIndex(host, ":"); i >= 0 {
+			host = host[:i]
+		}
+		auth = smtp.PlainAuth("", n.Username, n.Password, host)
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", n.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(n.To, ", "))
+	fmt.Fprintf(&msg, "Subject: [datahub] %s\r\n", strings.ReplaceAll(ev.Title, "\n", " "))
+	fmt.Fprintf(&msg, "Date: %s\r\n", ev.OccurredAt.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(ev.Text(), "\n", "\r\n"))
+	msg.WriteString("\r\n")
+
+	return smtp.SendMail(n.Addr, auth, n.From, n.To, []byte(msg.String()))
+}
+
+// FileNotifier appends each event as a JSON line, e.g. for local testing or
+// a log shipper.
+type FileNotifier struct {
+	Path string
+	mu   sync.Mutex
+}
+
+func (n *FileNotifier) Send(_ context.Context, ev Event) error {
+	line, err := json.Marshal(ev)
+	if err != nil {
+		return Permanent(err)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	f, err := os.OpenFile(n.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LogNotifier writes events to a logger; it's the default channel when no
+// NOTIFY_CONFIG is set.
+type LogNotifier struct {
+	Logger *log.Logger
+}
+
+func (n *LogNotifier) Send(_ context.Context, ev Event) error {
+	n.Logger.Printf("🔔 %s %s", ev.Type, strings.ReplaceAll(ev.Text(), "\n", " — "))
+	return nil
+}
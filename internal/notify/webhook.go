@@ -0,0 +1,89 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Webhook signature headers. Receivers recompute
+// hex(HMAC-SHA256(secret, timestamp + "." + body)) and compare it with the
+// signature (after the "sha256=" prefix), rejecting stale timestamps.
+const (
+	HeaderEvent     = "X-Datahub-Event"
+	HeaderTimestamp = "X-Datahub-Timestamp"
+	HeaderSignature = "X-Datahub-Signature"
+)
+
+// WebhookNotifier POSTs the event as JSON, signed with a shared secret.
+type WebhookNotifier struct {
+	URL    string
+	Secret string
+	Client *http.Client
+}
+
+func NewWebhookNotifier(url, secret string, timeout time.Duration) *WebhookNotifier {
+	if timeout <= 0 {
+		timeout = defaultHTTPTimeout
+	}
+	return &WebhookNotifier{URL: url, Secret: secret, Client: &http.Client{Timeout: timeout}}
+}
+
+func (n *WebhookNotifier) Send(ctx context.Context, ev Event) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return Permanent(err)
+	}
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	headers := map[string]string{
+		HeaderEvent:     ev.Type,
+		HeaderTimestamp: ts,
+	}
+	if n.Secret != "" {
+		headers[HeaderSignature] = "sha256=" + Sign(n.Secret, ts, body)
+	}
+	return postJSON(ctx, n.Client, n.URL, body, headers)
+}
+
+// Sign returns the hex HMAC-SHA256 of timestamp + "." + body.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SlackNotifier posts to a Slack incoming webhook (or anything that accepts
+// the same {"text": ...} body, e.g. Mattermost).
+type SlackNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+func NewSlackNotifier(url string, timeout time.Duration) *SlackNotifier {
+	if timeout <= 0 {
+		timeout = defaultHTTPTimeout
+	}
+	return &SlackNotifier{URL: url, Client: &http.Client{Timeout: timeout}}
+}
+
+func (n *SlackNotifier) Send(ctx context.Context, ev Event) error {
+	icon := "ℹ️"
+	switch ev.Severity {
+	case SeverityWarning:
+		icon = "⚠️"
+	case SeverityCritical:
+		icon = "🚨"
+	}
+	body, err := json.Marshal(map[string]string{"text": icon + " " + ev.Text()})
+	if err != nil {
+		return Permanent(err)
+	}
+	return postJSON(ctx, n.Client, n.URL, body, nil)
+}
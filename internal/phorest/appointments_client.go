@@ -0,0 +1,236 @@
+package phorest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/araquach/phorest-datahub/internal/models"
+)
+
+type appointmentAPIResponse struct {
+	Embedded struct {
+		Appointments []struct {
+			AppointmentID   string    `json:"appointmentId"`
+			Version         int64     `json:"version"`
+			AppointmentDate string    `json:"appointmentDate"` // "YYYY-MM-DD"
+			StartTime       string    `json:"startTime"`       // "HH:MM:SS.sss", branch local
+			EndTime         string    `json:"endTime"`
+			Price           float64   `json:"price"`
+			DepositAmount   float64   `json:"depositAmount"`
+			StaffRequest    bool      `json:"staffRequest"`
+			ClientID        string    `json:"clientId"`
+			ServiceID       string    `json:"serviceId"`
+			ServiceName     string    `json:"serviceName"`
+			StaffID         string    `json:"staffId"`
+			RoomID          string    `json:"roomId"`
+			BookingID       string    `json:"bookingId"`
+			State           string    `json:"state"`
+			ActivationState string    `json:"activationState"`
+			Source          string    `json:"source"`
+			Confirmed       bool      `json:"confirmed"`
+			NoShow          bool      `json:"noShow"`
+			CreatedAt       time.Time `json:"createdAt"`
+			UpdatedAt       time.Time `json:"updatedAt"`
+		} `json:"appointments"`
+	} `json:"_embedded"`
+	Page struct {
+		Size          int `json:"size"`
+		TotalElements int `json:"totalElements"`
+		TotalPages    int `json:"totalPages"`
+		Number        int `json:"number"`
+	} `json:"page"`
+}
+
+// Phorest appointment values the flags are derived from.
+const (
+	appointmentCancelled = "CANCELED" // activationState
+	appointmentNoShow    = "NO_SHOW"  // state
+	appointmentOnline    = "ONLINE"   // contained in source (ONLINE, ONLINE_BOOKING, ...)
+)
+
+// maxAppointmentRange is the widest from_date..to_date span the appointment
+// list accepts; longer windows are split by the caller.
+const maxAppointmentRange = 31 * 24 * time.Hour
+
+// dateRange is an inclusive span of days.
+type dateRange struct{ from, to time.Time }
+
+// appointmentRanges splits the days from..to into spans the appointment
+// (and roster) lists accept.
+func appointmentRanges(from, to time.Time) []dateRange {
+	day := func(t time.Time) time.Time {
+		t = t.UTC()
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	var out []dateRange
+	end := day(to)
+	for start := day(from); !start.After(end); {
+		stop := start.Add(maxAppointmentRange - 24*time.Hour)
+		if stop.After(end) {
+			stop = end
+		}
+		out = append(out, dateRange{start, stop})
+		start = stop.AddDate(0, 0, 1)
+	}
+	return out
+}
+
+type AppointmentsClient struct {
+	BaseURL  string
+	User     string
+	Pass     string
+	Business string
+	HTTP     *http.Client
+}
+
+func NewAppointmentsClient(user, pass, business string) *AppointmentsClient {
+	return &AppointmentsClient{
+		BaseURL:  "https://api-gateway-eu.phorest.com/third-party-api-server/api",
+		User:     user,
+		Pass:     pass,
+		Business: business,
+		HTTP: &http.Client{
+			Timeout: 30 * time.Second,
+			Transport: &http.Transport{
+				DialContext: (&net.Dialer{
+					Timeout:   10 * time.Second,
+					KeepAlive: 30 * time.Second,
+				}).DialContext,
+				MaxIdleConns:        100,
+				IdleConnTimeout:     90 * time.Second,
+				TLSHandshakeTimeout: 10 * time.Second,
+			},
+		},
+	}
+}
+
+// AppointmentQuery pages the appointments dated From..To (inclusive days,
+// at most maxAppointmentRange apart). Cancelled appointments are included.
+type AppointmentQuery struct {
+	From time.Time
+	To   time.Time
+	Page int
+	Size int
+}
+
+// AppointmentPage is one page of an appointment listing plus Phorest's paging totals.
+type AppointmentPage struct {
+	Appointments  []models.Appointment
+	TotalPages    int
+	TotalElements int
+}
+
+// ListAppointments fetches one page of a branch's appointments.
+func (c *AppointmentsClient) ListAppointments(ctx context.Context, branchID string, q AppointmentQuery) (*AppointmentPage, error) {
+	if q.Size <= 0 {
+		q.Size = 100
+	}
+	params := url.Values{}
+	params.Set("size", strconv.Itoa(q.Size))
+	params.Set("page", strconv.Itoa(q.Page))
+	params.Set("from_date", q.From.UTC().Format("2006-01-02"))
+	params.Set("to_date", q.To.UTC().Format("2006-01-02"))
+	params.Set("fetch_canceled", "true")
+	u := fmt.Sprintf("%s/business/%s/branch/%s/appointment?%s",
+		c.BaseURL, c.Business, branchID, params.Encode())
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(c.User, c.Pass)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		b, _ := io.ReadAll(resp.Body)
+		return nil, &HTTPStatusError{Op: "phorest appointments " + branchID, StatusCode: resp.StatusCode, Body: string(b)}
+	}
+
+	var api appointmentAPIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&api); err != nil {
+		return nil, err
+	}
+
+	out := make([]models.Appointment, 0, len(api.Embedded.Appointments))
+	for _, a := range api.Embedded.Appointments {
+		day, err := time.Parse("2006-01-02", a.AppointmentDate)
+		if err != nil {
+			return nil, fmt.Errorf("appointment %s: bad appointmentDate %q", a.AppointmentID, a.AppointmentDate)
+		}
+		start, err := clockOn(day, a.StartTime)
+		if err != nil {
+			return nil, fmt.Errorf("appointment %s: bad startTime: %w", a.AppointmentID, err)
+		}
+		end, err := clockOn(day, a.EndTime)
+		if err != nil {
+			return nil, fmt.Errorf("appointment %s: bad endTime: %w", a.AppointmentID, err)
+		}
+
+		created, updated := a.CreatedAt, a.UpdatedAt
+		out = append(out, models.Appointment{
+			AppointmentID:    a.AppointmentID,
+			BranchID:         branchID,
+			Version:          a.Version,
+			AppointmentDate:  day,
+			StartAt:          start,
+			EndAt:            end,
+			ClientID:         a.ClientID,
+			StaffID:          a.StaffID,
+			ServiceID:        a.ServiceID,
+			ServiceName:      a.ServiceName,
+			RoomID:           a.RoomID,
+			BookingID:        a.BookingID,
+			Price:            optFloat(a.Price),
+			DepositAmount:    optFloat(a.DepositAmount),
+			State:            a.State,
+			ActivationState:  a.ActivationState,
+			Source:           a.Source,
+			BookedOnline:     strings.Contains(strings.ToUpper(a.Source), appointmentOnline),
+			StaffRequest:     a.StaffRequest,
+			Confirmed:        a.Confirmed,
+			Cancelled:        a.ActivationState == appointmentCancelled,
+			NoShow:           a.NoShow || a.State == appointmentNoShow,
+			CreatedAtPhorest: nonZeroTime(created),
+			UpdatedAtPhorest: nonZeroTime(updated),
+		})
+	}
+	return &AppointmentPage{
+		Appointments:  out,
+		TotalPages:    api.Page.TotalPages,
+		TotalElements: api.Page.TotalElements,
+	}, nil
+}
+
+// clockOn combines a date with a Phorest "HH:MM:SS[.sss]" wall-clock time.
+func clockOn(day time.Time, clock string) (time.Time, error) {
+	for _, layout := range []string{"15:04:05.000", "15:04:05", "15:04"} {
+		if t, err := time.Parse(layout, clock); err == nil {
+			return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised time %q", clock)
+}
+
+func nonZeroTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
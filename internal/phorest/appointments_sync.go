@@ -0,0 +1,138 @@
+package phorest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/araquach/phorest-datahub/internal/config"
+	"github.com/araquach/phorest-datahub/internal/repos"
+)
+
+const appointmentsPageSize = 100
+
+// RunIncrementalAppointmentsSync pulls each branch's appointments dated from
+// the appointments_api watermark (newest updatedAt seen, minus the overlap,
+// or a periodic wider sweep) through Cfg.AppointmentsLookahead from today,
+// so future bookings and their cancellations are kept current. Without a
+// watermark it goes back Cfg.AppointmentsBackfill.
+func (r *Runner) RunIncrementalAppointmentsSync(ctx context.Context) error {
+	lg := r.Logger
+
+	lg.Printf("▶️ Starting incremental APPOINTMENTS sync...")
+
+	c := r.appointmentsClient()
+	wr := r.watermarks()
+
+	err := r.forEachBranch(ctx, SyncKindAppointments, func(ctx context.Context, b config.BranchConfig) error {
+		lg.Printf("🏢 Branch %s (%s): starting APPOINTMENTS sync", b.Name, b.BranchID)
+
+		last, err := wr.GetLastUpdated("appointments_api", b.BranchID)
+		if err != nil {
+			return fmt.Errorf("get appointments_api watermark for %s: %w", b.BranchID, err)
+		}
+
+		now := time.Now().UTC()
+		from := now.Add(-r.Cfg.AppointmentsBackfill)
+		sweep := false
+		if last != nil {
+			from, sweep, err = r.lookbackStart("appointments_api", b.BranchID, *last)
+			if err != nil {
+				return fmt.Errorf("appointments_api look-back for %s: %w", b.BranchID, err)
+			}
+		} else {
+			lg.Printf("   %s: no appointments watermark → backfilling from %s", b.BranchID, from.Format(exportDateFmt))
+		}
+		to := now.Add(r.Cfg.AppointmentsLookahead)
+
+		stats, err := r.syncAppointmentsWindow(ctx, c, b.BranchID, from, to)
+		if err != nil {
+			return err
+		}
+
+		if stats.MaxUpdated != nil {
+			if err := wr.UpsertLastUpdated("appointments_api", b.BranchID, *stats.MaxUpdated); err != nil {
+				return Partial(fmt.Errorf("update appointments_api watermark for %s: %w", b.BranchID, err))
+			}
+		}
+		if sweep {
+			r.markSweep("appointments_api", b.BranchID)
+		}
+
+		lg.Printf("✅ APPOINTMENTS sync finished for %s (%d appointment(s), %d changed)", b.BranchID, stats.Rows, stats.Changed)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	lg.Printf("✅ All branches APPOINTMENTS sync finished")
+	return nil
+}
+
+// RunAppointmentsWindowSync re-pulls appointments dated between from and to
+// (inclusive) for every configured branch, ignoring the watermark.
+func (r *Runner) RunAppointmentsWindowSync(ctx context.Context, from, to time.Time) error {
+	lg := r.Logger
+
+	lg.Printf("▶️ Starting APPOINTMENTS window sync %s..%s", from.Format(exportDateFmt), to.Format(exportDateFmt))
+
+	c := r.appointmentsClient()
+	err := r.forEachBranch(ctx, SyncKindAppointments, func(ctx context.Context, b config.BranchConfig) error {
+		stats, err := r.syncAppointmentsWindow(ctx, c, b.BranchID, from, to)
+		if err != nil {
+			return err
+		}
+		lg.Printf("✅ APPOINTMENTS window sync finished for %s (%d appointment(s), %d changed)", b.BranchID, stats.Rows, stats.Changed)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	lg.Printf("✅ All branches APPOINTMENTS window sync finished")
+	return nil
+}
+
+func (r *Runner) appointmentsClient() *AppointmentsClient {
+	return NewAppointmentsClient(r.Cfg.PhorestUsername, r.Cfg.PhorestPassword, r.Cfg.PhorestBusiness)
+}
+
+// syncAppointmentsWindow pages through one branch's appointments dated
+// from..to, split into ranges the API accepts, upserting each page. It adds
+// fetched vs changed rows to the run report; errors after anything was
+// saved are marked Partial.
+func (r *Runner) syncAppointmentsWindow(ctx context.Context, c *AppointmentsClient, branchID string, from, to time.Time) (importStats, error) {
+	repo := repos.NewAppointmentsRepo(r.DB, r.Logger)
+
+	var stats importStats
+	defer func() { r.count(SyncKindAppointments, branchID, stats.Rows, stats.Changed) }()
+
+	for _, rg := range appointmentRanges(from, to) {
+		for page := 0; ; page++ {
+			res, err := c.ListAppointments(ctx, branchID, AppointmentQuery{From: rg.from, To: rg.to, Page: page, Size: appointmentsPageSize})
+			if err != nil {
+				return stats, partialAfter(int(stats.Rows), fmt.Errorf("list appointments %s..%s page %d: %w",
+					rg.from.Format(exportDateFmt), rg.to.Format(exportDateFmt), page, err))
+			}
+			if len(res.Appointments) == 0 {
+				break
+			}
+
+			changed, err := repo.UpsertBatch(res.Appointments, 500)
+			if err != nil {
+				return stats, partialAfter(int(stats.Rows), fmt.Errorf("upsert appointments: %w", err))
+			}
+			stats.Rows += int64(len(res.Appointments))
+			stats.Changed += changed
+			for i := range res.Appointments {
+				stats.MaxUpdated = maxTime(stats.MaxUpdated, res.Appointments[i].UpdatedAtPhorest)
+			}
+
+			if res.TotalPages > 0 && page+1 >= res.TotalPages {
+				break
+			}
+		}
+	}
+	return stats, nil
+}
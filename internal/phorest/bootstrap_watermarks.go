@@ -3,7 +3,7 @@ package phorest
 import (
 	"time"
 
-	"gorm.io/gorm"
+	"github.com/araquach/phorest-datahub/internal/repos"
 )
 
 // small helper DTOs
@@ -26,6 +26,8 @@ func (r *Runner) BootstrapWatermarks() error {
 
 	lg.Printf("🔧 Bootstrapping sync_watermarks from existing data...")
 
+	wr := r.watermarks().ForRun(r.RunID, repos.WatermarkChangedByBootstrap)
+
 	// 1) Per-branch watermark for transaction_items
 	var txRows []txWatermarkRow
 	if err := db.
@@ -44,9 +46,9 @@ func (r *Runner) BootstrapWatermarks() error {
 		if row.MaxUpdatedAtPh == nil {
 			continue
 		}
-		if err := upsertWatermark(db,
+		if err := wr.UpsertLastUpdated(
 			"transactions_csv",
-			&row.BranchID,
+			row.BranchID,
 			*row.MaxUpdatedAtPh,
 		); err != nil {
 			return err
@@ -66,10 +68,9 @@ func (r *Runner) BootstrapWatermarks() error {
 	}
 
 	if clientRow.MaxUpdatedAtPh != nil {
-		all := "ALL"
-		if err := upsertWatermark(db,
+		if err := wr.UpsertLastUpdated(
 			"clients_csv",
-			&all, // <── HERE: use "ALL"
+			"ALL",
 			*clientRow.MaxUpdatedAtPh,
 		); err != nil {
 			return err
@@ -83,25 +84,3 @@ func (r *Runner) BootstrapWatermarks() error {
 	lg.Printf("✅ sync_watermarks bootstrap complete.")
 	return nil
 }
-
-// upsertWatermark inserts or updates a single (entity, branch_id) row.
-func upsertWatermark(db *gorm.DB, entity string, branchID *string, lastUpdatedPh time.Time) error {
-	if lastUpdatedPh.IsZero() {
-		return nil
-	}
-
-	// Normalise "global" to ALL
-	if branchID == nil || *branchID == "" {
-		all := "ALL"
-		branchID = &all
-	}
-
-	return db.Exec(`
-		INSERT INTO sync_watermarks (entity, branch_id, last_updated_phorest, created_at, updated_at)
-		VALUES ($1, $2, $3, now(), now())
-		ON CONFLICT (entity, branch_id)
-		DO UPDATE SET
-			last_updated_phorest = GREATEST(sync_watermarks.last_updated_phorest, EXCLUDED.last_updated_phorest),
-			updated_at           = now()
-	`, entity, *branchID, lastUpdatedPh).Error
-}
@@ -64,10 +64,10 @@ func NewBranchClient(user, pass, business string, lg *log.Logger) *BranchClient
 	}
 }
 
-func (c *BranchClient) FetchBranches() ([]models.Branch, error) {
+func (c *BranchClient) FetchBranches(ctx context.Context) ([]models.Branch, error) {
 	url := fmt.Sprintf("%s/business/%s/branch", c.BaseURL, c.Business)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Second)
 	defer cancel()
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
@@ -85,7 +85,7 @@ func (c *BranchClient) FetchBranches() ([]models.Branch, error) {
 
 	if resp.StatusCode < 200 || resp.StatusCode > 299 {
 		b, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("phorest branches: status %d: %s", resp.StatusCode, string(b))
+		return nil, &HTTPStatusError{Op: "phorest branches", StatusCode: resp.StatusCode, Body: string(b)}
 	}
 
 	var api BranchAPIResponse
@@ -0,0 +1,78 @@
+package phorest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/araquach/phorest-datahub/internal/config"
+)
+
+// BranchError is one branch's failure within a multi-branch sync.
+type BranchError struct {
+	BranchID string
+	Name     string
+	Err      error
+}
+
+func (e *BranchError) Error() string {
+	return fmt.Sprintf("%s (%s): %v", e.Name, e.BranchID, e.Err)
+}
+
+func (e *BranchError) Unwrap() error { return e.Err }
+
+// forEachBranch runs fn for every configured branch, at most
+// Cfg.SyncConcurrency at a time, and records one SyncResult per branch under
+// entity. A failing branch does not stop the others; all failures are returned
+// together (errors.Join of *BranchError) once every branch has finished.
+// Branches with an empty BranchID are skipped.
+func (r *Runner) forEachBranch(ctx context.Context, entity string, fn func(ctx context.Context, b config.BranchConfig) error) error {
+	limit := r.Cfg.SyncConcurrency
+	if limit < 1 {
+		limit = 1
+	}
+
+	var (
+		g    errgroup.Group
+		mu   sync.Mutex
+		errs []error
+	)
+	g.SetLimit(limit)
+
+	start := time.Now()
+	for _, b := range r.Cfg.Branches {
+		if b.BranchID == "" {
+			r.Logger.Printf("⚠️  %s sync: skipping branch with empty BranchID (name=%q)", entity, b.Name)
+			continue
+		}
+
+		g.Go(func() error {
+			started := time.Now()
+			// Don't start new branches once the run has been cancelled.
+			err := ctx.Err()
+			if err == nil {
+				err = fn(ctx, b)
+			}
+			r.record(entity, b.BranchID, started, err)
+			if err != nil {
+				r.Logger.Printf("❌ %s sync failed for %s (%s) [%s]: %v", entity, b.Name, b.BranchID, Categorize(err), err)
+				mu.Lock()
+				errs = append(errs, &BranchError{BranchID: b.BranchID, Name: b.Name, Err: err})
+				mu.Unlock()
+			}
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	if len(errs) > 0 {
+		r.Logger.Printf("⚠️  %s sync finished in %s with %d failed branch(es)",
+			entity, time.Since(start).Round(time.Second), len(errs))
+		return errors.Join(errs...)
+	}
+	return nil
+}
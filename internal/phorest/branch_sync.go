@@ -1,12 +1,17 @@
 package phorest
 
 import (
+	"context"
+	"fmt"
 	"time"
 
 	"github.com/araquach/phorest-datahub/internal/repos"
 )
 
-func (r *Runner) SyncBranchesFromAPI() error {
+func (r *Runner) SyncBranchesFromAPI(ctx context.Context) (err error) {
+	started := time.Now()
+	defer func() { r.record(SyncKindBranches, "ALL", started, err) }()
+
 	c := NewBranchClient(
 		r.Cfg.PhorestUsername,
 		r.Cfg.PhorestPassword,
@@ -14,9 +19,9 @@ func (r *Runner) SyncBranchesFromAPI() error {
 		r.Logger,
 	)
 	repo := repos.NewBranchRepo(r.DB, r.Logger)
-	wr := repos.NewWatermarksRepo(r.DB, r.Logger)
+	wr := r.watermarks()
 
-	rows, err := c.FetchBranches()
+	rows, err := c.FetchBranches(ctx)
 	if err != nil {
 		r.Logger.Printf("❌ branch fetch failed: %v", err)
 		return err
@@ -35,7 +40,8 @@ func (r *Runner) SyncBranchesFromAPI() error {
 	now := time.Now().UTC()
 	if err := wr.UpsertLastUpdated("branches_api", "ALL", now); err != nil {
 		r.Logger.Printf("⚠️ failed to update branches_api watermark: %v", err)
-		// you can choose to return err here if you want it to be fatal
+		// Branches are saved; only the bookkeeping failed.
+		return Partial(fmt.Errorf("update branches_api watermark: %w", err))
 	}
 
 	r.Logger.Printf("✅ branches upserted: %d", len(rows))
@@ -15,6 +15,7 @@ import (
 
 type ParsedClients struct {
 	Clients []models.Client
+	Drift   *SchemaDrift // header vs the columns read; nil = no change
 }
 
 // ParseClientsCSV reads a Phorest clients CSV and returns a unique set by client_id.
@@ -33,10 +34,7 @@ func ParseClientsCSV(path string, lg *log.Logger) (*ParsedClients, error) {
 	if err != nil {
 		return nil, fmt.Errorf("read header: %w", err)
 	}
-	idx := make(map[string]int, len(header))
-	for i, h := range header {
-		idx[strings.TrimSpace(strings.ToLower(h))] = i
-	}
+	cols := newCSVColumns(header)
 
 	clean := func(s string) string {
 		// force UTF-8 by decoding into runes and back out
@@ -45,7 +43,7 @@ func ParseClientsCSV(path string, lg *log.Logger) (*ParsedClients, error) {
 	}
 
 	get := func(rec []string, name string) string {
-		i, ok := idx[name]
+		i, ok := cols.index(name)
 		if !ok || i >= len(rec) {
 			return ""
 		}
@@ -206,5 +204,5 @@ func ParseClientsCSV(path string, lg *log.Logger) (*ParsedClients, error) {
 	}
 
 	lg.Printf("Parsed clients CSV: %d unique clients", len(out))
-	return &ParsedClients{Clients: out}, nil
+	return &ParsedClients{Clients: out, Drift: cols.drift(path)}, nil
 }
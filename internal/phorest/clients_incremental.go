@@ -5,17 +5,18 @@ import (
 	"fmt"
 	"path/filepath"
 	"time"
-
-	"github.com/araquach/phorest-datahub/internal/repos"
 )
 
-func (r *Runner) RunIncrementalClientsSync(ctx context.Context) error {
+func (r *Runner) RunIncrementalClientsSync(ctx context.Context) (err error) {
 	lg := r.Logger
-	db := r.DB
+
+	// CLIENT_CSV is business-wide, so there is one result for "ALL".
+	started := time.Now()
+	defer func() { r.record(SyncKindClients, "ALL", started, err) }()
 
 	lg.Printf("▶️ Starting incremental CLIENT_CSV sync...")
 
-	wr := repos.NewWatermarksRepo(db, lg)
+	wr := r.watermarks()
 
 	// --- 1) Read watermark
 	last, err := wr.GetLastUpdated("clients_csv", "ALL")
@@ -24,11 +25,18 @@ func (r *Runner) RunIncrementalClientsSync(ctx context.Context) error {
 	}
 
 	var filterExpr string
+	sweep := false
 	if last == nil {
 		lg.Printf("ℹ️ No clients_csv watermark – requesting full export")
 		filterExpr = "" // full export
 	} else {
-		from := last.UTC().Format("2006-01-02T15:04:05.000Z")
+		// Look back before the watermark so late edits are re-pulled
+		var start time.Time
+		start, sweep, err = r.lookbackStart("clients_csv", "ALL", *last)
+		if err != nil {
+			return fmt.Errorf("clients_csv look-back: %w", err)
+		}
+		from := start.UTC().Format("2006-01-02T15:04:05.000Z")
 		filterExpr = fmt.Sprintf("updated=>%s", from)
 		lg.Printf("ℹ️ Using filterExpression=%q", filterExpr)
 	}
@@ -57,6 +65,7 @@ func (r *Runner) RunIncrementalClientsSync(ctx context.Context) error {
 	// --- 4) Poll job
 	waitMax := 5 * time.Minute
 	final, err := r.Export.WaitForCSVJob(
+		ctx,
 		r.Cfg.PhorestBusiness,
 		b.BranchID,
 		job.JobID,
@@ -81,9 +90,14 @@ func (r *Runner) RunIncrementalClientsSync(ctx context.Context) error {
 	lg.Printf("💾 Saved CLIENT_CSV to %s", dest)
 
 	// --- 6) Re-use your existing CSV import logic
-	if err := r.importSingleClientsCSV(dest); err != nil {
+	stats, err := r.importSingleClientsCSV(dest)
+	if err != nil {
 		return fmt.Errorf("import incremental clients csv: %w", err)
 	}
+	r.count(SyncKindClients, "ALL", stats.Rows, stats.Changed)
+	if sweep {
+		r.markSweep("clients_csv", "ALL")
+	}
 
 	// Archive this CSV into the bootstrap clients dir
 	r.archiveCSVToSeed(dest, "data/clients")
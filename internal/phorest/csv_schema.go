@@ -0,0 +1,104 @@
+package phorest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/araquach/phorest-datahub/internal/notify"
+)
+
+// SchemaDrift is how a CSV header differs from the columns its parser reads.
+type SchemaDrift struct {
+	File    string
+	Missing []string // columns the parser reads that the file doesn't have (read as "")
+	Extra   []string // columns in the file the parser never reads
+	header  []string
+}
+
+// csvColumns maps header names to positions and remembers which ones the
+// parser asked for, so the header can be compared with what was used.
+type csvColumns struct {
+	idx    map[string]int
+	header []string
+	used   map[string]bool
+}
+
+func newCSVColumns(header []string) *csvColumns {
+	c := &csvColumns{
+		idx:    make(map[string]int, len(header)),
+		header: make([]string, 0, len(header)),
+		used:   map[string]bool{},
+	}
+	for i, h := range header {
+		name := strings.TrimSpace(strings.ToLower(h))
+		c.idx[name] = i
+		c.header = append(c.header, name)
+	}
+	return c
+}
+
+func (c *csvColumns) index(name string) (int, bool) {
+	c.used[name] = true
+	i, ok := c.idx[name]
+	return i, ok
+}
+
+// drift compares the header with the columns read so far; nil if they match
+// or no row was parsed (nothing was read, so nothing can be compared).
+func (c *csvColumns) drift(path string) *SchemaDrift {
+	if len(c.used) == 0 {
+		return nil
+	}
+	d := &SchemaDrift{File: filepath.Base(path), header: c.header}
+	for name := range c.used {
+		if _, ok := c.idx[name]; !ok {
+			d.Missing = append(d.Missing, name)
+		}
+	}
+	for name := range c.idx {
+		if name != "" && !c.used[name] {
+			d.Extra = append(d.Extra, name)
+		}
+	}
+	if len(d.Missing) == 0 && len(d.Extra) == 0 {
+		return nil
+	}
+	sort.Strings(d.Missing)
+	sort.Strings(d.Extra)
+	return d
+}
+
+// notifyDrift logs a header change and publishes it once per distinct header
+// layout. Missing columns are a warning (fields silently go empty); new
+// columns are informational.
+func (r *Runner) notifyDrift(kind string, d *SchemaDrift) {
+	if d == nil {
+		return
+	}
+	r.Logger.Printf("⚠️  %s CSV %s header drift: missing=%v extra=%v", kind, d.File, d.Missing, d.Extra)
+
+	sev := notify.SeverityInfo
+	if len(d.Missing) > 0 {
+		sev = notify.SeverityWarning
+	}
+	sum := sha256.Sum256([]byte(strings.Join(d.header, ",")))
+	r.notify(context.Background(), notify.Event{
+		Type:     notify.EventCSVSchemaDrift,
+		Severity: sev,
+		Title:    fmt.Sprintf("%s CSV columns changed", kind),
+		Body: fmt.Sprintf("%s: %d expected column(s) missing, %d new column(s)",
+			d.File, len(d.Missing), len(d.Extra)),
+		Data: map[string]any{
+			"csv":     kind,
+			"file":    d.File,
+			"missing": d.Missing,
+			"extra":   d.Extra,
+		},
+		Key: notify.EventCSVSchemaDrift + "|" + kind + "|" + hex.EncodeToString(sum[:8]),
+	})
+}
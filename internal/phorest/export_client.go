@@ -96,7 +96,7 @@ func (c *ExportClient) CreateCSVExport(
 
 	b, _ := io.ReadAll(resp.Body)
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("CSV export create failed: %s — %s", resp.Status, string(b))
+		return nil, &HTTPStatusError{Op: "CSV export create failed", StatusCode: resp.StatusCode, Body: string(b)}
 	}
 
 	var out ExportResponse
@@ -107,8 +107,9 @@ func (c *ExportClient) CreateCSVExport(
 	return &out, nil
 }
 
-// Wait for a job to finish:
+// Wait for a job to finish (returns early with ctx.Err() if ctx is cancelled):
 func (c *ExportClient) WaitForCSVJob(
+	ctx context.Context,
 	businessID string,
 	branchID string,
 	jobID string,
@@ -125,10 +126,13 @@ func (c *ExportClient) WaitForCSVJob(
 
 	for {
 		if time.Now().After(deadline) {
-			return nil, fmt.Errorf("timeout waiting for job %s", jobID)
+			return nil, Transient(fmt.Errorf("timeout waiting for job %s", jobID))
 		}
 
-		req, _ := http.NewRequest("GET", url, nil)
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return nil, err
+		}
 		req.SetBasicAuth(c.username, c.password)
 		req.Header.Set("Accept", "application/json")
 
@@ -140,7 +144,7 @@ func (c *ExportClient) WaitForCSVJob(
 		res.Body.Close()
 
 		if res.StatusCode < 200 || res.StatusCode > 299 {
-			return nil, fmt.Errorf("poll non-2xx: %s — %s", res.Status, string(b))
+			return nil, &HTTPStatusError{Op: "poll non-2xx", StatusCode: res.StatusCode, Body: string(b)}
 		}
 
 		var out ExportResponse
@@ -163,7 +167,11 @@ func (c *ExportClient) WaitForCSVJob(
 			return &out, fmt.Errorf("CSV job FAILED: %s", jobID)
 
 		default:
-			time.Sleep(backoff)
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(backoff):
+			}
 			if backoff < 10*time.Second {
 				backoff += 2 * time.Second
 			}
@@ -202,7 +210,7 @@ func (c *ExportClient) tryDownload(url string, outPath string, withAuth bool) er
 
 	if res.StatusCode < 200 || res.StatusCode >= 300 {
 		b, _ := io.ReadAll(res.Body)
-		return fmt.Errorf("download non-2xx: %s — %s", res.Status, string(b))
+		return &HTTPStatusError{Op: "download non-2xx", StatusCode: res.StatusCode, Body: string(b)}
 	}
 
 	f, err := os.Create(outPath)
@@ -0,0 +1,103 @@
+package phorest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/araquach/phorest-datahub/internal/notify"
+)
+
+// NotifyFailures publishes a sync.failed event for every failed or partial
+// result in the Runner's report. Fatal errors are critical (someone has to
+// act); transient ones are warnings (the next run will probably recover).
+func (r *Runner) NotifyFailures(ctx context.Context) {
+	if r.Report == nil {
+		return
+	}
+	for _, res := range r.Report.Results() {
+		if res.Status == SyncSuccess {
+			continue
+		}
+		sev := notify.SeverityWarning
+		if res.Category == CategoryFatal {
+			sev = notify.SeverityCritical
+		}
+		ev := notify.Event{
+			Type:     notify.EventSyncFailed,
+			Severity: sev,
+			BranchID: res.BranchID,
+			Title:    fmt.Sprintf("%s sync %s", res.Entity, res.Status),
+			Data: map[string]any{
+				"entity":   res.Entity,
+				"status":   res.Status,
+				"category": res.Category,
+				"rows":     res.Rows,
+				"changed":  res.Changed,
+			},
+		}
+		if res.Err != nil {
+			ev.Body = res.Err.Error()
+		}
+		if r.RunID != nil {
+			ev.Data["run_id"] = *r.RunID
+		}
+		r.notify(ctx, ev)
+	}
+}
+
+// freshnessEntities are the watermarks that should advance every trading
+// day; reviews and products can legitimately sit still for longer.
+var freshnessEntities = map[string]bool{
+	"transactions_csv": true,
+	"clients_csv":      true,
+	"appointments_api": true,
+}
+
+// CheckFreshness publishes a data.stale event for each daily watermark that
+// is more than Cfg.StaleAfter behind now (critical past twice that). Each
+// stuck watermark value is reported once per severity.
+func (r *Runner) CheckFreshness(ctx context.Context, now time.Time) error {
+	if r.Cfg.StaleAfter <= 0 {
+		return nil
+	}
+	wms, err := r.watermarks().List()
+	if err != nil {
+		return fmt.Errorf("list watermarks: %w", err)
+	}
+
+	for _, wm := range wms {
+		if !freshnessEntities[wm.Entity] || wm.LastUpdatedPhorest == nil {
+			continue
+		}
+		age := now.Sub(*wm.LastUpdatedPhorest)
+		if age <= r.Cfg.StaleAfter {
+			continue
+		}
+		branchID := "ALL"
+		if wm.BranchID != nil && *wm.BranchID != "" {
+			branchID = *wm.BranchID
+		}
+
+		sev := notify.SeverityWarning
+		if age > 2*r.Cfg.StaleAfter {
+			sev = notify.SeverityCritical
+		}
+		days := int(age.Hours() / 24)
+		r.notify(ctx, notify.Event{
+			Type:     notify.EventDataStale,
+			Severity: sev,
+			BranchID: branchID,
+			Title:    fmt.Sprintf("%s data is %d day(s) old", wm.Entity, days),
+			Body: fmt.Sprintf("Newest %s row is from %s; nothing newer has synced since.",
+				wm.Entity, wm.LastUpdatedPhorest.UTC().Format(time.RFC3339)),
+			Data: map[string]any{
+				"entity":       wm.Entity,
+				"last_updated": wm.LastUpdatedPhorest.UTC(),
+				"age_hours":    int(age.Hours()),
+			},
+			Key: fmt.Sprintf("%s|%s|%s|%d|%s", notify.EventDataStale, wm.Entity, branchID, wm.LastUpdatedPhorest.Unix(), sev),
+		})
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package phorest
+
+import (
+	"time"
+)
+
+// sweepEntity is the watermark key that remembers when entity last ran its
+// wide look-back sweep for a branch.
+func sweepEntity(entity string) string {
+	return entity + ":sweep"
+}
+
+// lookbackStart applies the configured OverlapPolicy to a watermark. Normally
+// it returns wm minus the overlap; when a sweep is due (no sweep recorded yet,
+// or the last one is older than SweepEvery) it returns now minus SweepWindow
+// instead, and sweep=true so the caller can markSweep after a successful run.
+func (r *Runner) lookbackStart(entity, branchID string, wm time.Time) (start time.Time, sweep bool, err error) {
+	policy := r.Cfg.OverlapFor(entity)
+	start = wm.Add(-policy.Overlap)
+
+	if policy.SweepWindow <= 0 {
+		return start, false, nil
+	}
+
+	last, err := r.watermarks().GetLastUpdated(sweepEntity(entity), branchID)
+	if err != nil {
+		return start, false, err
+	}
+	now := time.Now().UTC()
+	if last != nil && now.Sub(*last) < policy.SweepEvery {
+		return start, false, nil
+	}
+
+	if sweepStart := now.Add(-policy.SweepWindow); sweepStart.Before(start) {
+		start = sweepStart
+	}
+	r.Logger.Printf("🧹 %s/%s: running %s look-back sweep from %s",
+		entity, branchID, policy.SweepWindow, start.Format(time.RFC3339))
+	return start, true, nil
+}
+
+// markSweep records that a sweep for entity/branch completed now.
+func (r *Runner) markSweep(entity, branchID string) {
+	if err := r.watermarks().UpsertLastUpdated(sweepEntity(entity), branchID, time.Now().UTC()); err != nil {
+		r.Logger.Printf("⚠️  failed to record %s sweep for %s: %v", entity, branchID, err)
+	}
+}
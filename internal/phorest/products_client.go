@@ -127,7 +127,7 @@ func (c *ProductsClient) ListProducts(ctx context.Context, opts ListProductsOpti
 		if msg == "" {
 			msg = "<empty body>"
 		}
-		return nil, fmt.Errorf("phorest: list products %s returned %d: %s", u.String(), resp.StatusCode, msg)
+		return nil, &HTTPStatusError{Op: "phorest: list products " + u.String(), StatusCode: resp.StatusCode, Body: msg}
 	}
 
 	var out listProductsResponse
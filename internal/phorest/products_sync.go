@@ -2,20 +2,59 @@ package phorest
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"os"
 	"time"
 
+	"github.com/araquach/phorest-datahub/internal/config"
 	"github.com/araquach/phorest-datahub/internal/models"
+	"github.com/araquach/phorest-datahub/internal/notify"
 	"github.com/araquach/phorest-datahub/internal/repos"
+	"github.com/araquach/phorest-datahub/internal/services"
+	"gorm.io/gorm"
 )
 
+// ProductStrategy selects how a products sync walks the Phorest listing.
+type ProductStrategy string
+
+const (
+	// ProductStrategyIncremental fetches products updated since the
+	// products_api watermark (minus the overlap). When a reconcile is due
+	// (Cfg.ProductsReconcileEvery) it runs one instead. This is the default.
+	ProductStrategyIncremental ProductStrategy = "incremental"
+	// ProductStrategyReconcile lists every product and marks stored ones
+	// Phorest no longer returns as removed.
+	ProductStrategyReconcile ProductStrategy = "reconcile"
+)
+
+// ProductStrategies lists every strategy accepted by ParseProductStrategy.
+var ProductStrategies = []ProductStrategy{ProductStrategyIncremental, ProductStrategyReconcile}
+
+// ParseProductStrategy maps a CLI/API value to a strategy; "" means incremental.
+func ParseProductStrategy(s string) (ProductStrategy, error) {
+	if s == "" {
+		return ProductStrategyIncremental, nil
+	}
+	for _, st := range ProductStrategies {
+		if string(st) == s {
+			return st, nil
+		}
+	}
+	return "", fmt.Errorf("unknown product strategy %q", s)
+}
+
+// productsReconcileEntity is the watermark key remembering when a branch's
+// products were last reconciled.
+const productsReconcileEntity = "products_api:reconcile"
+
 // SyncProductsFromAPI pulls products/stock for all configured branches
 // and writes to ph_products, ph_product_stock, and ph_product_stock_history.
-func (r *Runner) SyncProductsFromAPI() error {
+// Branches run in parallel (Cfg.SyncConcurrency at a time).
+func (r *Runner) SyncProductsFromAPI(ctx context.Context, strategy ProductStrategy) error {
 	lg := r.Logger
 
-	lg.Println("🚿 Starting PRODUCTS sync from Phorest API…")
+	lg.Printf("🚿 Starting PRODUCTS sync from Phorest API (strategy=%s)…", strategy)
 
 	pc := NewProductsClient(
 		"",
@@ -24,11 +63,10 @@ func (r *Runner) SyncProductsFromAPI() error {
 		r.Cfg.PhorestPassword,
 	)
 
-	productRepo := repos.NewPhProductRepo(r.DB)
 	stockRepo := repos.NewPhProductStockRepo(r.DB)
-	watermarks := repos.NewWatermarksRepo(r.DB, r.Logger)
-
-	ctx := context.Background()
+	changeRepo := repos.NewProductChangesRepo(r.DB).ForRun(r.RunID)
+	checkpoints := repos.NewProductSyncCheckpointsRepo(r.DB)
+	watermarks := r.watermarks()
 
 	productType := os.Getenv("PRODUCT_TYPE_FILTER") // "" = all
 	if productType == "" {
@@ -37,241 +75,561 @@ func (r *Runner) SyncProductsFromAPI() error {
 		lg.Printf("   PRODUCT_TYPE_FILTER=%s → syncing only this type", productType)
 	}
 
-	for _, b := range r.Cfg.Branches {
+	err := r.forEachBranch(ctx, SyncKindProducts, func(ctx context.Context, b config.BranchConfig) error {
 		lg.Printf("➡️  Syncing PRODUCTS for branch %s (ID: %s)", b.Name, b.BranchID)
 
 		wm, err := watermarks.GetLastUpdated("products_api", b.BranchID)
 		if err != nil {
 			return fmt.Errorf("get products watermark for %s: %w", b.BranchID, err)
 		}
+		reconcile, err := r.productsReconcileDue(strategy, b.BranchID)
+		if err != nil {
+			return err
+		}
+		if reconcile && productType != "" {
+			// A filtered listing can't tell a removed product from one of another type.
+			lg.Printf("⚠️  %s: PRODUCT_TYPE_FILTER is set; skipping products reconcile", b.BranchID)
+			reconcile = false
+		}
 
-		var updatedAfter, updatedBefore *time.Time
-
-		if wm != nil {
-			after := wm.UTC()
-			now := time.Now().UTC()
-			updatedAfter = &after
-			updatedBefore = &now
-			lg.Printf("   Using incremental window updatedAfter=%s, updatedBefore=%s",
-				after.Format(time.RFC3339), now.Format(time.RFC3339))
-		} else {
-			lg.Println("   No products watermark → full sync (no date filters)")
-		}
-
-		maxUpdatedAt, err := r.syncProductsForBranch(
-			ctx,
-			pc,
-			productRepo,
-			stockRepo,
-			b.BranchID,
-			productType,
-			updatedAfter,
-			updatedBefore,
-		)
+		cp, err := checkpoints.Get(ctx, b.BranchID)
+		if err != nil {
+			return fmt.Errorf("get products checkpoint for %s: %w", b.BranchID, err)
+		}
+		resumed := false
+		switch {
+		case cp == nil:
+		case cp.ProductType != productType || cp.Reconcile != reconcile:
+			lg.Printf("⚠️  %s: discarding products checkpoint from a different listing (type=%q, reconcile=%t)",
+				b.BranchID, cp.ProductType, cp.Reconcile)
+			cp = nil
+		case time.Since(cp.UpdatedAt) > productsCheckpointMaxAge:
+			lg.Printf("⚠️  %s: discarding products checkpoint last written %s", b.BranchID, cp.UpdatedAt.Format(time.RFC3339))
+			cp = nil
+		default:
+			resumed = true
+			lg.Printf("▶️  %s: resuming products sync at page %d (%d product(s) already written since %s)",
+				b.BranchID, cp.NextPage, cp.Processed, cp.StartedAt.Format(time.RFC3339))
+		}
+
+		if cp == nil {
+			cp = &models.ProductSyncCheckpoint{
+				BranchID:    b.BranchID,
+				ProductType: productType,
+				Reconcile:   reconcile,
+				StartedAt:   time.Now().UTC(),
+			}
+			switch {
+			case reconcile:
+				lg.Printf("🧹 %s: products reconcile → full listing (no date filters)", b.BranchID)
+			case wm != nil:
+				// Overlap the watermark so late edits are re-pulled
+				after := wm.UTC().Add(-r.Cfg.OverlapFor("products_api").Overlap)
+				now := time.Now().UTC()
+				cp.UpdatedAfter = &after
+				cp.UpdatedBefore = &now
+				lg.Printf("   %s: using incremental window updatedAfter=%s, updatedBefore=%s",
+					b.BranchID, after.Format(time.RFC3339), now.Format(time.RFC3339))
+			default:
+				lg.Printf("   %s: no products watermark → full sync (no date filters)", b.BranchID)
+			}
+		}
+		cp.RunID = r.RunID
+
+		listing, err := r.syncProductsForBranch(ctx, pc, cp)
 		if err != nil {
 			return fmt.Errorf("sync products for branch %s (%s): %w", b.Name, b.BranchID, err)
 		}
 
-		if maxUpdatedAt != nil {
-			if err := watermarks.UpsertLastUpdated("products_api", b.BranchID, *maxUpdatedAt); err != nil {
-				return fmt.Errorf("update products_api watermark for %s: %w", b.BranchID, err)
+		if listing.maxUpdatedAt != nil {
+			if err := watermarks.UpsertLastUpdated("products_api", b.BranchID, *listing.maxUpdatedAt); err != nil {
+				return Partial(fmt.Errorf("update products_api watermark for %s: %w", b.BranchID, err))
+			}
+		}
+		if err := checkpoints.Delete(ctx, b.BranchID); err != nil {
+			// Harmless: the next run resumes past the last page and finishes at once.
+			lg.Printf("⚠️  failed to clear products checkpoint for %s: %v", b.BranchID, err)
+		}
+
+		if reconcile && resumed {
+			// The pages written before the interruption aren't in listing.seen.
+			lg.Printf("⚠️  %s: products reconcile resumed mid-listing; skipping removal marking until the next full reconcile", b.BranchID)
+			reconcile = false
+		}
+
+		if reconcile {
+			if err := r.reconcileProducts(ctx, stockRepo, changeRepo, b.BranchID, listing); err != nil {
+				return Partial(err)
+			}
+			if err := watermarks.UpsertLastUpdated(productsReconcileEntity, b.BranchID, time.Now().UTC()); err != nil {
+				lg.Printf("⚠️  failed to record products reconcile for %s: %v", b.BranchID, err)
 			}
 		}
+
+		if r.Cfg.ReorderNotify {
+			r.notifyReorder(ctx, b)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
 	lg.Println("✅ PRODUCTS sync complete for all branches.")
 	return nil
 }
 
-// syncProductsForBranch does the paging + upserts for a single branch.
-// It returns the maximum UpdatedAt timestamp from Phorest for this run.
+// productsReconcileDue reports whether this branch sync should be a full
+// reconcile: always for ProductStrategyReconcile, otherwise once
+// Cfg.ProductsReconcileEvery has passed since the last one.
+func (r *Runner) productsReconcileDue(strategy ProductStrategy, branchID string) (bool, error) {
+	if strategy == ProductStrategyReconcile {
+		return true, nil
+	}
+	if r.Cfg.ProductsReconcileEvery <= 0 {
+		return false, nil
+	}
+	last, err := r.watermarks().GetLastUpdated(productsReconcileEntity, branchID)
+	if err != nil {
+		return false, fmt.Errorf("get products reconcile time for %s: %w", branchID, err)
+	}
+	if last == nil {
+		// Only start the schedule once there's something to reconcile
+		// against; the first full sync is a complete listing anyway.
+		wm, err := r.watermarks().GetLastUpdated("products_api", branchID)
+		return wm != nil, err
+	}
+	return time.Since(*last) >= r.Cfg.ProductsReconcileEvery, nil
+}
+
+// productsCheckpointMaxAge is how long an interrupted products sync can be
+// resumed. Older checkpoints are discarded and the branch starts over, as
+// Phorest's paging may have shifted too far since.
+const productsCheckpointMaxAge = 24 * time.Hour
+
+// productListing is what one branch's walk of the products API saw.
+type productListing struct {
+	maxUpdatedAt  *time.Time
+	seen          map[string]struct{} // product IDs returned
+	totalElements int                 // page.totalElements of the first page
+}
+
+// syncProductsForBranch does the paging + upserts for a single branch,
+// starting at cp.NextPage with cp's window and advancing cp as each page
+// commits. It returns what the listing contained (including the maximum
+// UpdatedAt from Phorest, carried over from earlier pages when resuming)
+// and counts fetched vs actually-changed stock rows in the run report.
+// Errors after at least one product was written are marked Partial.
 func (r *Runner) syncProductsForBranch(
 	ctx context.Context,
 	pc *ProductsClient,
-	productRepo *repos.PhProductRepo,
-	stockRepo *repos.PhProductStockRepo,
-	branchID string,
-	productType string,
-	updatedAfter *time.Time,
-	updatedBefore *time.Time,
-) (*time.Time, error) {
-	page := 0
+	cp *models.ProductSyncCheckpoint,
+) (*productListing, error) {
 	size := 100
+	first := cp.NextPage
 
-	var maxUpdatedAt *time.Time
+	listing := &productListing{seen: map[string]struct{}{}, maxUpdatedAt: cp.MaxUpdatedAt}
+	processed := 0
+	var changed int64
+	defer func() { r.count(SyncKindProducts, cp.BranchID, int64(processed), changed) }()
 
 	for {
+		page := cp.NextPage
 		resp, err := pc.ListProducts(ctx, ListProductsOptions{
-			BranchID:      branchID,
-			ProductType:   productType,
-			UpdatedAfter:  updatedAfter,
-			UpdatedBefore: updatedBefore,
+			BranchID:      cp.BranchID,
+			ProductType:   cp.ProductType,
+			UpdatedAfter:  cp.UpdatedAfter,
+			UpdatedBefore: cp.UpdatedBefore,
 			Page:          page,
 			Size:          size,
 		})
 		if err != nil {
-			return nil, err
+			return nil, partialAfter(processed, err)
+		}
+		if page == first {
+			listing.totalElements = resp.Page.TotalElements
 		}
 
 		if len(resp.Embedded.Products) == 0 {
 			break
 		}
 
-		for _, pp := range resp.Embedded.Products {
-			if err := r.processProductRecord(ctx, productRepo, stockRepo, branchID, pp); err != nil {
-				return nil, err
-			}
+		res, err := r.syncProductPage(ctx, cp, resp.Page.TotalPages, resp.Embedded.Products)
+		if err != nil {
+			return nil, partialAfter(processed, fmt.Errorf("page %d: %w", page, err))
+		}
+		processed += len(resp.Embedded.Products)
+		changed += res.changed
+		for _, n := range res.belowMin {
+			r.notifyStockBelowMin(ctx, n.pp, n.stock)
+		}
 
-			// Track max UpdatedAt from Phorest
-			if maxUpdatedAt == nil || pp.UpdatedAt.After(*maxUpdatedAt) {
-				t := pp.UpdatedAt
-				maxUpdatedAt = &t
-			}
+		for _, pp := range resp.Embedded.Products {
+			listing.seen[pp.ProductID] = struct{}{}
 		}
+		listing.maxUpdatedAt = cp.MaxUpdatedAt
 
-		page++
-		if resp.Page.TotalPages > 0 && page >= resp.Page.TotalPages {
+		if resp.Page.TotalPages > 0 && cp.NextPage >= resp.Page.TotalPages {
 			break
 		}
 	}
 
-	return maxUpdatedAt, nil
+	return listing, nil
 }
 
-// processProductRecord maps a single PhorestProduct into:
-//   - ph_products (master)
-//   - ph_product_stock (current state per branch)
-//   - ph_product_stock_history (time series when quantity changes)
-func (r *Runner) processProductRecord(
+// reconcileProducts marks the branch's stored products that a full,
+// unfiltered listing didn't return as removed. It refuses to when the
+// listing looks unreliable: empty, or fewer distinct products than
+// page.totalElements promised (products moved between pages mid-walk).
+func (r *Runner) reconcileProducts(
 	ctx context.Context,
-	productRepo *repos.PhProductRepo,
 	stockRepo *repos.PhProductStockRepo,
+	changeRepo *repos.ProductChangesRepo,
 	branchID string,
-	pp PhorestProduct,
+	listing *productListing,
 ) error {
-	// --- Upsert product master ---
-	product := &models.PhProduct{
-		ID:       pp.ProductID,
-		Name:     pp.Name,
-		Archived: pp.Archived,
-	}
+	lg := r.Logger
 
-	if pp.ParentProductID != "" {
-		product.ParentID = &pp.ParentProductID
-	}
-	if pp.BrandID != "" {
-		product.BrandID = &pp.BrandID
+	stored, err := stockRepo.ListLiveProductIDs(ctx, branchID)
+	if err != nil {
+		return fmt.Errorf("list stored products for %s: %w", branchID, err)
 	}
-	if pp.BrandName != "" {
-		product.BrandName = &pp.BrandName
+	if len(listing.seen) == 0 && len(stored) > 0 {
+		lg.Printf("⚠️  %s: Phorest returned no products but %d are stored; skipping removal marking", branchID, len(stored))
+		return nil
 	}
-	if pp.CategoryID != "" {
-		product.CategoryID = &pp.CategoryID
+	if len(listing.seen) != listing.totalElements {
+		return fmt.Errorf("products reconcile for %s: saw %d distinct products, page.totalElements=%d; not marking removals",
+			branchID, len(listing.seen), listing.totalElements)
 	}
-	if pp.CategoryName != "" {
-		product.CategoryName = &pp.CategoryName
+
+	var missing []string
+	for _, id := range stored {
+		if _, ok := listing.seen[id]; !ok {
+			missing = append(missing, id)
+		}
 	}
-	if pp.Code != "" {
-		product.Code = &pp.Code
+	if len(missing) == 0 {
+		lg.Printf("✅ %s: products reconcile found nothing missing (%d stored, %d in Phorest)", branchID, len(stored), listing.totalElements)
+		return nil
 	}
-	if pp.Type != "" {
-		product.TypeRaw = &pp.Type
+
+	now := time.Now().UTC()
+	marked, err := stockRepo.MarkRemoved(ctx, branchID, missing, now)
+	if err != nil {
+		return fmt.Errorf("mark removed products for %s: %w", branchID, err)
 	}
-	if pp.MeasurementQuantity != 0 {
-		v := pp.MeasurementQuantity
-		product.MeasurementQty = &v
+	ts := now.Format(time.RFC3339)
+	changes := make([]models.ProductChange, 0, len(marked))
+	for _, id := range marked {
+		changes = append(changes, models.ProductChange{
+			Entity:    models.ProductChangeStock,
+			ProductID: id,
+			BranchID:  branchID,
+			Field:     "removed_at",
+			NewValue:  &ts,
+			ChangedAt: now,
+		})
 	}
-	if pp.MeasurementUnit != "" {
-		product.MeasurementUnit = &pp.MeasurementUnit
+	if err := changeRepo.Insert(ctx, changes); err != nil {
+		return fmt.Errorf("log removed products for %s: %w", branchID, err)
 	}
+	r.count(SyncKindProducts, branchID, 0, int64(len(marked)))
+	lg.Printf("🗑️  %s: marked %d product(s) removed (no longer in Phorest)", branchID, len(marked))
+	return nil
+}
 
-	product.CreatedAtPh = &pp.CreatedAt
-	product.UpdatedAtPh = &pp.UpdatedAt
-
-	if err := productRepo.Upsert(ctx, product); err != nil {
-		return err
-	}
+// productPage is the outcome of writing one API page.
+type productPage struct {
+	changed  int64             // stock rows new or different
+	belowMin []productBelowMin // live products that just reached their minimum
+}
 
-	// --- Upsert current stock row ---
+type productBelowMin struct {
+	pp    PhorestProduct
+	stock *models.PhProductStock
+}
 
-	newStock := &models.PhProductStock{
-		ProductID:   pp.ProductID,
-		BranchID:    branchID,
-		Archived:    pp.Archived,
-		CreatedAtPh: &pp.CreatedAt,
-		UpdatedAtPh: &pp.UpdatedAt,
+// syncProductPage writes one API page in a single transaction:
+//   - ph_products (master)
+//   - ph_product_stock (current state per branch)
+//   - ph_product_stock_history (time series when quantity changes)
+//   - product_changes (every changed field of an existing product/stock row)
+//   - product_sync_checkpoints (cp advanced past this page)
+//
+// Existing rows are loaded in one query per table and compared in memory,
+// so a page costs a handful of round trips however many products it holds.
+// Below-minimum notifications are returned for sending after the commit;
+// cp is only updated once the page has committed.
+func (r *Runner) syncProductPage(ctx context.Context, cp *models.ProductSyncCheckpoint, totalPages int, products []PhorestProduct) (*productPage, error) {
+	branchID := cp.BranchID
+	next := *cp
+	next.NextPage++
+	next.TotalPages = totalPages
+	next.Processed += len(products)
+	for _, pp := range products {
+		// Track max UpdatedAt from Phorest
+		if next.MaxUpdatedAt == nil || pp.UpdatedAt.After(*next.MaxUpdatedAt) {
+			t := pp.UpdatedAt
+			next.MaxUpdatedAt = &t
+		}
 	}
 
-	if pp.Price != 0 {
-		v := pp.Price
-		newStock.Price = &v
+	// A product listed twice on a page (it moved mid-walk) keeps its last version.
+	idx := make(map[string]int, len(products))
+	var page []PhorestProduct
+	for _, pp := range products {
+		if i, ok := idx[pp.ProductID]; ok {
+			page[i] = pp
+			continue
+		}
+		idx[pp.ProductID] = len(page)
+		page = append(page, pp)
 	}
-	if pp.MinQuantity != 0 {
-		v := pp.MinQuantity
-		newStock.MinQuantity = &v
+	ids := make([]string, len(page))
+	for i, pp := range page {
+		ids[i] = pp.ProductID
 	}
-	if pp.MaxQuantity != 0 {
-		v := pp.MaxQuantity
-		newStock.MaxQuantity = &v
+
+	out := &productPage{}
+	now := time.Now().UTC()
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		productRepo := repos.NewPhProductRepo(tx)
+		stockRepo := repos.NewPhProductStockRepo(tx)
+		changeRepo := repos.NewProductChangesRepo(tx).ForRun(r.RunID)
+
+		oldProducts, err := productRepo.GetMany(ctx, ids)
+		if err != nil {
+			return fmt.Errorf("load products: %w", err)
+		}
+		oldStock, err := stockRepo.GetManyForBranch(ctx, branchID, ids)
+		if err != nil {
+			return fmt.Errorf("load stock: %w", err)
+		}
+
+		products := make([]models.PhProduct, 0, len(page))
+		stock := make([]models.PhProductStock, 0, len(page))
+		var history []models.PhProductStockHistory
+		var changes []models.ProductChange
+
+		for _, pp := range page {
+			product, st := productModels(pp, branchID)
+			existing := oldStock[pp.ProductID]
+
+			changes = append(changes, repos.ProductDiff(oldProducts[pp.ProductID], product, branchID, now)...)
+			changes = append(changes, repos.StockDiff(existing, st, now)...)
+			if stockChanged(existing, st) {
+				out.changed++
+			}
+			// A product back after being marked removed needs a fresh
+			// snapshot even at its old quantity: the latest history row is
+			// the zero one MarkRemoved wrote.
+			if existing == nil || existing.RemovedAt != nil || !eqFloat(existing.QuantityInStock, st.QuantityInStock) {
+				history = append(history, models.PhProductStockHistory{
+					ProductID:       pp.ProductID,
+					BranchID:        branchID,
+					QuantityInStock: st.QuantityInStock,
+					Price:           st.Price,
+					Source:          "sync",
+					SnapshotTime:    now,
+				})
+			}
+			if fellBelowMin(existing, st) {
+				out.belowMin = append(out.belowMin, productBelowMin{pp: pp, stock: st})
+			}
+			products = append(products, *product)
+			stock = append(stock, *st)
+		}
+
+		if err := productRepo.UpsertMany(ctx, products); err != nil {
+			return fmt.Errorf("upsert products: %w", err)
+		}
+		if err := stockRepo.UpsertMany(ctx, stock); err != nil {
+			return fmt.Errorf("upsert stock: %w", err)
+		}
+		if err := stockRepo.InsertHistoryMany(ctx, history); err != nil {
+			return fmt.Errorf("insert stock history: %w", err)
+		}
+		if err := changeRepo.Insert(ctx, changes); err != nil {
+			return fmt.Errorf("insert product changes: %w", err)
+		}
+		if err := repos.NewProductSyncCheckpointsRepo(tx).Save(ctx, &next); err != nil {
+			return fmt.Errorf("save checkpoint: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	// quantity can be 0 and that’s meaningful, so always store it
-	{
-		v := pp.QuantityInStock
-		newStock.QuantityInStock = &v
+	*cp = next
+	return out, nil
+}
+
+// productModels maps a PhorestProduct to its ph_products and branch
+// ph_product_stock rows. Zero values Phorest sends for unset fields are
+// stored as NULL, except the quantity, where 0 is meaningful.
+func productModels(pp PhorestProduct, branchID string) (*models.PhProduct, *models.PhProductStock) {
+	product := &models.PhProduct{
+		ID:              pp.ProductID,
+		Name:            pp.Name,
+		Archived:        pp.Archived,
+		ParentID:        optString(pp.ParentProductID),
+		BrandID:         optString(pp.BrandID),
+		BrandName:       optString(pp.BrandName),
+		CategoryID:      optString(pp.CategoryID),
+		CategoryName:    optString(pp.CategoryName),
+		Code:            optString(pp.Code),
+		TypeRaw:         optString(pp.Type),
+		MeasurementQty:  optFloat(pp.MeasurementQuantity),
+		MeasurementUnit: optString(pp.MeasurementUnit),
+		CreatedAtPh:     &pp.CreatedAt,
+		UpdatedAtPh:     &pp.UpdatedAt,
 	}
-	if pp.ReorderCount != 0 {
-		v := pp.ReorderCount
-		newStock.ReorderCount = &v
+
+	qty := pp.QuantityInStock
+	stock := &models.PhProductStock{
+		ProductID:       pp.ProductID,
+		BranchID:        branchID,
+		Price:           optFloat(pp.Price),
+		MinQuantity:     optFloat(pp.MinQuantity),
+		MaxQuantity:     optFloat(pp.MaxQuantity),
+		QuantityInStock: &qty,
+		ReorderCount:    optFloat(pp.ReorderCount),
+		ReorderCost:     optFloat(pp.ReorderCost),
+		Archived:        pp.Archived,
+		CreatedAtPh:     &pp.CreatedAt,
+		UpdatedAtPh:     &pp.UpdatedAt,
 	}
-	if pp.ReorderCost != 0 {
-		v := pp.ReorderCost
-		newStock.ReorderCost = &v
+	return product, stock
+}
+
+func optString(s string) *string {
+	if s == "" {
+		return nil
 	}
+	return &s
+}
 
-	existing, err := stockRepo.GetByProductAndBranch(ctx, pp.ProductID, branchID)
-	if err != nil {
-		return err
+func optFloat(v float64) *float64 {
+	if v == 0 {
+		return nil
 	}
+	return &v
+}
 
-	// Upsert current state
-	if err := stockRepo.Upsert(ctx, newStock); err != nil {
-		return err
+// stockChanged reports whether a stock row is new or any synced field differs.
+func stockChanged(old, cur *models.PhProductStock) bool {
+	if old == nil {
+		return true
 	}
+	return !eqFloat(old.Price, cur.Price) ||
+		!eqFloat(old.MinQuantity, cur.MinQuantity) ||
+		!eqFloat(old.MaxQuantity, cur.MaxQuantity) ||
+		!eqFloat(old.QuantityInStock, cur.QuantityInStock) ||
+		!eqFloat(old.ReorderCount, cur.ReorderCount) ||
+		!eqFloat(old.ReorderCost, cur.ReorderCost) ||
+		old.Archived != cur.Archived ||
+		old.RemovedAt != nil
+}
 
-	// --- History logging when quantity changes (or first time) ---
+// fellBelowMin reports whether a live product just reached its minimum stock
+// level. New rows don't count, so a first sync doesn't announce every
+// product that is already low.
+func fellBelowMin(old, cur *models.PhProductStock) bool {
+	if old == nil || cur.Archived || !belowMin(cur) {
+		return false
+	}
+	return !belowMin(old) || old.Archived
+}
 
-	shouldLogHistory := false
-	var oldQty *float64
+func belowMin(s *models.PhProductStock) bool {
+	return s.MinQuantity != nil && *s.MinQuantity > 0 &&
+		s.QuantityInStock != nil && *s.QuantityInStock <= *s.MinQuantity
+}
 
-	if existing == nil {
-		shouldLogHistory = true
-	} else {
-		oldQty = existing.QuantityInStock
-		newQty := newStock.QuantityInStock
-		if (oldQty == nil && newQty != nil) ||
-			(oldQty != nil && newQty == nil) ||
-			(oldQty != nil && newQty != nil && *oldQty != *newQty) {
-			shouldLogHistory = true
-		}
+func (r *Runner) notifyStockBelowMin(ctx context.Context, pp PhorestProduct, st *models.PhProductStock) {
+	sev := notify.SeverityWarning
+	if *st.QuantityInStock <= 0 {
+		sev = notify.SeverityCritical
+	}
+	data := map[string]any{
+		"product_id":        pp.ProductID,
+		"product_name":      pp.Name,
+		"brand_name":        pp.BrandName,
+		"quantity_in_stock": *st.QuantityInStock,
+		"min_quantity":      *st.MinQuantity,
+	}
+	if st.MaxQuantity != nil {
+		data["max_quantity"] = *st.MaxQuantity
 	}
+	r.notify(ctx, notify.Event{
+		Type:     notify.EventStockBelowMin,
+		Severity: sev,
+		BranchID: st.BranchID,
+		Title:    fmt.Sprintf("%s is down to %g (minimum %g)", pp.Name, *st.QuantityInStock, *st.MinQuantity),
+		Data:     data,
+	})
+}
 
-	if shouldLogHistory {
-		h := &models.PhProductStockHistory{
-			ProductID:       pp.ProductID,
-			BranchID:        branchID,
-			QuantityInStock: newStock.QuantityInStock,
-			Source:          "sync",
-			SnapshotTime:    time.Now(),
-		}
-		if newStock.Price != nil {
-			v := *newStock.Price
-			h.Price = &v
-		}
+// notifyReorder publishes the branch's reorder list (if anything needs
+// ordering). The same list is sent at most once a day.
+func (r *Runner) notifyReorder(ctx context.Context, b config.BranchConfig) {
+	rep, err := services.NewStockService(r.DB, r.Logger).Reorder(ctx, b.BranchID)
+	if err != nil {
+		r.Logger.Printf("⚠️  %s: reorder list failed: %v", b.BranchID, err)
+		return
+	}
+	if rep.Lines == 0 {
+		return
+	}
 
-		if err := stockRepo.InsertHistory(ctx, h); err != nil {
-			return err
+	h := sha256.New()
+	brands := make([]map[string]any, 0, len(rep.Brands))
+	lines := make([]map[string]any, 0, rep.Lines)
+	for _, br := range rep.Brands {
+		brands = append(brands, map[string]any{
+			"brand_name": br.BrandName,
+			"lines":      len(br.Lines),
+			"units":      br.Units,
+			"cost":       br.Cost,
+		})
+		for _, l := range br.Lines {
+			fmt.Fprintf(h, "%s:%g;", l.ProductID, l.SuggestedQty)
+			lines = append(lines, map[string]any{
+				"product_id":        l.ProductID,
+				"product_name":      l.ProductName,
+				"brand_name":        l.BrandName,
+				"quantity_in_stock": l.QuantityInStock,
+				"suggested_qty":     l.SuggestedQty,
+				"line_cost":         l.LineCost,
+			})
 		}
 	}
 
-	return nil
+	r.notify(ctx, notify.Event{
+		Type:     notify.EventStockReorder,
+		Severity: notify.SeverityInfo,
+		BranchID: b.BranchID,
+		Title:    fmt.Sprintf("%s: %d product(s) to reorder", b.Name, rep.Lines),
+		Body: fmt.Sprintf("%g unit(s) across %d brand(s), estimated cost %.2f (%d line(s) have no reorder cost).",
+			rep.Units, len(rep.Brands), rep.Cost, rep.Uncosted),
+		Data: map[string]any{
+			"lines":    rep.Lines,
+			"units":    rep.Units,
+			"cost":     rep.Cost,
+			"uncosted": rep.Uncosted,
+			"brands":   brands,
+			"items":    lines,
+		},
+		Key: fmt.Sprintf("%s|%s|%s|%x", notify.EventStockReorder, b.BranchID,
+			time.Now().UTC().Format("2006-01-02"), h.Sum(nil)[:8]),
+	})
+}
+
+func eqFloat(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
 }
@@ -7,6 +7,8 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/araquach/phorest-datahub/internal/models"
@@ -67,42 +69,82 @@ func NewReviewsClient(user, pass, business string) *ReviewsClient {
 	}
 }
 
-// FetchReviews fetches reviews for a branch.
-// If sinceDate is non-empty (YYYY-MM-DD), it will use it as a lower bound on reviewDate (if supported).
-func (c *ReviewsClient) FetchReviews(branchID, sinceDate string, page, size int) ([]models.Review, int, error) {
+// ReviewQuery filters and pages a review listing. From/To bound reviewDate
+// (inclusive, by day); nil leaves that side open.
+type ReviewQuery struct {
+	From *time.Time
+	To   *time.Time
+	Page int
+	Size int
+}
+
+// ReviewPage is one page of a review listing plus Phorest's paging totals.
+// Fetched counts the rows the API returned before the window filter, so an
+// empty Reviews with Fetched > 0 is a page outside the window, not the end.
+// Older counts the dropped rows dated before From.
+type ReviewPage struct {
+	Reviews       []models.Review
+	Fetched       int
+	Older         int
+	TotalPages    int
+	TotalElements int
+}
+
+// Query parameter names for the reviewDate range on the review list
+// endpoint. They follow the appointment list's YYYY-MM-DD from_date/to_date
+// convention but are not confirmed for reviews, so callers must not rely on
+// the API filtering by date: ListReviews filters client-side and the syncer
+// stops on a page entirely older than the window (the listing is newest
+// first) or after maxSkippedReviewPages out-of-window pages in a row.
+const (
+	reviewFromParam = "from_date"
+	reviewToParam   = "to_date"
+)
+
+// ListReviews fetches one page of reviews for a branch. Rows outside
+// [From, To] are dropped client-side as well, so a window is honoured even
+// if the endpoint ignores the date parameters.
+func (c *ReviewsClient) ListReviews(ctx context.Context, branchID string, q ReviewQuery) (*ReviewPage, error) {
 	// Paging params (Phorest list endpoints are usually size/page based)
-	if size <= 0 {
-		size = 200
+	if q.Size <= 0 {
+		q.Size = 200
 	}
-	url := fmt.Sprintf("%s/business/%s/branch/%s/review?size=%d&page=%d",
-		c.BaseURL, c.Business, branchID, size, page)
-
-	// (If the endpoint supports filtering by reviewDate, you can append `&reviewDateStart=%s`)
+	params := url.Values{}
+	params.Set("size", strconv.Itoa(q.Size))
+	params.Set("page", strconv.Itoa(q.Page))
+	if q.From != nil {
+		params.Set(reviewFromParam, q.From.UTC().Format("2006-01-02"))
+	}
+	if q.To != nil {
+		params.Set(reviewToParam, q.To.UTC().Format("2006-01-02"))
+	}
+	u := fmt.Sprintf("%s/business/%s/branch/%s/review?%s",
+		c.BaseURL, c.Business, branchID, params.Encode())
 
-	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Second)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 	req.SetBasicAuth(c.User, c.Pass)
 	req.Header.Set("Accept", "application/json")
 
 	resp, err := c.HTTP.Do(req)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode > 299 {
 		b, _ := io.ReadAll(resp.Body)
-		return nil, 0, fmt.Errorf("phorest reviews %s: status %d: %s", branchID, resp.StatusCode, string(b))
+		return nil, &HTTPStatusError{Op: "phorest reviews " + branchID, StatusCode: resp.StatusCode, Body: string(b)}
 	}
 
 	var api reviewAPIResponse
 	if err := json.NewDecoder(resp.Body).Decode(&api); err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 
 	out := make([]models.Review, 0, len(api.Embedded.Reviews))
@@ -117,8 +159,9 @@ func (c *ReviewsClient) FetchReviews(branchID, sinceDate string, page, size int)
 		return &t
 	}
 
+	older := 0
 	for _, r := range api.Embedded.Reviews {
-		out = append(out, models.Review{
+		rv := models.Review{
 			ReviewID:        r.ReviewID,
 			BranchID:        branchID,
 			ClientID:        r.ClientID,
@@ -133,16 +176,35 @@ func (c *ReviewsClient) FetchReviews(branchID, sinceDate string, page, size int)
 			Rating:          r.Rating,
 			FacebookReview:  r.FacebookReview,
 			TwitterReview:   r.TwitterReview,
-		})
+		}
+		if !reviewInWindow(rv, q.From, q.To) {
+			if rv.ReviewDate != nil && q.From != nil && rv.ReviewDate.Format("2006-01-02") < q.From.UTC().Format("2006-01-02") {
+				older++
+			}
+			continue
+		}
+		out = append(out, rv)
 	}
-	return out, api.Page.TotalPages, nil
+	return &ReviewPage{
+		Reviews:       out,
+		Fetched:       len(api.Embedded.Reviews),
+		Older:         older,
+		TotalPages:    api.Page.TotalPages,
+		TotalElements: api.Page.TotalElements,
+	}, nil
 }
 
-func (c *ReviewsClient) FetchLatestN(branchID string, n int) ([]models.Review, error) {
-	if n <= 0 {
-		n = 10
+// reviewInWindow compares by day; reviews without a date are always kept.
+func reviewInWindow(rv models.Review, from, to *time.Time) bool {
+	if rv.ReviewDate == nil {
+		return true
+	}
+	d := rv.ReviewDate.Format("2006-01-02")
+	if from != nil && d < from.UTC().Format("2006-01-02") {
+		return false
+	}
+	if to != nil && d > to.UTC().Format("2006-01-02") {
+		return false
 	}
-	// page=0 with size=n
-	rows, _, err := c.FetchReviews(branchID, "", 0, n)
-	return rows, err
+	return true
 }
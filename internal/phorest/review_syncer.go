@@ -0,0 +1,432 @@
+package phorest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/araquach/phorest-datahub/internal/alerts"
+	"github.com/araquach/phorest-datahub/internal/analysis"
+	"github.com/araquach/phorest-datahub/internal/config"
+	"github.com/araquach/phorest-datahub/internal/models"
+	"github.com/araquach/phorest-datahub/internal/notify"
+	"github.com/araquach/phorest-datahub/internal/repos"
+)
+
+// ReviewStrategy selects which reviews a ReviewSyncer fetches.
+type ReviewStrategy string
+
+const (
+	// ReviewStrategyIncremental fetches from the reviews_api watermark (minus
+	// the configured overlap) up to today. This is the default.
+	ReviewStrategyIncremental ReviewStrategy = "incremental"
+	// ReviewStrategyFull walks every page without date filters.
+	ReviewStrategyFull ReviewStrategy = "full"
+	// ReviewStrategyLatest fetches only the newest N reviews per branch. It
+	// never moves the watermark, since it can skip over older gaps.
+	ReviewStrategyLatest ReviewStrategy = "latest"
+	// ReviewStrategyReconcile is a full walk that also marks stored reviews
+	// Phorest no longer returns as deleted.
+	ReviewStrategyReconcile ReviewStrategy = "reconcile"
+)
+
+// ReviewStrategies lists every strategy accepted by ParseReviewStrategy.
+var ReviewStrategies = []ReviewStrategy{
+	ReviewStrategyIncremental, ReviewStrategyFull, ReviewStrategyLatest, ReviewStrategyReconcile,
+}
+
+// ParseReviewStrategy maps a CLI/API value to a strategy; "" means incremental.
+func ParseReviewStrategy(s string) (ReviewStrategy, error) {
+	if s == "" {
+		return ReviewStrategyIncremental, nil
+	}
+	for _, st := range ReviewStrategies {
+		if string(st) == s {
+			return st, nil
+		}
+	}
+	return "", fmt.Errorf("unknown review strategy %q", s)
+}
+
+const (
+	reviewsPageSize       = 100
+	defaultLatestReviewsN = 10
+	// maxSkippedReviewPages caps consecutive pages with no in-window rows, so
+	// an API that ignores the date parameters doesn't turn an incremental
+	// run into a full walk.
+	maxSkippedReviewPages = 3
+)
+
+// ReviewSyncer is the single review sync path. Every strategy shares the same
+// paging, upsert, CSV backup, watermark and run-report bookkeeping; they only
+// differ in the date window and how many pages they read.
+type ReviewSyncer struct {
+	r        *Runner
+	client   *ReviewsClient
+	repo     *repos.ReviewsRepo
+	wm       *repos.WatermarksRepo
+	strategy ReviewStrategy
+	latestN  int
+}
+
+// NewReviewSyncer builds a syncer for strategy. latestN is only used by
+// ReviewStrategyLatest (<= 0 means 10).
+func (r *Runner) NewReviewSyncer(strategy ReviewStrategy, latestN int) *ReviewSyncer {
+	if latestN <= 0 {
+		latestN = defaultLatestReviewsN
+	}
+	return &ReviewSyncer{
+		r:        r,
+		client:   NewReviewsClient(r.Cfg.PhorestUsername, r.Cfg.PhorestPassword, r.Cfg.PhorestBusiness),
+		repo:     repos.NewReviewsRepo(r.DB, r.Logger).ForRun(r.RunID),
+		wm:       r.watermarks(),
+		strategy: strategy,
+		latestN:  latestN,
+	}
+}
+
+// SyncReviews runs the review sync for every configured branch with strategy,
+// then analyses any new or edited review text and re-checks rating alerts.
+// Both follow-ups are offline and their failure doesn't fail the sync.
+func (r *Runner) SyncReviews(ctx context.Context, strategy ReviewStrategy, latestN int) error {
+	err := r.NewReviewSyncer(strategy, latestN).Run(ctx)
+
+	if _, aerr := analysis.NewPipeline(r.DB, r.Logger).Run(ctx); aerr != nil {
+		r.Logger.Printf("⚠️  review analysis failed: %v", aerr)
+	}
+
+	branchIDs := make([]string, 0, len(r.Cfg.Branches))
+	for _, b := range r.Cfg.Branches {
+		branchIDs = append(branchIDs, b.BranchID)
+	}
+	monitor := alerts.NewRatingMonitor(r.DB, r.Logger)
+	monitor.Hooks = append(monitor.Hooks, alerts.NotifyHook(r.Notify))
+	if aerr := monitor.Run(ctx, branchIDs, time.Now().UTC()); aerr != nil {
+		r.Logger.Printf("⚠️  rating alerts failed: %v", aerr)
+	}
+	return err
+}
+
+// Run syncs every configured branch in parallel; a failing branch doesn't
+// stop the others.
+func (s *ReviewSyncer) Run(ctx context.Context) error {
+	lg := s.r.Logger
+	lg.Printf("▶️ Starting REVIEWS sync (strategy=%s)...", s.strategy)
+
+	err := s.r.forEachBranch(ctx, SyncKindReviews, s.syncBranch)
+	if err != nil {
+		return err
+	}
+
+	lg.Printf("✅ All branches REVIEWS sync finished (strategy=%s)", s.strategy)
+	return nil
+}
+
+// reviewWindow is what one branch sync should fetch.
+type reviewWindow struct {
+	from     *time.Time // nil = no lower bound
+	to       *time.Time // nil = no upper bound
+	maxRows  int        // 0 = every page
+	sweep    bool       // a look-back sweep is running; markSweep on success
+	advWM    bool       // move reviews_api to the newest review date seen
+	pageSize int
+}
+
+// window picks the date bounds for one branch. Only incremental syncs have
+// an upper bound (today, UTC); full and reconcile walks must see reviews
+// dated ahead of the UTC date too, or reconcile would mark them deleted.
+func (s *ReviewSyncer) window(branchID string) (reviewWindow, error) {
+	switch s.strategy {
+	case ReviewStrategyIncremental:
+		from, sweep, err := s.resumePoint(branchID)
+		if err != nil {
+			return reviewWindow{}, err
+		}
+		today := time.Now().UTC()
+		return reviewWindow{from: from, to: &today, sweep: sweep, advWM: true, pageSize: reviewsPageSize}, nil
+	case ReviewStrategyFull, ReviewStrategyReconcile:
+		return reviewWindow{advWM: true, pageSize: reviewsPageSize}, nil
+	case ReviewStrategyLatest:
+		return reviewWindow{maxRows: s.latestN, pageSize: s.latestN}, nil
+	}
+	return reviewWindow{}, Fatal(fmt.Errorf("unknown review strategy %q", s.strategy))
+}
+
+// syncBranch fetches one branch's reviews for its window, upserts them page by
+// page, archives a CSV backup of the rows seen and (where the strategy allows)
+// moves the reviews_api watermark to the newest review date.
+func (s *ReviewSyncer) syncBranch(ctx context.Context, b config.BranchConfig) (err error) {
+	lg := s.r.Logger
+	branchID := b.BranchID
+
+	// Once a page has been upserted, any later failure is a partial sync.
+	pagesSaved := 0
+	defer func() {
+		if err != nil && pagesSaved > 0 {
+			err = Partial(err)
+		}
+	}()
+
+	lg.Printf("🏢 Branch %s (%s): starting REVIEWS sync (strategy=%s)", b.Name, branchID, s.strategy)
+
+	win, err := s.window(branchID)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+
+	switch {
+	case win.maxRows > 0:
+		lg.Printf("ℹ️ %s: fetching latest %d reviews", branchID, win.maxRows)
+	case win.from != nil && win.to != nil:
+		lg.Printf("ℹ️ %s: fetching reviews %s..%s", branchID, win.from.Format("2006-01-02"), win.to.Format("2006-01-02"))
+	case win.from != nil:
+		lg.Printf("ℹ️ %s: fetching reviews from %s", branchID, win.from.Format("2006-01-02"))
+	default:
+		lg.Printf("ℹ️ %s: fetching all reviews (full walk)", branchID)
+	}
+
+	var seen []models.Review
+	var latestInRun *time.Time
+	var changed int64
+	skipped := 0
+
+	for page := 0; ; page++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		res, err := s.client.ListReviews(ctx, branchID, ReviewQuery{From: win.from, To: win.to, Page: page, Size: win.pageSize})
+		if err != nil {
+			return fmt.Errorf("fetch reviews branch=%s page=%d: %w", branchID, page, err)
+		}
+		if res.Fetched == 0 {
+			break
+		}
+		rows := res.Reviews
+		if win.maxRows > 0 && len(seen)+len(rows) > win.maxRows {
+			rows = rows[:win.maxRows-len(seen)]
+		}
+		if len(rows) == 0 {
+			// Every row on this page was outside the window, so the API
+			// didn't apply the date parameters. The listing is newest first:
+			// a page wholly older than the window ends it.
+			if res.Older == res.Fetched {
+				lg.Printf("   %s: page=%d is older than the window; stopping", branchID, page+1)
+				break
+			}
+			skipped++
+			if skipped >= maxSkippedReviewPages {
+				lg.Printf("⚠️  %s: %d pages in a row outside the window (date filter ignored?); stopping at page %d of %d",
+					branchID, skipped, page+1, res.TotalPages)
+				break
+			}
+			if page+1 >= res.TotalPages {
+				break
+			}
+			continue
+		}
+		skipped = 0
+
+		st, err := s.repo.UpsertMany(rows)
+		if err != nil {
+			return fmt.Errorf("upsert reviews branch=%s page=%d: %w", branchID, page, err)
+		}
+		pagesSaved++
+		changed += st.Changed()
+		seen = append(seen, rows...)
+		s.notifyLowReviews(ctx, rows, st.NewIDs, now)
+
+		for i := range rows {
+			if d := rows[i].ReviewDate; d != nil {
+				// NOTE: ReviewDate is a *date*, but we keep it as midnight UTC.
+				t := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC)
+				latestInRun = maxTime(latestInRun, &t)
+			}
+		}
+
+		lg.Printf("   %s: page=%d/%d rows=%d new=%d updated=%d", branchID, page+1, res.TotalPages, len(rows), st.Inserted, st.Updated)
+
+		if page+1 >= res.TotalPages || (win.maxRows > 0 && len(seen) >= win.maxRows) {
+			break
+		}
+	}
+
+	s.r.count(SyncKindReviews, branchID, int64(len(seen)), changed)
+
+	if win.sweep {
+		defer func() {
+			if err == nil {
+				s.r.markSweep("reviews_api", branchID)
+			}
+		}()
+	}
+
+	if s.strategy == ReviewStrategyReconcile {
+		if err := s.reconcile(branchID, seen); err != nil {
+			return err
+		}
+	}
+
+	if len(seen) == 0 {
+		lg.Printf("✅ %s: no reviews in window; nothing to archive", branchID)
+		return nil
+	}
+
+	if changed > 0 {
+		if err := s.archiveCSV(branchID, seen); err != nil {
+			return err
+		}
+	}
+
+	// Update watermark if we actually saw newer review dates
+	if win.advWM && latestInRun != nil {
+		if err := s.wm.UpsertLastUpdated("reviews_api", branchID, *latestInRun); err != nil {
+			return fmt.Errorf("update reviews_api watermark for %s: %w", branchID, err)
+		}
+	}
+
+	lg.Printf("✅ %s: REVIEWS sync finished (%d rows seen, %d new or updated)", branchID, len(seen), changed)
+	return nil
+}
+
+// lowReviewMaxAge limits low-review notifications to recent reviews, so a
+// first full walk doesn't announce years of history.
+const lowReviewMaxAge = 7 * 24 * time.Hour
+
+// notifyLowReviews publishes a review.low event for each newly stored 1–2 star
+// review dated within lowReviewMaxAge.
+func (s *ReviewSyncer) notifyLowReviews(ctx context.Context, rows []models.Review, newIDs []string, now time.Time) {
+	if len(newIDs) == 0 {
+		return
+	}
+	isNew := make(map[string]bool, len(newIDs))
+	for _, id := range newIDs {
+		isNew[id] = true
+	}
+	for i := range rows {
+		rv := &rows[i]
+		if !isNew[rv.ReviewID] || rv.Rating < 1 || rv.Rating > 2 ||
+			rv.ReviewDate == nil || now.Sub(*rv.ReviewDate) > lowReviewMaxAge {
+			continue
+		}
+		sev := notify.SeverityWarning
+		if rv.Rating == 1 {
+			sev = notify.SeverityCritical
+		}
+		s.r.notify(ctx, notify.Event{
+			Type:     notify.EventLowReview,
+			Severity: sev,
+			BranchID: rv.BranchID,
+			StaffID:  rv.StaffID,
+			Title: fmt.Sprintf("%d-star review for %s %s", rv.Rating,
+				rv.StaffFirstName, rv.StaffLastName),
+			Body: rv.Text,
+			Data: map[string]any{
+				"review_id":   rv.ReviewID,
+				"rating":      rv.Rating,
+				"review_date": rv.ReviewDate.Format("2006-01-02"),
+				"client_id":   rv.ClientID,
+			},
+			Key: notify.EventLowReview + "|" + rv.ReviewID,
+		})
+	}
+}
+
+// reconcile marks stored reviews that Phorest no longer returns as deleted.
+// It only runs after a complete walk, so "not seen" really means "gone".
+func (s *ReviewSyncer) reconcile(branchID string, seen []models.Review) error {
+	stored, err := s.repo.ListReviewIDs(branchID)
+	if err != nil {
+		return fmt.Errorf("list stored reviews for %s: %w", branchID, err)
+	}
+	if len(seen) == 0 && len(stored) > 0 {
+		// An empty listing is far more likely an API problem than every
+		// review being removed; don't wipe the branch.
+		s.r.Logger.Printf("⚠️  %s: Phorest returned no reviews but %d are stored; skipping deletion marking", branchID, len(stored))
+		return nil
+	}
+
+	inAPI := make(map[string]struct{}, len(seen))
+	for i := range seen {
+		inAPI[seen[i].ReviewID] = struct{}{}
+	}
+	var missing []string
+	for _, id := range stored {
+		if _, ok := inAPI[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+
+	if len(missing) > 0 {
+		n, err := s.repo.MarkDeleted(branchID, missing)
+		if err != nil {
+			return fmt.Errorf("mark deleted reviews for %s: %w", branchID, err)
+		}
+		s.r.count(SyncKindReviews, branchID, 0, n)
+		s.r.Logger.Printf("🗑️  %s: marked %d review(s) deleted (no longer in Phorest)", branchID, n)
+	} else {
+		s.r.Logger.Printf("✅ %s: reconcile found no missing reviews (%d stored, %d in Phorest)", branchID, len(stored), len(seen))
+	}
+	return nil
+}
+
+// resumePoint returns the start of the incremental window: the reviews_api
+// watermark (or, before one exists, the newest stored review) minus the
+// configured overlap. nil means no history yet → full walk.
+func (s *ReviewSyncer) resumePoint(branchID string) (*time.Time, bool, error) {
+	wm, err := s.wm.GetLastUpdated("reviews_api", branchID)
+	if err != nil {
+		return nil, false, fmt.Errorf("get reviews_api watermark for %s: %w", branchID, err)
+	}
+	if wm == nil {
+		maxDate, err := s.repo.MaxReviewDate(branchID)
+		if err != nil {
+			return nil, false, fmt.Errorf("max review_date for %s: %w", branchID, err)
+		}
+		if maxDate == nil || *maxDate == "" {
+			return nil, false, nil
+		}
+		t, err := time.Parse("2006-01-02", *maxDate)
+		if err != nil {
+			return nil, false, fmt.Errorf("parse max review_date %q: %w", *maxDate, err)
+		}
+		wm = &t
+	}
+
+	start, sweep, err := s.r.lookbackStart("reviews_api", branchID, *wm)
+	if err != nil {
+		return nil, false, err
+	}
+	return &start, sweep, nil
+}
+
+// archiveCSV writes a per-run CSV backup and moves it into data/reviews for
+// future bootstraps.
+func (s *ReviewSyncer) archiveCSV(branchID string, rows []models.Review) error {
+	lg := s.r.Logger
+
+	// 1) Write per-run CSV backup into ExportDir
+	timestamp := time.Now().UTC().Format("20060102_150405")
+	filename := fmt.Sprintf("reviews_%s_%s_%s.csv", s.strategy, branchID, timestamp)
+	tmpPath := filepath.Join(s.r.Cfg.ExportDir, filename)
+
+	if err := writeReviewsCSV(tmpPath, rows); err != nil {
+		return fmt.Errorf("write reviews CSV for %s: %w", branchID, err)
+	}
+	lg.Printf("💾 %s: saved reviews CSV to %s", branchID, tmpPath)
+
+	// 2) Archive into data/reviews for future bootstrap
+	archiveDir := "data/reviews"
+	if err := os.MkdirAll(archiveDir, 0o755); err != nil {
+		return fmt.Errorf("mkdir %s: %w", archiveDir, err)
+	}
+	finalPath := filepath.Join(archiveDir, filename)
+	if err := os.Rename(tmpPath, finalPath); err != nil {
+		return fmt.Errorf("archive reviews CSV for %s: %w", branchID, err)
+	}
+	lg.Printf("📦 %s: archived %s → %s (for future bootstrap)", branchID, tmpPath, finalPath)
+	return nil
+}
@@ -53,7 +53,7 @@ func (r *Runner) BootstrapReviewsFromCSVsIfNeeded() error {
 			continue
 		}
 
-		if err := repo.UpsertMany(batch.Reviews); err != nil {
+		if _, err := repo.UpsertMany(batch.Reviews); err != nil {
 			return fmt.Errorf("upsert reviews from %s: %w", p, err)
 		}
 
@@ -0,0 +1,226 @@
+package phorest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/araquach/phorest-datahub/internal/models"
+)
+
+// Roster endpoints under /business/{id}/branch/{id}. Each takes the same
+// from_date/to_date (YYYY-MM-DD, at most maxAppointmentRange apart) and
+// page/size parameters as the appointment list.
+const (
+	rosterTimetablePath = "staff/worktimetable"
+	rosterBreakPath     = "break"
+	rosterTimeOffPath   = "timeoff"
+	rosterPageSize      = 200
+)
+
+type rosterPage struct {
+	Page struct {
+		TotalPages int `json:"totalPages"`
+	} `json:"page"`
+}
+
+type timetableAPIResponse struct {
+	Embedded struct {
+		Timetables []struct {
+			StaffID   string `json:"staffId"`
+			Date      string `json:"date"`      // "YYYY-MM-DD"
+			StartTime string `json:"startTime"` // "HH:MM:SS.sss", branch local
+			EndTime   string `json:"endTime"`
+		} `json:"worktimetables"`
+	} `json:"_embedded"`
+	rosterPage
+}
+
+type breakAPIResponse struct {
+	Embedded struct {
+		Breaks []struct {
+			StaffID   string `json:"staffId"`
+			BreakDate string `json:"breakDate"`
+			StartTime string `json:"startTime"`
+			EndTime   string `json:"endTime"`
+			Label     string `json:"label"`
+			PaidBreak bool   `json:"paidBreak"`
+		} `json:"breaks"`
+	} `json:"_embedded"`
+	rosterPage
+}
+
+type timeOffAPIResponse struct {
+	Embedded struct {
+		TimeOffs []struct {
+			StaffID   string `json:"staffId"`
+			StartDate string `json:"startDate"`
+			StartTime string `json:"startTime"`
+			EndDate   string `json:"endDate"`
+			EndTime   string `json:"endTime"`
+			Reason    string `json:"reason"`
+		} `json:"timeOffs"`
+	} `json:"_embedded"`
+	rosterPage
+}
+
+// RosterClient reads staff work timetables, breaks and time off.
+type RosterClient struct {
+	BaseURL  string
+	User     string
+	Pass     string
+	Business string
+	HTTP     *http.Client
+}
+
+func NewRosterClient(user, pass, business string) *RosterClient {
+	return &RosterClient{
+		BaseURL:  "https://api-gateway-eu.phorest.com/third-party-api-server/api",
+		User:     user,
+		Pass:     pass,
+		Business: business,
+		HTTP: &http.Client{
+			Timeout: 30 * time.Second,
+			Transport: &http.Transport{
+				DialContext: (&net.Dialer{
+					Timeout:   10 * time.Second,
+					KeepAlive: 30 * time.Second,
+				}).DialContext,
+				MaxIdleConns:        100,
+				IdleConnTimeout:     90 * time.Second,
+				TLSHandshakeTimeout: 10 * time.Second,
+			},
+		},
+	}
+}
+
+// FetchRoster reads a branch's timetables, breaks and time off for the
+// days from..to (inclusive, at most maxAppointmentRange apart), every page.
+func (c *RosterClient) FetchRoster(ctx context.Context, branchID string, from, to time.Time) (*models.Roster, error) {
+	out := &models.Roster{}
+
+	for page := 0; ; page++ {
+		var api timetableAPIResponse
+		if err := c.get(ctx, branchID, rosterTimetablePath, from, to, page, &api); err != nil {
+			return nil, err
+		}
+		for _, t := range api.Embedded.Timetables {
+			day, start, end, err := clockRange(t.Date, t.StartTime, t.Date, t.EndTime)
+			if err != nil {
+				return nil, fmt.Errorf("timetable for staff %s: %w", t.StaffID, err)
+			}
+			out.Timetables = append(out.Timetables, models.StaffWorkTimetable{
+				BranchID: branchID, StaffID: t.StaffID, WorkDate: day, StartAt: start, EndAt: end,
+			})
+		}
+		if page+1 >= api.Page.TotalPages {
+			break
+		}
+	}
+
+	for page := 0; ; page++ {
+		var api breakAPIResponse
+		if err := c.get(ctx, branchID, rosterBreakPath, from, to, page, &api); err != nil {
+			return nil, err
+		}
+		for _, b := range api.Embedded.Breaks {
+			day, start, end, err := clockRange(b.BreakDate, b.StartTime, b.BreakDate, b.EndTime)
+			if err != nil {
+				return nil, fmt.Errorf("break for staff %s: %w", b.StaffID, err)
+			}
+			out.Breaks = append(out.Breaks, models.StaffBreak{
+				BranchID: branchID, StaffID: b.StaffID, BreakDate: day, StartAt: start, EndAt: end,
+				Label: b.Label, Paid: b.PaidBreak,
+			})
+		}
+		if page+1 >= api.Page.TotalPages {
+			break
+		}
+	}
+
+	for page := 0; ; page++ {
+		var api timeOffAPIResponse
+		if err := c.get(ctx, branchID, rosterTimeOffPath, from, to, page, &api); err != nil {
+			return nil, err
+		}
+		for _, t := range api.Embedded.TimeOffs {
+			startTime, endTime := t.StartTime, t.EndTime
+			if startTime == "" {
+				startTime = "00:00"
+			}
+			if endTime == "" {
+				endTime = "24:00"
+			}
+			_, start, end, err := clockRange(t.StartDate, startTime, t.EndDate, endTime)
+			if err != nil {
+				return nil, fmt.Errorf("time off for staff %s: %w", t.StaffID, err)
+			}
+			out.TimeOff = append(out.TimeOff, models.StaffTimeOff{
+				BranchID: branchID, StaffID: t.StaffID, StartAt: start, EndAt: end, Reason: t.Reason,
+			})
+		}
+		if page+1 >= api.Page.TotalPages {
+			break
+		}
+	}
+
+	return out, nil
+}
+
+func (c *RosterClient) get(ctx context.Context, branchID, path string, from, to time.Time, page int, out any) error {
+	params := url.Values{}
+	params.Set("size", strconv.Itoa(rosterPageSize))
+	params.Set("page", strconv.Itoa(page))
+	params.Set("from_date", from.UTC().Format("2006-01-02"))
+	params.Set("to_date", to.UTC().Format("2006-01-02"))
+	u := fmt.Sprintf("%s/business/%s/branch/%s/%s?%s",
+		c.BaseURL, c.Business, branchID, path, params.Encode())
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(c.User, c.Pass)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		b, _ := io.ReadAll(resp.Body)
+		return &HTTPStatusError{Op: "phorest " + path + " " + branchID, StatusCode: resp.StatusCode, Body: string(b)}
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// clockRange parses a start and end date + wall-clock time. "24:00" means
+// the end of that day. day is the start date.
+func clockRange(startDate, startClock, endDate, endClock string) (day, start, end time.Time, err error) {
+	if day, err = time.Parse("2006-01-02", startDate); err != nil {
+		return day, start, end, fmt.Errorf("bad date %q", startDate)
+	}
+	endDay, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return day, start, end, fmt.Errorf("bad date %q", endDate)
+	}
+	if start, err = clockOn(day, startClock); err != nil {
+		return day, start, end, err
+	}
+	if endClock == "24:00" {
+		return day, start, endDay.AddDate(0, 0, 1), nil
+	}
+	end, err = clockOn(endDay, endClock)
+	return day, start, end, err
+}
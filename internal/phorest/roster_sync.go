@@ -0,0 +1,70 @@
+package phorest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/araquach/phorest-datahub/internal/config"
+	"github.com/araquach/phorest-datahub/internal/repos"
+)
+
+// SyncRosterFromAPI replaces each branch's staff timetables, breaks and time
+// off from the last roster sync (minus the roster_api overlap, since past
+// rosters get corrected) through Cfg.RosterLookahead from today. The first
+// sync of a branch reaches back Cfg.AppointmentsBackfill, so utilisation has
+// rosters for the same history as bookings.
+func (r *Runner) SyncRosterFromAPI(ctx context.Context) error {
+	lg := r.Logger
+
+	lg.Printf("▶️ Starting ROSTER sync...")
+
+	c := NewRosterClient(r.Cfg.PhorestUsername, r.Cfg.PhorestPassword, r.Cfg.PhorestBusiness)
+	repo := repos.NewStaffRosterRepo(r.DB, r.Logger)
+	wr := r.watermarks()
+
+	err := r.forEachBranch(ctx, SyncKindRoster, func(ctx context.Context, b config.BranchConfig) error {
+		lg.Printf("🏢 Branch %s (%s): starting ROSTER sync", b.Name, b.BranchID)
+
+		last, err := wr.GetLastUpdated("roster_api", b.BranchID)
+		if err != nil {
+			return fmt.Errorf("get roster_api watermark for %s: %w", b.BranchID, err)
+		}
+		now := time.Now().UTC()
+		from := now.Add(-r.Cfg.AppointmentsBackfill)
+		if last != nil {
+			from = last.Add(-r.Cfg.OverlapFor("roster_api").Overlap)
+		}
+		to := now.Add(r.Cfg.RosterLookahead)
+
+		saved := 0
+		for _, rg := range appointmentRanges(from, to) {
+			roster, err := c.FetchRoster(ctx, b.BranchID, rg.from, rg.to)
+			if err != nil {
+				return partialAfter(saved, fmt.Errorf("fetch roster %s..%s: %w",
+					rg.from.Format(exportDateFmt), rg.to.Format(exportDateFmt), err))
+			}
+			if err := repo.ReplaceWindow(b.BranchID, rg.from, rg.to, *roster); err != nil {
+				return partialAfter(saved, fmt.Errorf("save roster %s..%s: %w",
+					rg.from.Format(exportDateFmt), rg.to.Format(exportDateFmt), err))
+			}
+			n := len(roster.Timetables) + len(roster.Breaks) + len(roster.TimeOff)
+			saved += n
+			r.count(SyncKindRoster, b.BranchID, int64(n), int64(n))
+		}
+
+		if err := wr.UpsertLastUpdated("roster_api", b.BranchID, now); err != nil {
+			return Partial(fmt.Errorf("update roster_api watermark: %w", err))
+		}
+
+		lg.Printf("✅ ROSTER sync finished for %s (%d row(s) %s..%s)", b.BranchID, saved,
+			from.Format(exportDateFmt), to.Format(exportDateFmt))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	lg.Printf("✅ All branches ROSTER sync finished")
+	return nil
+}
@@ -0,0 +1,386 @@
+package phorest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/araquach/phorest-datahub/internal/config"
+	"github.com/araquach/phorest-datahub/internal/models"
+	"github.com/araquach/phorest-datahub/internal/notify"
+	"github.com/araquach/phorest-datahub/internal/repos"
+)
+
+// Sync kinds that can be started through the RunManager.
+const (
+	SyncKindStaff        = "staff"
+	SyncKindBranches     = "branches"
+	SyncKindClients      = "clients"
+	SyncKindTransactions = "transactions"
+	SyncKindReviews      = "reviews"
+	SyncKindProducts     = "products"
+	SyncKindAppointments = "appointments"
+	SyncKindRoster       = "roster"
+	SyncKindServices     = "services"
+)
+
+// SyncKindScheduled is the sync_runs kind of a CLI run, which covers every
+// kind for every branch. It can't be started through the RunManager.
+const SyncKindScheduled = "scheduled"
+
+// SyncKinds lists every kind accepted by RunManager.Start.
+var SyncKinds = []string{
+	SyncKindStaff, SyncKindBranches, SyncKindClients,
+	SyncKindTransactions, SyncKindReviews, SyncKindProducts,
+	SyncKindAppointments, SyncKindRoster, SyncKindServices,
+}
+
+var (
+	ErrUnknownSyncKind = errors.New("unknown sync kind")
+	ErrUnknownBranch   = errors.New("branch is not configured")
+	ErrRunConflict     = errors.New("a sync of this kind is already running for this branch")
+	ErrBadStrategy     = errors.New("invalid sync strategy")
+)
+
+// RunRequest describes a sync to start. BranchID "" means all configured branches.
+// From/To are only used by the transactions and appointments syncs (explicit
+// re-pull window); Strategy by the reviews and products syncs, LatestN only by reviews.
+type RunRequest struct {
+	Kind        string
+	BranchID    string
+	From        *time.Time
+	To          *time.Time
+	Strategy    string
+	LatestN     int
+	TriggeredBy string
+	RequestedBy string
+}
+
+// ActiveRun is an in-flight (or recently finished) sync with its captured log.
+type ActiveRun struct {
+	ID        int64
+	Kind      string
+	BranchID  string
+	StartedAt time.Time
+
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	lines   []string
+	changed chan struct{}
+	status  string
+	errText string
+}
+
+// Status returns the run status and error text (if any).
+func (a *ActiveRun) Status() (string, string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.status, a.errText
+}
+
+// LinesSince returns log lines from offset onwards, a channel that is closed
+// when more lines arrive, and whether the run has finished.
+func (a *ActiveRun) LinesSince(offset int) ([]string, <-chan struct{}, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var out []string
+	if offset < len(a.lines) {
+		out = append(out, a.lines[offset:]...)
+	}
+	return out, a.changed, a.status != models.SyncRunRunning
+}
+
+func (a *ActiveRun) appendLine(line string) {
+	a.mu.Lock()
+	a.lines = append(a.lines, line)
+	close(a.changed)
+	a.changed = make(chan struct{})
+	a.mu.Unlock()
+}
+
+func (a *ActiveRun) finish(status, errText string) string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.status = status
+	a.errText = errText
+	close(a.changed)
+	a.changed = make(chan struct{})
+	return strings.Join(a.lines, "\n")
+}
+
+// runLogWriter splits logger output into lines for an ActiveRun.
+type runLogWriter struct {
+	run *ActiveRun
+	buf bytes.Buffer
+}
+
+func (w *runLogWriter) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+	for {
+		line, err := w.buf.ReadString('\n')
+		if err != nil {
+			// Partial line: keep it for the next write.
+			w.buf.Reset()
+			w.buf.WriteString(line)
+			break
+		}
+		w.run.appendLine(strings.TrimRight(line, "\n"))
+	}
+	return len(p), nil
+}
+
+// RunManager starts Runner syncs in the background (e.g. from the admin API),
+// records them in sync_runs and keeps their logs available for streaming.
+type RunManager struct {
+	base    *Runner
+	runs    *repos.SyncRunsRepo
+	Timeout time.Duration
+	Retain  time.Duration
+
+	mu     sync.Mutex
+	active map[int64]*ActiveRun
+}
+
+func NewRunManager(base *Runner) *RunManager {
+	return &RunManager{
+		base:    base,
+		runs:    repos.NewSyncRunsRepo(base.DB, base.Logger),
+		Timeout: 30 * time.Minute,
+		Retain:  15 * time.Minute,
+		active:  make(map[int64]*ActiveRun),
+	}
+}
+
+// Start validates the request, records the run and executes it in the background.
+func (m *RunManager) Start(req RunRequest) (*ActiveRun, error) {
+	if !validSyncKind(req.Kind) {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownSyncKind, req.Kind)
+	}
+	switch {
+	case req.Kind == SyncKindReviews:
+		st, err := ParseReviewStrategy(req.Strategy)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrBadStrategy, err)
+		}
+		req.Strategy = string(st)
+	case req.Kind == SyncKindProducts:
+		st, err := ParseProductStrategy(req.Strategy)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrBadStrategy, err)
+		}
+		req.Strategy = string(st)
+	case req.Strategy != "":
+		return nil, fmt.Errorf("%w: %s syncs have no strategies", ErrBadStrategy, req.Kind)
+	}
+
+	branches := m.base.Cfg.Branches
+	if req.BranchID != "" {
+		branches = nil
+		for _, b := range m.base.Cfg.Branches {
+			if b.BranchID == req.BranchID {
+				branches = []config.BranchConfig{b}
+			}
+		}
+		if branches == nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownBranch, req.BranchID)
+		}
+	}
+
+	branchKey := req.BranchID
+	if branchKey == "" {
+		branchKey = "ALL"
+	}
+
+	m.mu.Lock()
+	for _, a := range m.active {
+		st, _ := a.Status()
+		if st == models.SyncRunRunning && a.Kind == req.Kind &&
+			(a.BranchID == branchKey || a.BranchID == "ALL" || branchKey == "ALL") {
+			m.mu.Unlock()
+			return nil, fmt.Errorf("%w (run %d)", ErrRunConflict, a.ID)
+		}
+	}
+
+	rec := &models.SyncRun{
+		Kind:        req.Kind,
+		BranchID:    branchKey,
+		WindowFrom:  req.From,
+		WindowTo:    req.To,
+		Strategy:    req.Strategy,
+		TriggeredBy: req.TriggeredBy,
+		RequestedBy: req.RequestedBy,
+	}
+	// Runs of other processes (another API replica, a cron CLI run) only
+	// show up in sync_runs.
+	conflict, err := m.runs.StartExclusive(rec, []string{req.Kind, SyncKindScheduled})
+	if err != nil {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("record sync run: %w", err)
+	}
+	if conflict != 0 {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("%w (run %d)", ErrRunConflict, conflict)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.Timeout)
+	run := &ActiveRun{
+		ID:        rec.ID,
+		Kind:      req.Kind,
+		BranchID:  branchKey,
+		StartedAt: rec.StartedAt,
+		cancel:    cancel,
+		changed:   make(chan struct{}),
+		status:    models.SyncRunRunning,
+	}
+	m.active[run.ID] = run
+	m.mu.Unlock()
+
+	// Per-run Runner: same DB/export client, but only the requested branches
+	// and a logger that also feeds the run's log stream.
+	cfg := *m.base.Cfg
+	cfg.Branches = branches
+	lg := log.New(
+		io.MultiWriter(m.base.Logger.Writer(), &runLogWriter{run: run}),
+		fmt.Sprintf("[sync #%d] ", run.ID),
+		log.LstdFlags,
+	)
+	scoped := &Runner{
+		DB:     m.base.DB,
+		Cfg:    &cfg,
+		Logger: lg,
+		Export: m.base.Export,
+		Report: NewSyncReport(),
+		RunID:  &run.ID,
+		Notify: m.base.Notify,
+	}
+
+	go m.execute(ctx, cancel, run, scoped, req)
+	return run, nil
+}
+
+func (m *RunManager) execute(ctx context.Context, cancel context.CancelFunc, run *ActiveRun, r *Runner, req RunRequest) {
+	defer cancel()
+
+	var err error
+	func() {
+		defer func() {
+			if p := recover(); p != nil {
+				err = fmt.Errorf("panic: %v", p)
+			}
+		}()
+		r.Logger.Printf("🚀 Starting %s sync (branch=%s, triggered by %s)", run.Kind, run.BranchID, req.TriggeredBy)
+		err = runSyncKind(ctx, r, req)
+	}()
+
+	status, errText := models.SyncRunSucceeded, ""
+	switch {
+	case err != nil && errors.Is(ctx.Err(), context.Canceled):
+		status, errText = models.SyncRunCancelled, "cancelled"
+		r.Logger.Printf("🛑 %s sync cancelled", run.Kind)
+	case err != nil && r.Report.Status() == SyncPartial:
+		status, errText = models.SyncRunPartial, err.Error()
+		r.Logger.Printf("⚠️  %s sync partially failed: %v", run.Kind, err)
+	case err != nil:
+		status, errText = models.SyncRunFailed, err.Error()
+		r.Logger.Printf("❌ %s sync failed: %v", run.Kind, err)
+	default:
+		r.Logger.Printf("✅ %s sync finished", run.Kind)
+	}
+
+	if status != models.SyncRunCancelled {
+		r.NotifyFailures(context.Background())
+		if err != nil && len(r.Report.Results()) == 0 {
+			// Failed before any entity/branch result was recorded.
+			r.notify(context.Background(), notify.Event{
+				Type:     notify.EventSyncFailed,
+				Severity: notify.SeverityCritical,
+				BranchID: run.BranchID,
+				Title:    fmt.Sprintf("%s sync failed", run.Kind),
+				Body:     errText,
+				Data:     map[string]any{"entity": run.Kind, "run_id": run.ID},
+			})
+		}
+	}
+
+	logText := run.finish(status, errText)
+	if err := m.runs.Finish(run.ID, status, errText, logText); err != nil {
+		m.base.Logger.Printf("⚠️  failed to record result of sync run %d: %v", run.ID, err)
+	}
+
+	time.AfterFunc(m.Retain, func() {
+		m.mu.Lock()
+		delete(m.active, run.ID)
+		m.mu.Unlock()
+	})
+}
+
+func runSyncKind(ctx context.Context, r *Runner, req RunRequest) error {
+	switch req.Kind {
+	case SyncKindStaff:
+		return r.SyncStaffFromAPI(ctx)
+	case SyncKindBranches:
+		return r.SyncBranchesFromAPI(ctx)
+	case SyncKindClients:
+		return r.RunIncrementalClientsSync(ctx)
+	case SyncKindTransactions:
+		if req.From != nil && req.To != nil {
+			return r.RunTransactionsWindowSync(ctx, *req.From, *req.To)
+		}
+		return r.RunIncrementalTransactionsSync(ctx)
+	case SyncKindReviews:
+		return r.SyncReviews(ctx, ReviewStrategy(req.Strategy), req.LatestN)
+	case SyncKindProducts:
+		return r.SyncProductsFromAPI(ctx, ProductStrategy(req.Strategy))
+	case SyncKindAppointments:
+		if req.From != nil && req.To != nil {
+			return r.RunAppointmentsWindowSync(ctx, *req.From, *req.To)
+		}
+		return r.RunIncrementalAppointmentsSync(ctx)
+	case SyncKindRoster:
+		return r.SyncRosterFromAPI(ctx)
+	case SyncKindServices:
+		return r.SyncServicesFromAPI(ctx)
+	}
+	return fmt.Errorf("%w: %q", ErrUnknownSyncKind, req.Kind)
+}
+
+// Get returns an in-memory run (running or recently finished), or nil.
+func (m *RunManager) Get(id int64) *ActiveRun {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.active[id]
+}
+
+// Cancel cancels a running sync's context. Returns false if it isn't running here.
+func (m *RunManager) Cancel(id int64) bool {
+	run := m.Get(id)
+	if run == nil {
+		return false
+	}
+	if st, _ := run.Status(); st != models.SyncRunRunning {
+		return false
+	}
+	run.cancel()
+	return true
+}
+
+// Runs exposes the sync_runs repository for status lookups.
+func (m *RunManager) Runs() *repos.SyncRunsRepo {
+	return m.runs
+}
+
+func validSyncKind(kind string) bool {
+	for _, k := range SyncKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package phorest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type ServicesClient struct {
+	BaseURL    string
+	BusinessID string
+	Username   string
+	Password   string
+	HTTPClient *http.Client
+}
+
+func NewServicesClient(baseURL, businessID, username, password string) *ServicesClient {
+	if baseURL == "" {
+		baseURL = "https://api-gateway-eu.phorest.com/third-party-api-server"
+	}
+	return &ServicesClient{
+		BaseURL:    baseURL,
+		BusinessID: businessID,
+		Username:   username,
+		Password:   password,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type PhorestService struct {
+	ServiceID    string    `json:"serviceId"`
+	Name         string    `json:"name"`
+	CategoryID   string    `json:"categoryId"`
+	CategoryName string    `json:"categoryName"`
+	Duration     int       `json:"duration"` // minutes
+	Price        float64   `json:"price"`
+	Archived     bool      `json:"archived"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+type listServicesResponse struct {
+	Embedded struct {
+		Services []PhorestService `json:"services"`
+	} `json:"_embedded"`
+	Page struct {
+		Size          int `json:"size"`
+		TotalElements int `json:"totalElements"`
+		TotalPages    int `json:"totalPages"`
+		Number        int `json:"number"`
+	} `json:"page"`
+}
+
+// ListServices fetches one page of a branch's service catalogue, archived
+// services included.
+func (c *ServicesClient) ListServices(ctx context.Context, branchID string, page, size int) (*listServicesResponse, error) {
+	if size <= 0 {
+		size = 100
+	}
+	u, err := url.Parse(fmt.Sprintf("%s/api/business/%s/branch/%s/service", c.BaseURL, c.BusinessID, branchID))
+	if err != nil {
+		return nil, err
+	}
+
+	q := u.Query()
+	q.Set("size", fmt.Sprint(size))
+	q.Set("page", fmt.Sprint(page))
+	q.Set("fetch_archived", "true")
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(c.Username, c.Password)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		msg := strings.TrimSpace(string(body))
+		if msg == "" {
+			msg = "<empty body>"
+		}
+		return nil, &HTTPStatusError{Op: "phorest: list services " + u.String(), StatusCode: resp.StatusCode, Body: msg}
+	}
+
+	var out listServicesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,189 @@
+package phorest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/araquach/phorest-datahub/internal/config"
+	"github.com/araquach/phorest-datahub/internal/models"
+	"github.com/araquach/phorest-datahub/internal/repos"
+	"gorm.io/gorm"
+)
+
+// SyncServicesFromAPI reads every branch's full service catalogue into
+// ph_services, appending to ph_service_history whenever a service is new or
+// its name, category, duration, list price or archived status changed.
+// The catalogue is small, so each run lists it whole; services_api records
+// the newest updatedAt seen.
+func (r *Runner) SyncServicesFromAPI(ctx context.Context) error {
+	lg := r.Logger
+
+	lg.Printf("▶️ Starting SERVICES sync...")
+
+	sc := NewServicesClient("", r.Cfg.PhorestBusiness, r.Cfg.PhorestUsername, r.Cfg.PhorestPassword)
+	wr := r.watermarks()
+
+	err := r.forEachBranch(ctx, SyncKindServices, func(ctx context.Context, b config.BranchConfig) error {
+		lg.Printf("🏢 Branch %s (%s): starting SERVICES sync", b.Name, b.BranchID)
+
+		var (
+			processed  int
+			changed    int64
+			maxUpdated *time.Time
+		)
+		defer func() { r.count(SyncKindServices, b.BranchID, int64(processed), changed) }()
+
+		for page := 0; ; page++ {
+			resp, err := sc.ListServices(ctx, b.BranchID, page, 100)
+			if err != nil {
+				return partialAfter(processed, err)
+			}
+			if len(resp.Embedded.Services) == 0 {
+				break
+			}
+
+			n, err := r.syncServicePage(ctx, b.BranchID, resp.Embedded.Services)
+			if err != nil {
+				return partialAfter(processed, fmt.Errorf("page %d: %w", page, err))
+			}
+			processed += len(resp.Embedded.Services)
+			changed += n
+			for _, ps := range resp.Embedded.Services {
+				if !ps.UpdatedAt.IsZero() {
+					t := ps.UpdatedAt
+					maxUpdated = maxTime(maxUpdated, &t)
+				}
+			}
+
+			if resp.Page.TotalPages > 0 && page+1 >= resp.Page.TotalPages {
+				break
+			}
+		}
+
+		if maxUpdated != nil {
+			if err := wr.UpsertLastUpdated("services_api", b.BranchID, *maxUpdated); err != nil {
+				return Partial(fmt.Errorf("update services_api watermark for %s: %w", b.BranchID, err))
+			}
+		}
+
+		lg.Printf("✅ SERVICES sync finished for %s (%d service(s), %d changed)", b.BranchID, processed, changed)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	lg.Printf("✅ All branches SERVICES sync finished")
+	return nil
+}
+
+// syncServicePage upserts one API page of services and their history rows
+// in a single transaction, returning how many were new or changed.
+func (r *Runner) syncServicePage(ctx context.Context, branchID string, page []PhorestService) (int64, error) {
+	// Keep the last version of a service listed twice.
+	idx := make(map[string]int, len(page))
+	var rows []models.PhService
+	for _, ps := range page {
+		s := serviceModel(ps, branchID)
+		if i, ok := idx[ps.ServiceID]; ok {
+			rows[i] = s
+			continue
+		}
+		idx[ps.ServiceID] = len(rows)
+		rows = append(rows, s)
+	}
+	ids := make([]string, len(rows))
+	for i := range rows {
+		ids[i] = rows[i].ID
+	}
+
+	var changed int64
+	now := time.Now().UTC()
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		repo := repos.NewPhServiceRepo(tx).ForRun(r.RunID)
+
+		old, err := repo.GetManyForBranch(ctx, branchID, ids)
+		if err != nil {
+			return fmt.Errorf("load services: %w", err)
+		}
+
+		var history []models.PhServiceHistory
+		for i := range rows {
+			s := &rows[i]
+			if !serviceChanged(old[s.ID], s) {
+				continue
+			}
+			changed++
+			history = append(history, models.PhServiceHistory{
+				ServiceID:       s.ID,
+				BranchID:        branchID,
+				SnapshotTime:    now,
+				Name:            s.Name,
+				CategoryName:    s.CategoryName,
+				DurationMinutes: s.DurationMinutes,
+				Price:           s.Price,
+				Archived:        s.Archived,
+			})
+		}
+
+		if err := repo.UpsertMany(ctx, rows); err != nil {
+			return fmt.Errorf("upsert services: %w", err)
+		}
+		if err := repo.InsertHistoryMany(ctx, history); err != nil {
+			return fmt.Errorf("insert service history: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return changed, nil
+}
+
+// serviceModel maps a PhorestService to its ph_services row. A zero
+// duration or price is stored as NULL (not set in Phorest).
+func serviceModel(ps PhorestService, branchID string) models.PhService {
+	s := models.PhService{
+		ID:           ps.ServiceID,
+		BranchID:     branchID,
+		Name:         ps.Name,
+		CategoryID:   optString(ps.CategoryID),
+		CategoryName: optString(ps.CategoryName),
+		Price:        optFloat(ps.Price),
+		Archived:     ps.Archived,
+		CreatedAtPh:  nonZeroTime(ps.CreatedAt),
+		UpdatedAtPh:  nonZeroTime(ps.UpdatedAt),
+	}
+	if ps.Duration > 0 {
+		d := ps.Duration
+		s.DurationMinutes = &d
+	}
+	return s
+}
+
+// serviceChanged reports whether a service is new or a tracked field differs.
+func serviceChanged(old, cur *models.PhService) bool {
+	if old == nil {
+		return true
+	}
+	return old.Name != cur.Name ||
+		!eqText(old.CategoryName, cur.CategoryName) ||
+		!eqInt(old.DurationMinutes, cur.DurationMinutes) ||
+		!eqFloat(old.Price, cur.Price) ||
+		old.Archived != cur.Archived
+}
+
+func eqText(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func eqInt(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -69,11 +69,11 @@ func NewStaffClient(user, pass, business string, lg *log.Logger) *StaffClient {
 	}
 }
 
-func (c *StaffClient) FetchStaff(branchID string) ([]models.Staff, error) {
+func (c *StaffClient) FetchStaff(ctx context.Context, branchID string) ([]models.Staff, error) {
 	url := fmt.Sprintf("%s/business/%s/branch/%s/staff?fetch_archived=true&size=%d",
 		c.BaseURL, c.Business, branchID, 200)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Second)
 	defer cancel()
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
@@ -91,7 +91,7 @@ func (c *StaffClient) FetchStaff(branchID string) ([]models.Staff, error) {
 
 	if resp.StatusCode < 200 || resp.StatusCode > 299 {
 		b, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("phorest staff %s: %d: %s", branchID, resp.StatusCode, string(b))
+		return nil, &HTTPStatusError{Op: "phorest staff " + branchID, StatusCode: resp.StatusCode, Body: string(b)}
 	}
 
 	var api StaffAPIResponse
@@ -1,13 +1,17 @@
 package phorest
 
 import (
+	"context"
+	"fmt"
 	"time"
 
+	"github.com/araquach/phorest-datahub/internal/config"
 	"github.com/araquach/phorest-datahub/internal/repos"
 )
 
 // SyncStaffFromAPI fetches staff for each configured branch and upserts them.
-func (r *Runner) SyncStaffFromAPI() error {
+// Branches run in parallel; failures are reported together at the end.
+func (r *Runner) SyncStaffFromAPI(ctx context.Context) error {
 	c := NewStaffClient(
 		r.Cfg.PhorestUsername,
 		r.Cfg.PhorestPassword,
@@ -16,40 +20,32 @@ func (r *Runner) SyncStaffFromAPI() error {
 	)
 
 	repo := repos.NewStaffRepo(r.DB, r.Logger)
-	wr := repos.NewWatermarksRepo(r.DB, r.Logger)
-
-	for _, b := range r.Cfg.Branches {
-		if b.BranchID == "" {
-			r.Logger.Printf("⚠️  Skipping branch with empty BranchID (name=%q)", b.Name)
-			continue
-		}
+	wr := r.watermarks()
 
+	return r.forEachBranch(ctx, SyncKindStaff, func(ctx context.Context, b config.BranchConfig) error {
 		r.Logger.Printf("Fetching staff for %s (%s)", b.Name, b.BranchID)
 
-		rows, err := c.FetchStaff(b.BranchID)
+		rows, err := c.FetchStaff(ctx, b.BranchID)
 		if err != nil {
-			r.Logger.Printf("❌ staff fetch failed for %s (%s): %v", b.Name, b.BranchID, err)
-			continue
+			return fmt.Errorf("staff fetch: %w", err)
 		}
 		if len(rows) == 0 {
 			r.Logger.Printf("No staff to upsert for %s (%s)", b.Name, b.BranchID)
-			continue
+			return nil
 		}
 
 		if err := repo.UpsertMany(rows); err != nil {
-			r.Logger.Printf("❌ staff upsert failed for %s (%s): %v", b.Name, b.BranchID, err)
-			continue
+			return fmt.Errorf("staff upsert: %w", err)
 		}
 
 		// 🔹 record / advance watermark for this branch
 		now := time.Now().UTC()
 		if err := wr.UpsertLastUpdated("staff_api", b.BranchID, now); err != nil {
-			r.Logger.Printf("⚠️ failed to update staff_api watermark for %s (%s): %v", b.Name, b.BranchID, err)
-			// you could `continue` or `return err` here depending on how strict you want to be
+			// Staff rows are saved; only the bookkeeping failed.
+			return Partial(fmt.Errorf("update staff_api watermark: %w", err))
 		}
 
 		r.Logger.Printf("✅ staff upserted for %s (%s): %d", b.Name, b.BranchID, len(rows))
-	}
-
-	return nil
+		return nil
+	})
 }
@@ -0,0 +1,293 @@
+package phorest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ---------- Error categories ----------
+
+// ErrorCategory says whether retrying later is likely to help.
+type ErrorCategory string
+
+const (
+	// CategoryTransient: network errors, timeouts, 429/5xx — the next run will probably succeed.
+	CategoryTransient ErrorCategory = "transient"
+	// CategoryFatal: bad credentials, 4xx, bad data, DB errors — needs a human.
+	CategoryFatal ErrorCategory = "fatal"
+)
+
+// HTTPStatusError is returned by the Phorest clients for non-2xx responses.
+type HTTPStatusError struct {
+	Op         string
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("%s: status %d: %s", e.Op, e.StatusCode, e.Body)
+}
+
+// SyncError attaches an explicit category (and optionally "some data was
+// saved") to an error. Use Transient, Fatal and Partial to build one.
+type SyncError struct {
+	Category ErrorCategory
+	Partial  bool
+	Err      error
+}
+
+func (e *SyncError) Error() string { return e.Err.Error() }
+func (e *SyncError) Unwrap() error { return e.Err }
+
+// Transient marks err as worth retrying on the next run.
+func Transient(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &SyncError{Category: CategoryTransient, Err: err}
+}
+
+// Fatal marks err as needing intervention regardless of its cause.
+func Fatal(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &SyncError{Category: CategoryFatal, Err: err}
+}
+
+// Partial marks err as having happened after some rows were already saved.
+// The category is still derived from the underlying error.
+func Partial(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &SyncError{Category: Categorize(err), Partial: true, Err: err}
+}
+
+// Categorize classifies an error. Explicit SyncError categories win; otherwise
+// timeouts, network errors and 408/429/5xx responses are transient and
+// everything else is fatal.
+func Categorize(err error) ErrorCategory {
+	if err == nil {
+		return ""
+	}
+
+	var se *SyncError
+	if errors.As(err, &se) && se.Category != "" {
+		return se.Category
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return CategoryTransient
+	}
+	var he *HTTPStatusError
+	if errors.As(err, &he) {
+		switch {
+		case he.StatusCode == http.StatusRequestTimeout,
+			he.StatusCode == http.StatusTooManyRequests,
+			he.StatusCode >= 500:
+			return CategoryTransient
+		}
+		return CategoryFatal
+	}
+	var ne net.Error
+	if errors.As(err, &ne) {
+		return CategoryTransient
+	}
+	return CategoryFatal
+}
+
+// partialAfter marks err as Partial when n > 0 pages/rows were already saved.
+func partialAfter(n int, err error) error {
+	if n > 0 {
+		return Partial(err)
+	}
+	return err
+}
+
+func isPartial(err error) bool {
+	var se *SyncError
+	return errors.As(err, &se) && se.Partial
+}
+
+// ---------- Results ----------
+
+// SyncStatus is the outcome of one entity/branch sync (or a whole report).
+type SyncStatus string
+
+const (
+	SyncSuccess SyncStatus = "success"
+	SyncPartial SyncStatus = "partial"
+	SyncFailed  SyncStatus = "failed"
+)
+
+// Process exit codes for cron monitoring.
+const (
+	ExitOK      = 0
+	ExitFailed  = 1
+	ExitPartial = 3
+)
+
+// SyncResult is the outcome of syncing one entity for one branch
+// (BranchID "ALL" for business-wide syncs such as clients and branches).
+type SyncResult struct {
+	Entity   string
+	BranchID string
+	Status   SyncStatus
+	Category ErrorCategory
+	Err      error
+	Duration time.Duration
+
+	// Rows seen from Phorest and rows the upserts actually inserted or
+	// changed; overlap re-pulls make Rows larger than Changed.
+	Rows    int64
+	Changed int64
+}
+
+// NewSyncResult derives status and category from err.
+func NewSyncResult(entity, branchID string, started time.Time, err error) SyncResult {
+	res := SyncResult{
+		Entity:   entity,
+		BranchID: branchID,
+		Status:   SyncSuccess,
+		Duration: time.Since(started),
+	}
+	if err != nil {
+		res.Err = err
+		res.Category = Categorize(err)
+		res.Status = SyncFailed
+		if isPartial(err) {
+			res.Status = SyncPartial
+		}
+	}
+	return res
+}
+
+// SyncReport collects results from every sync in a process run. Safe for
+// concurrent use by parallel branch workers.
+type SyncReport struct {
+	mu      sync.Mutex
+	results []SyncResult
+	counts  map[string][2]int64 // entity|branch → rows, changed
+}
+
+func NewSyncReport() *SyncReport {
+	return &SyncReport{counts: make(map[string][2]int64)}
+}
+
+// Count adds row stats for an entity/branch; they are attached to its result.
+func (rep *SyncReport) Count(entity, branchID string, rows, changed int64) {
+	rep.mu.Lock()
+	c := rep.counts[entity+"|"+branchID]
+	c[0] += rows
+	c[1] += changed
+	rep.counts[entity+"|"+branchID] = c
+	rep.mu.Unlock()
+}
+
+func (rep *SyncReport) Add(res SyncResult) {
+	rep.mu.Lock()
+	rep.results = append(rep.results, res)
+	rep.mu.Unlock()
+}
+
+// Results returns a copy of the collected results, ordered by entity then branch.
+func (rep *SyncReport) Results() []SyncResult {
+	rep.mu.Lock()
+	out := append([]SyncResult(nil), rep.results...)
+	for i := range out {
+		c := rep.counts[out[i].Entity+"|"+out[i].BranchID]
+		out[i].Rows, out[i].Changed = c[0], c[1]
+	}
+	rep.mu.Unlock()
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Entity != out[j].Entity {
+			return out[i].Entity < out[j].Entity
+		}
+		return out[i].BranchID < out[j].BranchID
+	})
+	return out
+}
+
+// Status is success if everything succeeded, failed if nothing did, and
+// partial otherwise. An empty report counts as success.
+func (rep *SyncReport) Status() SyncStatus {
+	results := rep.Results()
+	ok, bad := 0, 0
+	for _, r := range results {
+		switch r.Status {
+		case SyncSuccess:
+			ok++
+		case SyncFailed:
+			bad++
+		}
+	}
+	switch {
+	case bad == len(results) && bad > 0:
+		return SyncFailed
+	case ok == len(results):
+		return SyncSuccess
+	default:
+		return SyncPartial
+	}
+}
+
+// ExitCode maps Status to ExitOK / ExitPartial / ExitFailed.
+func (rep *SyncReport) ExitCode() int {
+	switch rep.Status() {
+	case SyncFailed:
+		return ExitFailed
+	case SyncPartial:
+		return ExitPartial
+	}
+	return ExitOK
+}
+
+// Log writes a one-line-per-result summary.
+func (rep *SyncReport) Log(lg *log.Logger) {
+	results := rep.Results()
+	if len(results) == 0 {
+		lg.Printf("ℹ️ Sync summary: nothing ran")
+		return
+	}
+
+	lg.Printf("📋 Sync summary (%s):", rep.Status())
+	for _, r := range results {
+		icon := "✅"
+		switch r.Status {
+		case SyncPartial:
+			icon = "⚠️ "
+		case SyncFailed:
+			icon = "❌"
+		}
+		line := fmt.Sprintf("   %s %-12s %-24s %-8s %-6s", icon, r.Entity, r.BranchID, r.Status, r.Duration.Round(time.Second))
+		if r.Rows > 0 || r.Changed > 0 {
+			line += fmt.Sprintf(" rows=%d changed=%d", r.Rows, r.Changed)
+		}
+		if r.Err != nil {
+			line += fmt.Sprintf("  [%s] %v", r.Category, r.Err)
+		}
+		lg.Print(line)
+	}
+}
+
+// count adds row stats to the Runner's report (if it has one).
+func (r *Runner) count(entity, branchID string, rows, changed int64) {
+	if r.Report != nil {
+		r.Report.Count(entity, branchID, rows, changed)
+	}
+}
+
+// record adds a result to the Runner's report (if it has one).
+func (r *Runner) record(entity, branchID string, started time.Time, err error) {
+	if r.Report != nil {
+		r.Report.Add(NewSyncResult(entity, branchID, started, err))
+	}
+}
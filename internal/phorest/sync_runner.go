@@ -1,6 +1,7 @@
 package phorest
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log"
@@ -10,6 +11,7 @@ import (
 	"time"
 
 	"github.com/araquach/phorest-datahub/internal/config"
+	"github.com/araquach/phorest-datahub/internal/notify"
 	"github.com/araquach/phorest-datahub/internal/repos"
 	"gorm.io/gorm"
 )
@@ -19,24 +21,54 @@ type Runner struct {
 	Cfg    *config.Config
 	Logger *log.Logger
 	Export *ExportClient
+
+	// Report collects per-entity/per-branch results for this process run.
+	Report *SyncReport
+
+	// RunID is the sync_runs row this Runner works under (nil if unrecorded);
+	// watermark history rows are attributed to it.
+	RunID *int64
+
+	// Notify receives sync failures, stale data, CSV drift, low reviews and
+	// stock alerts (nil = events are dropped).
+	Notify *notify.Dispatcher
 }
 
 // Accept cfg and store it so r.Cfg is valid everywhere
-func NewRunner(db *gorm.DB, cfg *config.Config, lg *log.Logger) *Runner {
+func NewRunner(db *gorm.DB, cfg *config.Config, lg *log.Logger) (*Runner, error) {
 	export := NewExportClient(
 		cfg.PhorestUsername,
 		cfg.PhorestPassword,
 		cfg.PhorestBusiness,
 	)
 
+	dispatcher, err := notify.Load(db, cfg.NotifyConfig, lg)
+	if err != nil {
+		return nil, fmt.Errorf("NOTIFY_CONFIG: %w", err)
+	}
+
 	return &Runner{
 		DB:     db,
 		Cfg:    cfg,
 		Logger: lg,
 		Export: export,
+		Report: NewSyncReport(),
+		Notify: dispatcher,
+	}, nil
+}
+
+// notify publishes ev; delivery failures are logged, never returned.
+func (r *Runner) notify(ctx context.Context, ev notify.Event) {
+	if err := r.Notify.Publish(ctx, ev); err != nil {
+		r.Logger.Printf("⚠️  notification failed: %v", err)
 	}
 }
 
+// watermarks returns a WatermarksRepo that attributes changes to this run.
+func (r *Runner) watermarks() *repos.WatermarksRepo {
+	return repos.NewWatermarksRepo(r.DB, r.Logger).ForRun(r.RunID, repos.WatermarkChangedBySync)
+}
+
 // ImportAllTransactionsCSVs loops through all .csv files in a directory and imports them.
 func (r *Runner) ImportAllTransactionsCSVs(dir string) error {
 	lg := r.Logger
@@ -57,7 +89,7 @@ func (r *Runner) ImportAllTransactionsCSVs(dir string) error {
 		lg.Printf("──────────────────────────────────────────────")
 		lg.Printf("🏁 Starting import for file: %s", name)
 
-		if err := r.importSingleTransactionsCSV(path); err != nil {
+		if _, err := r.importSingleTransactionsCSV(path); err != nil {
 			lg.Printf("❌ Failed import for %s: %v", name, err)
 			continue
 		}
@@ -68,18 +100,42 @@ func (r *Runner) ImportAllTransactionsCSVs(dir string) error {
 	return nil
 }
 
-func (r *Runner) importSingleTransactionsCSV(csvPath string) error {
+// importStats summarises one CSV import.
+type importStats struct {
+	Rows       int64      // rows in the file
+	Changed    int64      // rows the upserts actually inserted or updated
+	MaxUpdated *time.Time // newest updated_at_phorest in the file
+}
+
+func maxTime(cur *time.Time, ts *time.Time) *time.Time {
+	if ts != nil && (cur == nil || ts.After(*cur)) {
+		return ts
+	}
+	return cur
+}
+
+func (r *Runner) importSingleTransactionsCSV(csvPath string) (importStats, error) {
 	lg := r.Logger
+	var stats importStats
 
 	batch, err := ParseTransactionsCSV(csvPath, lg)
 	if err != nil {
-		return err
+		return stats, err
 	}
+	r.notifyDrift("transactions", batch.Drift)
 	lg.Printf("Importing CSV %s: %d transactions, %d items", csvPath, len(batch.Transactions), len(batch.Items))
 
+	stats.Rows = int64(len(batch.Transactions) + len(batch.Items))
+	for i := range batch.Transactions {
+		stats.MaxUpdated = maxTime(stats.MaxUpdated, batch.Transactions[i].UpdatedAtPhorest)
+	}
+	for i := range batch.Items {
+		stats.MaxUpdated = maxTime(stats.MaxUpdated, batch.Items[i].UpdatedAtPhorest)
+	}
+
 	tx := r.DB.Begin()
 	if tx.Error != nil {
-		return tx.Error
+		return stats, tx.Error
 	}
 	defer func() {
 		if p := recover(); p != nil {
@@ -91,20 +147,23 @@ func (r *Runner) importSingleTransactionsCSV(csvPath string) error {
 	tr := repos.NewTransactionsRepo(tx, lg)
 	ir := repos.NewItemsRepo(tx, lg)
 
-	if err := tr.UpsertBatch(batch.Transactions, 500); err != nil {
+	txChanged, err := tr.UpsertBatch(batch.Transactions, 500)
+	if err != nil {
 		_ = tx.Rollback()
-		return err
+		return stats, err
 	}
-	if err := ir.UpsertBatch(batch.Items, 500); err != nil {
+	itemsChanged, err := ir.UpsertBatch(batch.Items, 500)
+	if err != nil {
 		_ = tx.Rollback()
-		return err
+		return stats, err
 	}
 
 	if err := tx.Commit().Error; err != nil {
-		return err
+		return stats, err
 	}
-	lg.Printf("✅ CSV %s committed.", filepath.Base(csvPath))
-	return nil
+	stats.Changed = txChanged + itemsChanged
+	lg.Printf("✅ CSV %s committed (%d rows, %d changed).", filepath.Base(csvPath), stats.Rows, stats.Changed)
+	return stats, nil
 }
 
 // ImportAllClientCSVs scans a dir and imports every .csv as clients
@@ -119,7 +178,7 @@ func (r *Runner) ImportAllClientCSVs(dir string) error {
 		return nil
 	}
 	for _, p := range paths {
-		if err := r.importSingleClientsCSV(p); err != nil {
+		if _, err := r.importSingleClientsCSV(p); err != nil {
 			r.Logger.Printf("❌ Client import failed: %s: %v", p, err)
 			continue
 		}
@@ -128,29 +187,30 @@ func (r *Runner) ImportAllClientCSVs(dir string) error {
 	return nil
 }
 
-func (r *Runner) importSingleClientsCSV(csvPath string) error {
+func (r *Runner) importSingleClientsCSV(csvPath string) (importStats, error) {
 	lg := r.Logger
+	var stats importStats
+
 	batch, err := ParseClientsCSV(csvPath, lg)
 	if err != nil {
-		return err
+		return stats, err
 	}
+	r.notifyDrift("clients", batch.Drift)
 	lg.Printf("Importing Clients CSV %s: %d clients", csvPath, len(batch.Clients))
+	stats.Rows = int64(len(batch.Clients))
 
 	var maxTS *time.Time
 	for i := range batch.Clients {
-		if ts := batch.Clients[i].UpdatedAtPhorest; ts != nil {
-			if maxTS == nil || ts.After(*maxTS) {
-				maxTS = ts
-			}
-		}
+		maxTS = maxTime(maxTS, batch.Clients[i].UpdatedAtPhorest)
 	}
 	if maxTS == nil {
 		lg.Printf("⚠️  No UpdatedAtPhorest values in %s; skipping watermark update", csvPath)
 	}
+	stats.MaxUpdated = maxTS
 
 	tx := r.DB.Begin()
 	if tx.Error != nil {
-		return tx.Error
+		return stats, tx.Error
 	}
 	defer func() {
 		if p := recover(); p != nil {
@@ -160,25 +220,27 @@ func (r *Runner) importSingleClientsCSV(csvPath string) error {
 	}()
 
 	cr := repos.NewClientsRepo(tx, lg)
-	if err := cr.UpsertBatch(batch.Clients, 1000); err != nil {
+	changed, err := cr.UpsertBatch(batch.Clients, 1000)
+	if err != nil {
 		_ = tx.Rollback()
-		return err
+		return stats, err
 	}
 
 	if maxTS != nil {
-		wr := repos.NewWatermarksRepo(tx, lg)
+		wr := repos.NewWatermarksRepo(tx, lg).ForRun(r.RunID, "")
 		// NOTE: branch = "ALL" for global clients CSV
 		if err := wr.UpsertLastUpdated("clients_csv", "ALL", *maxTS); err != nil {
 			_ = tx.Rollback()
-			return fmt.Errorf("update clients_csv watermark: %w", err)
+			return stats, fmt.Errorf("update clients_csv watermark: %w", err)
 		}
 	}
 
 	if err := tx.Commit().Error; err != nil {
-		return err
+		return stats, err
 	}
-	lg.Printf("✅ Clients CSV %s committed.", csvPath)
-	return nil
+	stats.Changed = changed
+	lg.Printf("✅ Clients CSV %s committed (%d rows, %d changed).", csvPath, stats.Rows, stats.Changed)
+	return stats, nil
 }
 
 // archiveCSVToSeed copies a CSV from srcPath into destDir
@@ -17,6 +17,7 @@ import (
 type ParsedBatch struct {
 	Transactions []models.Transaction
 	Items        []models.TransactionItem
+	Drift        *SchemaDrift // header vs the columns read; nil = no change
 }
 
 // ParseTransactionsCSV reads a Phorest transactions CSV and returns split header/items.
@@ -37,14 +38,11 @@ func ParseTransactionsCSV(path string, lg *log.Logger) (*ParsedBatch, error) {
 	if err != nil {
 		return nil, fmt.Errorf("read header: %w", err)
 	}
-	idx := make(map[string]int, len(header))
-	for i, h := range header {
-		idx[strings.TrimSpace(strings.ToLower(h))] = i
-	}
+	cols := newCSVColumns(header)
 
 	// Helpers to read fields safely
 	get := func(rec []string, name string) string {
-		i, ok := idx[name]
+		i, ok := cols.index(name)
 		if !ok || i >= len(rec) {
 			return ""
 		}
@@ -328,7 +326,7 @@ func ParseTransactionsCSV(path string, lg *log.Logger) (*ParsedBatch, error) {
 	}
 
 	lg.Printf("Parsed CSV: %d transactions, %d items", len(transactions), len(items))
-	return &ParsedBatch{Transactions: transactions, Items: items}, nil
+	return &ParsedBatch{Transactions: transactions, Items: items, Drift: cols.drift(path)}, nil
 }
 
 func cleanUTF8(s string) string {
@@ -6,19 +6,21 @@ import (
 	"path/filepath"
 	"time"
 
-	"github.com/araquach/phorest-datahub/internal/repos"
+	"github.com/araquach/phorest-datahub/internal/config"
 )
 
+// Date format used by Phorest for startFilter/finishFilter
+const exportDateFmt = "2006-01-02"
+
 func (r *Runner) RunIncrementalTransactionsSync(ctx context.Context) error {
 	lg := r.Logger
-	db := r.DB
 
 	lg.Printf("▶️ Starting incremental TRANSACTIONS_CSV sync...")
 
-	wr := repos.NewWatermarksRepo(db, lg)
+	wr := r.watermarks()
 
-	// We'll iterate each branch separately
-	for _, b := range r.Cfg.Branches {
+	// Branches run in parallel; each waits on its own export job.
+	err := r.forEachBranch(ctx, SyncKindTransactions, func(ctx context.Context, b config.BranchConfig) error {
 		lg.Printf("🏢 Branch %s (%s): starting TRANSACTIONS_CSV sync", b.Name, b.BranchID)
 
 		// 1) Get per-branch watermark
@@ -27,95 +29,150 @@ func (r *Runner) RunIncrementalTransactionsSync(ctx context.Context) error {
 			return fmt.Errorf("get transactions_csv watermark for %s: %w", b.BranchID, err)
 		}
 
-		// 2) Build date window + filterExpression
-		var (
-			startDate  string
-			finishDate string
-			filterExpr string
-		)
-
-		// Date format used by Phorest for startFilter/finishFilter
-		const dateFmt = "2006-01-02"
-
+		var startDate string
+		sweep := false
 		if last == nil {
 			// No watermark yet for this branch:
 			// use some sensible "start of history" date
 			startDate = "2000-01-01"
 		} else {
-			// Use the *date* part of the last updated value
-			startDate = last.UTC().Format(dateFmt)
+			// Look back before the watermark so late edits/voids are re-pulled,
+			// then use the *date* part (the export filters by day)
+			var start time.Time
+			start, sweep, err = r.lookbackStart("transactions_csv", b.BranchID, *last)
+			if err != nil {
+				return fmt.Errorf("transactions_csv look-back for %s: %w", b.BranchID, err)
+			}
+			startDate = start.UTC().Format(exportDateFmt)
 		}
 
 		// Up to today
-		now := time.Now().UTC()
-		finishDate = now.Format(dateFmt)
-
-		// Build filterExpression per Phorest docs:
-		// updated=<2018-01-31T23:59:59.999Z&updated=>2018-01-01T00:0:00.000Z
-		fromTS := startDate + "T00:00:00.000Z"
-		toTS := finishDate + "T23:59:59.999Z"
-		filterExpr = fmt.Sprintf("updated=<%s&updated=>%s", toTS, fromTS)
-
-		lg.Printf("ℹ️ %s: using startFilter=%q finishFilter=%q filterExpression=%q",
-			b.BranchID, startDate, finishDate, filterExpr)
-
-		// 3) Create CSV export job
-		job, err := r.Export.CreateCSVExport(
-			ctx,
-			b.BranchID,
-			JobTypeTransactionsCSV, // "TRANSACTIONS_CSV"
-			filterExpr,
-			startDate,
-			finishDate,
-		)
+		finishDate := time.Now().UTC().Format(exportDateFmt)
+
+		stats, err := r.syncTransactionsWindow(ctx, b, startDate, finishDate)
 		if err != nil {
-			return fmt.Errorf("create TRANSACTIONS_CSV export for %s: %w", b.BranchID, err)
+			return err
 		}
-		lg.Printf("📝 %s: created TRANSACTIONS_CSV job %s (%s)", b.BranchID, job.JobID, job.JobStatus)
-
-		// 4) Poll job
-		waitMax := 5 * time.Minute
-		final, err := r.Export.WaitForCSVJob(
-			r.Cfg.PhorestBusiness,
-			b.BranchID,
-			job.JobID,
-			waitMax,
-		)
-		if err != nil {
-			// Special-case "No records found" so we don't treat it as a hard failure
-			if final != nil && final.FailureReason != nil && *final.FailureReason == "No records found" {
-				lg.Printf("ℹ️ %s: no new transactions in window %s..%s", b.BranchID, startDate, finishDate)
-				continue
+
+		if stats.MaxUpdated != nil {
+			if err := wr.UpsertLastUpdated("transactions_csv", b.BranchID, *stats.MaxUpdated); err != nil {
+				return Partial(fmt.Errorf("update transactions_csv watermark for %s: %w", b.BranchID, err))
 			}
-			return fmt.Errorf("wait for TRANSACTIONS_CSV job %s (%s): %w", job.JobID, b.BranchID, err)
 		}
-
-		if final.TempCSVExternalURL == nil || *final.TempCSVExternalURL == "" {
-			lg.Printf("⚠️ %s: job %s DONE but no csv URL; skipping import", b.BranchID, job.JobID)
-			continue
+		if sweep {
+			r.markSweep("transactions_csv", b.BranchID)
 		}
-		lg.Printf("📥 %s: job %s DONE, URL received", b.BranchID, job.JobID)
 
-		// 5) Download CSV to export dir
-		filename := fmt.Sprintf("transactions_incremental_%s_%s.csv", b.BranchID, now.Format("20060102_150405"))
-		dest := filepath.Join(r.Cfg.ExportDir, filename)
+		lg.Printf("✅ TRANSACTIONS_CSV incremental sync finished for %s", b.BranchID)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	lg.Printf("✅ All branches incremental TRANSACTIONS_CSV sync finished")
+	return nil
+}
+
+// RunTransactionsWindowSync re-exports transactions updated between from and to
+// (inclusive dates) for every configured branch, ignoring the watermark.
+// Upserts only overwrite rows with a newer updated_at_phorest, so re-running a
+// window is safe.
+func (r *Runner) RunTransactionsWindowSync(ctx context.Context, from, to time.Time) error {
+	lg := r.Logger
+
+	startDate := from.UTC().Format(exportDateFmt)
+	finishDate := to.UTC().Format(exportDateFmt)
+	lg.Printf("▶️ Starting TRANSACTIONS_CSV window sync %s..%s", startDate, finishDate)
 
-		if err := r.Export.DownloadCSV(*final.TempCSVExternalURL, dest); err != nil {
-			return fmt.Errorf("%s: download csv: %w", b.BranchID, err)
+	err := r.forEachBranch(ctx, SyncKindTransactions, func(ctx context.Context, b config.BranchConfig) error {
+		lg.Printf("🏢 Branch %s (%s): starting TRANSACTIONS_CSV window sync", b.Name, b.BranchID)
+
+		if _, err := r.syncTransactionsWindow(ctx, b, startDate, finishDate); err != nil {
+			return err
 		}
-		lg.Printf("💾 %s: saved TRANSACTIONS_CSV to %s", b.BranchID, dest)
 
-		// 6) Re-use your existing CSV import logic
-		if err := r.importSingleTransactionsCSV(dest); err != nil {
-			return fmt.Errorf("import incremental transactions csv %s: %w", dest, err)
+		lg.Printf("✅ TRANSACTIONS_CSV window sync finished for %s", b.BranchID)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	lg.Printf("✅ All branches TRANSACTIONS_CSV window sync finished")
+	return nil
+}
+
+// syncTransactionsWindow exports, downloads and imports one branch's
+// TRANSACTIONS_CSV for rows updated between startDate and finishDate (YYYY-MM-DD),
+// and adds the import's row counts to the run report.
+func (r *Runner) syncTransactionsWindow(ctx context.Context, b config.BranchConfig, startDate, finishDate string) (importStats, error) {
+	lg := r.Logger
+
+	// Build filterExpression per Phorest docs:
+	// updated=<2018-01-31T23:59:59.999Z&updated=>2018-01-01T00:0:00.000Z
+	fromTS := startDate + "T00:00:00.000Z"
+	toTS := finishDate + "T23:59:59.999Z"
+	filterExpr := fmt.Sprintf("updated=<%s&updated=>%s", toTS, fromTS)
+
+	lg.Printf("ℹ️ %s: using startFilter=%q finishFilter=%q filterExpression=%q",
+		b.BranchID, startDate, finishDate, filterExpr)
+
+	// Create CSV export job
+	job, err := r.Export.CreateCSVExport(
+		ctx,
+		b.BranchID,
+		JobTypeTransactionsCSV, // "TRANSACTIONS_CSV"
+		filterExpr,
+		startDate,
+		finishDate,
+	)
+	if err != nil {
+		return importStats{}, fmt.Errorf("create TRANSACTIONS_CSV export for %s: %w", b.BranchID, err)
+	}
+	lg.Printf("📝 %s: created TRANSACTIONS_CSV job %s (%s)", b.BranchID, job.JobID, job.JobStatus)
+
+	// Poll job
+	waitMax := 5 * time.Minute
+	final, err := r.Export.WaitForCSVJob(
+		ctx,
+		r.Cfg.PhorestBusiness,
+		b.BranchID,
+		job.JobID,
+		waitMax,
+	)
+	if err != nil {
+		// Special-case "No records found" so we don't treat it as a hard failure
+		if final != nil && final.FailureReason != nil && *final.FailureReason == "No records found" {
+			lg.Printf("ℹ️ %s: no new transactions in window %s..%s", b.BranchID, startDate, finishDate)
+			return importStats{}, nil
 		}
+		return importStats{}, fmt.Errorf("wait for TRANSACTIONS_CSV job %s (%s): %w", job.JobID, b.BranchID, err)
+	}
+
+	if final.TempCSVExternalURL == nil || *final.TempCSVExternalURL == "" {
+		lg.Printf("⚠️ %s: job %s DONE but no csv URL; skipping import", b.BranchID, job.JobID)
+		return importStats{}, nil
+	}
+	lg.Printf("📥 %s: job %s DONE, URL received", b.BranchID, job.JobID)
 
-		// Archive this CSV into the bootstrap transactions dir
-		r.archiveCSVToSeed(dest, "data/transactions")
+	// Download CSV to export dir
+	filename := fmt.Sprintf("transactions_incremental_%s_%s.csv", b.BranchID, time.Now().UTC().Format("20060102_150405"))
+	dest := filepath.Join(r.Cfg.ExportDir, filename)
 
-		lg.Printf("✅ TRANSACTIONS_CSV incremental sync finished for %s", b.BranchID)
+	if err := r.Export.DownloadCSV(*final.TempCSVExternalURL, dest); err != nil {
+		return importStats{}, fmt.Errorf("%s: download csv: %w", b.BranchID, err)
 	}
+	lg.Printf("💾 %s: saved TRANSACTIONS_CSV to %s", b.BranchID, dest)
 
-	lg.Printf("✅ All branches incremental TRANSACTIONS_CSV sync finished")
-	return nil
+	// Re-use your existing CSV import logic
+	stats, err := r.importSingleTransactionsCSV(dest)
+	if err != nil {
+		return stats, fmt.Errorf("import incremental transactions csv %s: %w", dest, err)
+	}
+	r.count(SyncKindTransactions, b.BranchID, stats.Rows, stats.Changed)
+
+	// Archive this CSV into the bootstrap transactions dir
+	r.archiveCSVToSeed(dest, "data/transactions")
+	return stats, nil
 }
@@ -0,0 +1,324 @@
+package reports
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/araquach/phorest-datahub/internal/repos"
+	"github.com/araquach/phorest-datahub/internal/services"
+	"gorm.io/gorm"
+)
+
+// KPIRow is one line of the KPI table: this period vs the previous one vs the branch.
+type KPIRow struct {
+	Key       string
+	Label     string
+	Kind      services.MetricKind
+	Current   float64
+	Previous  float64
+	ChangePct *float64 // nil when the previous value is zero
+	BranchAvg float64
+}
+
+// TargetRow is one line of the target attainment table.
+type TargetRow struct {
+	Label      string
+	Kind       services.MetricKind
+	Target     float64
+	Actual     float64
+	Attainment float64 // Actual / Target (1.0 = 100%)
+}
+
+// ReviewExcerpt is a trimmed review quote for the report.
+type ReviewExcerpt struct {
+	Date       string
+	Rating     int
+	ClientName string
+	Text       string
+}
+
+// AppraisalReport is everything needed to render an appraisal deck for one stylist.
+type AppraisalReport struct {
+	GeneratedAt    time.Time
+	StaffID        string
+	StaffName      string
+	StaffCategory  string
+	BranchID       string
+	BranchName     string
+	Currency       string
+	Period         services.Period
+	PreviousPeriod services.Period
+
+	KPIs           []KPIRow
+	TopServices    []services.ServiceRevenue
+	RetailProducts []services.ProductSales
+	Reviews        []ReviewExcerpt
+	Targets        []TargetRow
+
+	// ReviewThemes is the sentiment/topic rollup of this period's reviews.
+	ReviewThemes services.StaffReviewInsights
+
+	// BackBar is the stylist's estimated professional product cost (nil
+	// with no services); BranchBackBar is the branch's for comparison.
+	BackBar       *services.StaffBackBar
+	BranchBackBar services.BackBarTotals
+
+	// Utilisation is the stylist's booked vs rostered time by week (nil
+	// when neither rostered nor booked); BranchUtilisation is the branch's.
+	Utilisation       *services.StaffUtilisation
+	BranchUtilisation services.UtilisationTotals
+}
+
+// AppraisalBuilder assembles AppraisalReports from the datahub tables.
+type AppraisalBuilder struct {
+	kpis     *services.KPIService
+	insights *services.ReviewInsightsService
+	staff    *services.StaffService
+	stock    *services.StockService
+	util     *services.UtilisationService
+	reviews  *repos.ReviewsRepo
+	targets  *repos.AppraisalTargetsRepo
+	lg       *log.Logger
+
+	MaxReviews  int
+	MaxServices int
+	MaxProducts int
+}
+
+func NewAppraisalBuilder(db *gorm.DB, lg *log.Logger) *AppraisalBuilder {
+	return &AppraisalBuilder{
+		kpis:        services.NewKPIService(db, lg),
+		insights:    services.NewReviewInsightsService(db, lg),
+		staff:       services.NewStaffService(db, lg),
+		stock:       services.NewStockService(db, lg),
+		util:        services.NewUtilisationService(db, lg),
+		reviews:     repos.NewReviewsRepo(db, lg),
+		targets:     repos.NewAppraisalTargetsRepo(db, lg),
+		lg:          lg,
+		MaxReviews:  8,
+		MaxServices: 10,
+		MaxProducts: 15,
+	}
+}
+
+// Build gathers the data for one stylist and period. branchID may be empty,
+// in which case the stylist's primary (non-archived) branch is used.
+func (b *AppraisalBuilder) Build(ctx context.Context, staffID, branchID string, p services.Period) (*AppraisalReport, error) {
+	st, err := b.staff.Find(ctx, staffID, branchID)
+	if err != nil {
+		return nil, err
+	}
+	branchID = st.BranchID
+
+	rep := &AppraisalReport{
+		GeneratedAt:    time.Now().UTC(),
+		StaffID:        st.StaffID,
+		StaffName:      strings.TrimSpace(st.FirstName + " " + st.LastName),
+		StaffCategory:  st.StaffCategoryName,
+		BranchID:       branchID,
+		BranchName:     branchID,
+		Period:         p,
+		PreviousPeriod: p.Previous(),
+	}
+
+	br, err := b.staff.Branch(ctx, branchID)
+	if err != nil {
+		return nil, fmt.Errorf("load branch %s: %w", branchID, err)
+	}
+	if br != nil {
+		if br.Name != "" {
+			rep.BranchName = br.Name
+		}
+		rep.Currency = br.CurrencyCode
+	}
+
+	// --- KPIs: current vs previous vs branch average ---
+	cur, err := b.kpis.StaffKPIs(ctx, staffID, branchID, p)
+	if err != nil {
+		return nil, fmt.Errorf("current KPIs: %w", err)
+	}
+	prev, err := b.kpis.StaffKPIs(ctx, staffID, branchID, rep.PreviousPeriod)
+	if err != nil {
+		return nil, fmt.Errorf("previous KPIs: %w", err)
+	}
+	avg, err := b.kpis.BranchAverages(ctx, branchID, p)
+	if err != nil {
+		return nil, fmt.Errorf("branch averages: %w", err)
+	}
+
+	for _, m := range services.KPIMetrics {
+		c, _ := cur.Metric(m.Key)
+		pv, _ := prev.Metric(m.Key)
+		row := KPIRow{
+			Key:       m.Key,
+			Label:     m.Label,
+			Kind:      m.Kind,
+			Current:   c,
+			Previous:  pv,
+			BranchAvg: avg[m.Key],
+		}
+		if pv != 0 {
+			ch := (c - pv) / pv * 100
+			row.ChangePct = &ch
+		}
+		rep.KPIs = append(rep.KPIs, row)
+	}
+
+	// --- Top services + retail ---
+	if rep.TopServices, err = b.kpis.TopServices(ctx, staffID, branchID, p, b.MaxServices); err != nil {
+		return nil, fmt.Errorf("top services: %w", err)
+	}
+	if rep.RetailProducts, err = b.kpis.RetailProducts(ctx, staffID, branchID, p, b.MaxProducts); err != nil {
+		return nil, fmt.Errorf("retail products: %w", err)
+	}
+
+	// --- Review excerpts ---
+	reviews, err := b.reviews.ListForStaff(staffID, branchID, p.From, p.To, b.MaxReviews)
+	if err != nil {
+		return nil, fmt.Errorf("reviews: %w", err)
+	}
+	for _, rv := range reviews {
+		ex := ReviewExcerpt{
+			Rating:     rv.Rating,
+			ClientName: rv.ClientFirstName,
+			Text:       excerpt(rv.Text, 280),
+		}
+		if rv.ReviewDate != nil {
+			ex.Date = rv.ReviewDate.Format("2006-01-02")
+		}
+		rep.Reviews = append(rep.Reviews, ex)
+	}
+
+	// --- Review themes (from internal/analysis) ---
+	if rep.ReviewThemes, err = b.insights.StaffInsights(ctx, staffID, branchID, p); err != nil {
+		return nil, fmt.Errorf("review themes: %w", err)
+	}
+
+	// --- Back-bar product usage ---
+	bb, err := b.stock.BackBarUsage(ctx, branchID, p)
+	if err != nil {
+		return nil, fmt.Errorf("back-bar usage: %w", err)
+	}
+	rep.BackBar = bb.ForStaff(staffID)
+	rep.BranchBackBar = bb.BackBarTotals
+
+	// --- Utilisation ---
+	ut, err := b.util.Utilisation(ctx, branchID, p)
+	if err != nil {
+		return nil, fmt.Errorf("utilisation: %w", err)
+	}
+	rep.Utilisation = ut.ForStaff(staffID)
+	rep.BranchUtilisation = ut.UtilisationTotals
+
+	// --- Targets ---
+	targets, err := b.targets.ListForPeriod(staffID, branchID, p.From, p.To)
+	if err != nil {
+		return nil, fmt.Errorf("targets: %w", err)
+	}
+	for _, t := range targets {
+		actual, ok := cur.Metric(t.Metric)
+		if !ok {
+			b.lg.Printf("⚠️  Unknown target metric %q for %s; skipping", t.Metric, staffID)
+			continue
+		}
+		row := TargetRow{
+			Label:  t.Metric,
+			Kind:   services.KindCount,
+			Target: t.TargetValue,
+			Actual: actual,
+		}
+		for _, m := range services.KPIMetrics {
+			if m.Key == t.Metric {
+				row.Label = m.Label
+				row.Kind = m.Kind
+				break
+			}
+		}
+		if t.TargetValue != 0 {
+			row.Attainment = actual / t.TargetValue
+		}
+		rep.Targets = append(rep.Targets, row)
+	}
+
+	return rep, nil
+}
+
+// excerpt trims review text to n runes on a word boundary.
+func excerpt(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	cut := string(r[:n])
+	if i := strings.LastIndex(cut, " "); i > n/2 {
+		cut = cut[:i]
+	}
+	return cut + "…"
+}
+
+// TopicLabel turns an analysis topic key ("wait_time") into a heading ("Wait time").
+func TopicLabel(topic string) string {
+	s := strings.ReplaceAll(topic, "_", " ")
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// FormatSentiment renders a -1..1 sentiment score, e.g. "+0.42".
+func FormatSentiment(v float64) string {
+	return fmt.Sprintf("%+.2f", v)
+}
+
+// FormatValue renders a KPI value according to its kind.
+func FormatValue(kind services.MetricKind, v float64, currency string) string {
+	switch kind {
+	case services.KindMoney:
+		if currency != "" {
+			return currency + " " + formatThousands(v, 2)
+		}
+		return formatThousands(v, 2)
+	case services.KindPercent:
+		return fmt.Sprintf("%.1f%%", v*100)
+	case services.KindRating:
+		return fmt.Sprintf("%.2f", v)
+	default:
+		return formatThousands(v, 0)
+	}
+}
+
+// FormatChange renders a period-on-period change, e.g. "+12.5%".
+func FormatChange(pct *float64) string {
+	if pct == nil {
+		return "–"
+	}
+	return fmt.Sprintf("%+.1f%%", *pct)
+}
+
+func formatThousands(v float64, decimals int) string {
+	s := fmt.Sprintf("%.*f", decimals, v)
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	intPart, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, frac = s[:i], s[i:]
+	}
+
+	var b strings.Builder
+	for i, c := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(c)
+	}
+	out := b.String() + frac
+	if neg {
+		out = "-" + out
+	}
+	return out
+}
@@ -0,0 +1,68 @@
+package reports
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/araquach/phorest-datahub/internal/services"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// RenderHTML writes the report as a single self-contained HTML page (inline CSS, no assets).
+func RenderHTML(w io.Writer, rep *AppraisalReport) error {
+	funcs := template.FuncMap{
+		"value": func(kind services.MetricKind, v float64) string {
+			return FormatValue(kind, v, rep.Currency)
+		},
+		"money": func(v float64) string {
+			return FormatValue(services.KindMoney, v, rep.Currency)
+		},
+		"count": func(v float64) string {
+			return FormatValue(services.KindCount, v, "")
+		},
+		"percent": func(v float64) string {
+			return FormatValue(services.KindPercent, v, "")
+		},
+		"ratio": func(a, b float64) float64 {
+			if b == 0 {
+				return 0
+			}
+			return a / b
+		},
+		"hours":     FormatHours,
+		"change":    FormatChange,
+		"topic":     TopicLabel,
+		"sentiment": FormatSentiment,
+		"trend": func(pct *float64) string {
+			switch {
+			case pct == nil:
+				return ""
+			case *pct > 0:
+				return "up"
+			case *pct < 0:
+				return "down"
+			}
+			return ""
+		},
+		"stars": func(n int) string {
+			if n < 0 {
+				n = 0
+			}
+			if n > 5 {
+				n = 5
+			}
+			return strings.Repeat("★", n) + strings.Repeat("☆", 5-n)
+		},
+	}
+
+	tmpl, err := template.New("appraisal.html.tmpl").Funcs(funcs).ParseFS(templateFS, "templates/appraisal.html.tmpl")
+	if err != nil {
+		return fmt.Errorf("parse appraisal template: %w", err)
+	}
+	return tmpl.Execute(w, rep)
+}
@@ -0,0 +1,306 @@
+package reports
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/go-pdf/fpdf"
+
+	"github.com/araquach/phorest-datahub/internal/services"
+)
+
+// RenderPDF writes the report as an A4 PDF using the pure-Go fpdf renderer
+// (core fonts only, so it needs no font files or network access).
+func RenderPDF(w io.Writer, rep *AppraisalReport) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.SetTitle(fmt.Sprintf("Appraisal – %s – %s", rep.StaffName, rep.Period), true)
+	pdf.AliasNbPages("")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.SetTextColor(120, 120, 120)
+		pdf.CellFormat(0, 6, fmt.Sprintf("Page %d/{nb}", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+
+	// Core fonts are cp1252; translate UTF-8 (en dashes, £, €, accents) on the way in.
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.AddPage()
+
+	// --- Header ---
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.SetTextColor(30, 30, 30)
+	pdf.CellFormat(0, 10, tr(rep.StaffName), "", 1, "L", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.SetTextColor(100, 100, 100)
+	sub := rep.BranchName
+	if rep.StaffCategory != "" {
+		sub = rep.StaffCategory + " · " + sub
+	}
+	pdf.CellFormat(0, 5, tr(sub), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 5, tr(fmt.Sprintf("Period: %s (compared with %s)", rep.Period, rep.PreviousPeriod)), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 5, tr("Generated "+rep.GeneratedAt.Format("2006-01-02 15:04 MST")), "", 1, "L", false, 0, "")
+
+	section := func(title string) {
+		pdf.Ln(6)
+		pdf.SetFont("Helvetica", "B", 13)
+		pdf.SetTextColor(30, 30, 30)
+		pdf.CellFormat(0, 8, tr(title), "B", 1, "L", false, 0, "")
+		pdf.Ln(2)
+	}
+	empty := func(msg string) {
+		pdf.SetFont("Helvetica", "I", 9)
+		pdf.SetTextColor(130, 130, 130)
+		pdf.CellFormat(0, 6, tr(msg), "", 1, "L", false, 0, "")
+	}
+	table := func(widths []float64, aligns []string, header []string, rows [][]string) {
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.SetFillColor(240, 240, 240)
+		pdf.SetTextColor(30, 30, 30)
+		for i, h := range header {
+			pdf.CellFormat(widths[i], 7, tr(h), "B", 0, aligns[i], true, 0, "")
+		}
+		pdf.Ln(-1)
+
+		pdf.SetFont("Helvetica", "", 9)
+		for _, row := range rows {
+			for i, c := range row {
+				pdf.CellFormat(widths[i], 6, tr(c), "B", 0, aligns[i], false, 0, "")
+			}
+			pdf.Ln(-1)
+		}
+	}
+	val := func(kind services.MetricKind, v float64) string {
+		return FormatValue(kind, v, rep.Currency)
+	}
+
+	// --- KPIs ---
+	section("Key performance indicators")
+	{
+		var rows [][]string
+		for _, k := range rep.KPIs {
+			rows = append(rows, []string{
+				k.Label,
+				val(k.Kind, k.Current),
+				val(k.Kind, k.Previous),
+				FormatChange(k.ChangePct),
+				val(k.Kind, k.BranchAvg),
+			})
+		}
+		table(
+			[]float64{50, 35, 35, 25, 35},
+			[]string{"L", "R", "R", "R", "R"},
+			[]string{"Metric", "This period", "Previous", "Change", "Branch avg"},
+			rows,
+		)
+	}
+
+	// --- Targets ---
+	section("Target attainment")
+	if len(rep.Targets) == 0 {
+		empty("No targets set for this period.")
+	} else {
+		var rows [][]string
+		for _, t := range rep.Targets {
+			rows = append(rows, []string{
+				t.Label,
+				val(t.Kind, t.Target),
+				val(t.Kind, t.Actual),
+				FormatValue(services.KindPercent, t.Attainment, ""),
+			})
+		}
+		table(
+			[]float64{60, 40, 40, 40},
+			[]string{"L", "R", "R", "R"},
+			[]string{"Target", "Goal", "Actual", "Attainment"},
+			rows,
+		)
+	}
+
+	// --- Utilisation ---
+	section("Utilisation")
+	if ut := rep.Utilisation; ut == nil {
+		empty("No roster or bookings recorded in this period.")
+	} else {
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.SetTextColor(60, 60, 60)
+		pdf.CellFormat(0, 6, tr(fmt.Sprintf("Booked %s of %s available · %s utilisation (branch %s) · %s no-shows",
+			FormatHours(ut.BookedMinutes), FormatHours(ut.AvailableMinutes),
+			FormatValue(services.KindPercent, ut.Utilisation, ""), FormatValue(services.KindPercent, rep.BranchUtilisation.Utilisation, ""),
+			FormatHours(ut.NoShowMinutes))), "", 1, "L", false, 0, "")
+
+		var rows [][]string
+		for _, wk := range ut.Weeks {
+			pct := "–"
+			if wk.AvailableMinutes > 0 {
+				pct = FormatValue(services.KindPercent, wk.Utilisation, "")
+			}
+			rows = append(rows, []string{
+				wk.WeekStart,
+				FormatHours(wk.RosteredMinutes),
+				FormatHours(wk.BreakMinutes) + " / " + FormatHours(wk.TimeOffMinutes),
+				FormatHours(wk.AvailableMinutes),
+				FormatHours(wk.BookedMinutes),
+				pct,
+			})
+		}
+		table(
+			[]float64{35, 25, 45, 25, 25, 25},
+			[]string{"L", "R", "R", "R", "R", "R"},
+			[]string{"Week of", "Rostered", "Breaks / time off", "Available", "Booked", "Utilisation"},
+			rows,
+		)
+	}
+
+	// --- Top services ---
+	section("Top services by revenue")
+	if len(rep.TopServices) == 0 {
+		empty("No services recorded in this period.")
+	} else {
+		var rows [][]string
+		for _, s := range rep.TopServices {
+			rows = append(rows, []string{
+				s.ServiceName,
+				FormatValue(services.KindCount, s.Count, ""),
+				val(services.KindMoney, s.Revenue),
+			})
+		}
+		table(
+			[]float64{110, 30, 40},
+			[]string{"L", "R", "R"},
+			[]string{"Service", "Count", "Revenue"},
+			rows,
+		)
+	}
+
+	// --- Retail ---
+	section("Retail products sold")
+	if len(rep.RetailProducts) == 0 {
+		empty("No retail sales recorded in this period.")
+	} else {
+		var rows [][]string
+		for _, p := range rep.RetailProducts {
+			rows = append(rows, []string{
+				p.ProductName,
+				p.BrandName,
+				FormatValue(services.KindCount, p.Units, ""),
+				val(services.KindMoney, p.Revenue),
+			})
+		}
+		table(
+			[]float64{85, 45, 20, 30},
+			[]string{"L", "L", "R", "R"},
+			[]string{"Product", "Brand", "Units", "Revenue"},
+			rows,
+		)
+	}
+
+	// --- Back-bar usage ---
+	section("Back-bar product usage")
+	if bb := rep.BackBar; bb == nil {
+		empty("No services recorded in this period.")
+	} else {
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.SetTextColor(60, 60, 60)
+		pdf.CellFormat(0, 6, tr(fmt.Sprintf("Estimated product cost %s · %s per service · %s of service revenue (branch %s)",
+			val(services.KindMoney, bb.ProductCost), val(services.KindMoney, bb.CostPerService),
+			FormatValue(services.KindPercent, bb.CostPct, ""), FormatValue(services.KindPercent, rep.BranchBackBar.CostPct, ""))), "", 1, "L", false, 0, "")
+
+		var rows [][]string
+		for _, l := range bb.Lines {
+			pct := "–"
+			if l.Revenue != 0 {
+				pct = FormatValue(services.KindPercent, l.ProductCost/l.Revenue, "")
+			}
+			rows = append(rows, []string{
+				l.ServiceName,
+				FormatValue(services.KindCount, l.Services, ""),
+				val(services.KindMoney, l.Revenue),
+				val(services.KindMoney, l.ProductCost),
+				pct,
+			})
+		}
+		table(
+			[]float64{80, 20, 30, 30, 20},
+			[]string{"L", "R", "R", "R", "R"},
+			[]string{"Service", "Count", "Revenue", "Product cost", "Cost %"},
+			rows,
+		)
+	}
+
+	// --- Review themes ---
+	section("Review themes")
+	if th := rep.ReviewThemes; th.Reviews == 0 {
+		empty("No analysed reviews in this period.")
+	} else {
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.SetTextColor(60, 60, 60)
+		pdf.CellFormat(0, 6, tr(fmt.Sprintf("%d analysed reviews · sentiment %s (%d positive, %d neutral, %d negative)",
+			th.Reviews, FormatSentiment(th.AvgSentiment), th.Positive, th.Neutral, th.Negative)), "", 1, "L", false, 0, "")
+
+		if len(th.Topics) > 0 {
+			var rows [][]string
+			for _, t := range th.Topics {
+				rows = append(rows, []string{
+					TopicLabel(t.Topic),
+					fmt.Sprint(t.Reviews),
+					fmt.Sprint(t.Praise),
+					fmt.Sprint(t.Complaints),
+					FormatSentiment(t.AvgSentiment),
+				})
+			}
+			table(
+				[]float64{60, 30, 30, 30, 30},
+				[]string{"L", "R", "R", "R", "R"},
+				[]string{"Topic", "Reviews", "Praise", "Complaints", "Sentiment"},
+				rows,
+			)
+		}
+
+		quotes := func(title string, qs []services.SnippetQuote) {
+			if len(qs) == 0 {
+				return
+			}
+			pdf.Ln(2)
+			pdf.SetFont("Helvetica", "B", 9)
+			pdf.SetTextColor(30, 30, 30)
+			pdf.CellFormat(0, 5, tr(title), "", 1, "L", false, 0, "")
+			pdf.SetFont("Helvetica", "I", 9)
+			pdf.SetTextColor(60, 60, 60)
+			for _, q := range qs {
+				pdf.MultiCell(0, 4.5, tr(fmt.Sprintf("“%s” (%s, %s)", q.Text, TopicLabel(q.Topic), q.ReviewDate)), "", "L", false)
+			}
+		}
+		quotes("Praise", th.Praise)
+		quotes("To coach on", th.Complaints)
+	}
+
+	// --- Reviews ---
+	section("What clients said")
+	if len(rep.Reviews) == 0 {
+		empty("No written reviews in this period.")
+	} else {
+		for _, rv := range rep.Reviews {
+			pdf.SetFont("Helvetica", "B", 9)
+			pdf.SetTextColor(30, 30, 30)
+			who := rv.Date
+			if rv.ClientName != "" {
+				who = rv.ClientName + ", " + rv.Date
+			}
+			pdf.CellFormat(0, 5, tr(fmt.Sprintf("%d/5 – %s", rv.Rating, who)), "", 1, "L", false, 0, "")
+
+			pdf.SetFont("Helvetica", "I", 9)
+			pdf.SetTextColor(60, 60, 60)
+			pdf.MultiCell(0, 4.5, tr("“"+rv.Text+"”"), "", "L", false)
+			pdf.Ln(2)
+		}
+	}
+
+	if err := pdf.Error(); err != nil {
+		return fmt.Errorf("render appraisal pdf: %w", err)
+	}
+	return pdf.Output(w)
+}
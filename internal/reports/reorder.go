@@ -0,0 +1,113 @@
+package reports
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+
+	"github.com/araquach/phorest-datahub/internal/services"
+	"gorm.io/gorm"
+)
+
+// ReorderDocument is one branch's reorder list with the branch details
+// needed to render it.
+type ReorderDocument struct {
+	*services.ReorderReport
+	BranchName string
+	Currency   string
+}
+
+// ReorderBuilder assembles ReorderDocuments.
+type ReorderBuilder struct {
+	stock *services.StockService
+	staff *services.StaffService
+	lg    *log.Logger
+}
+
+func NewReorderBuilder(db *gorm.DB, lg *log.Logger) *ReorderBuilder {
+	return &ReorderBuilder{
+		stock: services.NewStockService(db, lg),
+		staff: services.NewStaffService(db, lg),
+		lg:    lg,
+	}
+}
+
+// Build loads the reorder list for a branch.
+func (b *ReorderBuilder) Build(ctx context.Context, branchID string) (*ReorderDocument, error) {
+	rep, err := b.stock.Reorder(ctx, branchID)
+	if err != nil {
+		return nil, fmt.Errorf("reorder list for %s: %w", branchID, err)
+	}
+	doc := &ReorderDocument{ReorderReport: rep, BranchName: branchID}
+
+	br, err := b.staff.Branch(ctx, branchID)
+	if err != nil {
+		return nil, fmt.Errorf("load branch %s: %w", branchID, err)
+	}
+	if br != nil {
+		if br.Name != "" {
+			doc.BranchName = br.Name
+		}
+		doc.Currency = br.CurrencyCode
+	}
+	return doc, nil
+}
+
+// RenderReorderHTML writes the reorder list as a self-contained HTML page,
+// one table per brand.
+func RenderReorderHTML(w io.Writer, doc *ReorderDocument) error {
+	funcs := template.FuncMap{
+		"money": func(v float64) string {
+			return FormatValue(services.KindMoney, v, doc.Currency)
+		},
+		"moneyp": func(v *float64) string {
+			if v == nil {
+				return "–"
+			}
+			return FormatValue(services.KindMoney, *v, doc.Currency)
+		},
+		"qty": csvNum,
+		"qtyp": func(v *float64) string {
+			if v == nil {
+				return "–"
+			}
+			return csvNum(*v)
+		},
+		"brand": func(name string) string {
+			if name == "" {
+				return "No brand"
+			}
+			return name
+		},
+	}
+
+	tmpl, err := template.New("reorder.html.tmpl").Funcs(funcs).ParseFS(templateFS, "templates/reorder.html.tmpl")
+	if err != nil {
+		return fmt.Errorf("parse reorder template: %w", err)
+	}
+	return tmpl.Execute(w, doc)
+}
+
+// ReorderCSVHeader is the column order written by WriteReorderCSV.
+var ReorderCSVHeader = []string{
+	"branch_id", "brand_name", "product_id", "code", "product_name", "category_name",
+	"quantity_in_stock", "min_quantity", "max_quantity", "suggested_qty", "unit_cost", "line_cost",
+}
+
+// WriteReorderCSV writes one row per reorder line, ready to paste into a
+// supplier order.
+func WriteReorderCSV(w io.Writer, doc *ReorderDocument) error {
+	rows := make([][]string, 0, doc.Lines)
+	for _, b := range doc.Brands {
+		for _, l := range b.Lines {
+			rows = append(rows, []string{
+				l.BranchID, l.BrandName, l.ProductID, l.Code, l.ProductName, l.CategoryName,
+				csvNum(l.QuantityInStock), csvNum(l.MinQuantity), csvNumPtr(l.MaxQuantity),
+				csvNum(l.SuggestedQty), csvMoneyPtr(l.UnitCost), csvMoneyPtr(l.LineCost),
+			})
+		}
+	}
+	return writeCSV(w, ReorderCSVHeader, rows)
+}
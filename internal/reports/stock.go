@@ -0,0 +1,162 @@
+package reports
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/araquach/phorest-datahub/internal/models"
+	"github.com/araquach/phorest-datahub/internal/services"
+)
+
+// CSV exports for the stock reports. Quantities and money are plain numbers
+// (no currency) so the files open cleanly in a spreadsheet.
+
+// WriteValuationCSV writes one row per product with stock on hand.
+func WriteValuationCSV(w io.Writer, v *services.StockValuation) error {
+	rows := make([][]string, 0, len(v.Lines))
+	for _, l := range v.Lines {
+		rows = append(rows, []string{
+			v.BranchID, v.AsOf.Format("2006-01-02"), l.BrandName, l.ProductID, l.ProductName, l.CategoryName,
+			csvNum(l.Quantity), csvMoneyPtr(l.UnitCost), csvMoneyPtr(l.UnitPrice),
+			csvMoney(l.CostValue), csvMoney(l.RetailValue), csvDate(l.StockDate),
+		})
+	}
+	return writeCSV(w, []string{
+		"branch_id", "as_of", "brand_name", "product_id", "product_name", "category_name",
+		"quantity", "unit_cost", "unit_price", "cost_value", "retail_value", "stock_date",
+	}, rows)
+}
+
+// WriteMovementsCSV writes one row per product's opening/closing stock.
+func WriteMovementsCSV(w io.Writer, branchID string, p services.Period, moves []services.StockMovement) error {
+	rows := make([][]string, 0, len(moves))
+	for _, m := range moves {
+		rows = append(rows, []string{
+			branchID, p.From.Format("2006-01-02"), p.To.Format("2006-01-02"),
+			m.BrandName, m.ProductID, m.ProductName,
+			csvNum(m.Opening), csvNum(m.Received), csvNum(m.Removed), csvNum(m.Reconciled), csvNum(m.Closing), csvNum(m.Net),
+			strconv.FormatBool(m.FirstSeen),
+		})
+	}
+	return writeCSV(w, []string{
+		"branch_id", "from", "to", "brand_name", "product_id", "product_name",
+		"opening", "received", "removed", "reconciled", "closing", "net", "first_seen",
+	}, rows)
+}
+
+// WriteDeadStockCSV writes one row per unsold product.
+func WriteDeadStockCSV(w io.Writer, branchID string, days int, lines []services.DeadStockLine) error {
+	rows := make([][]string, 0, len(lines))
+	for _, l := range lines {
+		since := ""
+		if l.DaysSinceSale != nil {
+			since = strconv.Itoa(*l.DaysSinceSale)
+		}
+		rows = append(rows, []string{
+			branchID, strconv.Itoa(days), l.BrandName, l.ProductID, l.ProductName, l.CategoryName,
+			csvNum(l.QuantityInStock), csvMoney(l.CostValue), csvMoney(l.RetailValue),
+			csvDate(l.LastSold), since,
+		})
+	}
+	return writeCSV(w, []string{
+		"branch_id", "unsold_days", "brand_name", "product_id", "product_name", "category_name",
+		"quantity_in_stock", "cost_value", "retail_value", "last_sold", "days_since_sale",
+	}, rows)
+}
+
+// WriteShrinkageCSV writes one row per reconciled product.
+func WriteShrinkageCSV(w io.Writer, rows []models.StockShrinkage) error {
+	out := make([][]string, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, []string{
+			r.BranchID, r.PeriodStart.Format("2006-01-02"), r.PeriodEnd.Format("2006-01-02"),
+			r.BrandName, r.ProductID, r.ProductName, r.Usage,
+			csvNum(r.Received), csvNum(r.Removed), csvNum(r.Sold),
+			csvNum(r.ProfessionalUse), csvNum(r.Shrinkage), csvNum(r.UnmatchedSales),
+			csvMoneyPtr(r.UnitCost), csvMoney(r.ProfessionalCost), csvMoney(r.ShrinkageCost),
+			strconv.FormatBool(r.Flagged),
+		})
+	}
+	return writeCSV(w, []string{
+		"branch_id", "from", "to", "brand_name", "product_id", "product_name", "usage",
+		"received", "removed", "sold", "professional_use", "shrinkage", "unmatched_sales",
+		"unit_cost", "professional_cost", "shrinkage_cost", "flagged",
+	}, out)
+}
+
+// WriteBackBarCSV writes one row per stylist and service.
+func WriteBackBarCSV(w io.Writer, rep *services.BackBarReport) error {
+	var out [][]string
+	for _, st := range rep.Staff {
+		for _, l := range st.Lines {
+			out = append(out, []string{
+				rep.BranchID, rep.Period.From.Format("2006-01-02"), rep.Period.To.Format("2006-01-02"),
+				l.StaffID, l.StaffName, l.ServiceID, l.ServiceName, l.CategoryName, strconv.FormatBool(l.Colour),
+				csvNum(l.Services), csvMoney(l.Revenue), csvMoney(l.ProductCost),
+			})
+		}
+	}
+	return writeCSV(w, []string{
+		"branch_id", "from", "to", "staff_id", "staff_name", "service_id", "service_name", "category_name", "colour",
+		"services", "revenue", "product_cost",
+	}, out)
+}
+
+func writeCSV(w io.Writer, header []string, rows [][]string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return fmt.Errorf("write header: %w", err)
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return fmt.Errorf("write rows: %w", err)
+	}
+	return nil
+}
+
+func csvNum(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+
+func csvNumPtr(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return csvNum(*v)
+}
+
+func csvMoney(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+
+func csvMoneyPtr(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return csvMoney(*v)
+}
+
+func csvDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+// WritePriceRealisationCSV writes one row per stylist and service
+// (realisation as a 0..1 fraction).
+func WritePriceRealisationCSV(w io.Writer, rep *services.PriceRealisationReport) error {
+	var out [][]string
+	for _, st := range rep.Staff {
+		for _, l := range st.Lines {
+			out = append(out, []string{
+				rep.BranchID, rep.Period.From.Format("2006-01-02"), rep.Period.To.Format("2006-01-02"),
+				l.StaffID, l.StaffName, l.ServiceID, l.ServiceName, l.CategoryName,
+				csvNum(l.Services), csvNum(l.Unpriced), csvMoney(l.ListValue), csvMoney(l.Realised), csvMoney(l.Discount),
+				strconv.FormatFloat(l.Realisation, 'f', 4, 64),
+			})
+		}
+	}
+	return writeCSV(w, []string{
+		"branch_id", "from", "to", "staff_id", "staff_name", "service_id", "service_name", "category_name",
+		"services", "unpriced", "list_value", "realised", "discount", "realisation",
+	}, out)
+}
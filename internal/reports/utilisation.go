@@ -0,0 +1,35 @@
+package reports
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/araquach/phorest-datahub/internal/services"
+)
+
+// WriteUtilisationCSV writes one row per stylist and day (minutes, and
+// utilisation as a 0..1 fraction).
+func WriteUtilisationCSV(w io.Writer, rep *services.UtilisationReport) error {
+	var out [][]string
+	for _, st := range rep.Staff {
+		for _, d := range st.Days {
+			out = append(out, []string{
+				rep.BranchID, st.StaffID, st.StaffName, d.Date,
+				csvNum(d.RosteredMinutes), csvNum(d.BreakMinutes), csvNum(d.TimeOffMinutes), csvNum(d.AvailableMinutes),
+				csvNum(d.BookedMinutes), csvNum(d.NoShowMinutes), strconv.FormatFloat(d.Utilisation, 'f', 4, 64),
+				strconv.FormatBool(d.Estimated),
+			})
+		}
+	}
+	return writeCSV(w, []string{
+		"branch_id", "staff_id", "staff_name", "date",
+		"rostered_minutes", "break_minutes", "time_off_minutes", "available_minutes",
+		"booked_minutes", "no_show_minutes", "utilisation", "estimated",
+	}, out)
+}
+
+// FormatHours renders a number of minutes as hours, e.g. "37.5h".
+func FormatHours(minutes float64) string {
+	return fmt.Sprintf("%.1fh", minutes/60)
+}
@@ -0,0 +1,64 @@
+package repos
+
+import (
+	"log"
+	"time"
+
+	"github.com/araquach/phorest-datahub/internal/models"
+	"gorm.io/gorm"
+)
+
+type AlertsRepo struct {
+	db *gorm.DB
+	lg *log.Logger
+}
+
+func NewAlertsRepo(db *gorm.DB, lg *log.Logger) *AlertsRepo {
+	return &AlertsRepo{db: db, lg: lg}
+}
+
+// Raise opens an alert, or refreshes the open one for the same
+// kind/branch/staff. a is filled in from the stored row; raised reports
+// whether it is new (and so should be notified).
+func (r *AlertsRepo) Raise(a *models.Alert) (raised bool, err error) {
+	now := time.Now().UTC()
+	var out struct {
+		models.Alert
+		Inserted bool `gorm:"column:inserted"`
+	}
+	err = r.db.Raw(`
+INSERT INTO alerts (kind, severity, branch_id, staff_id, title, detail, value, baseline, raised_at, last_seen_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (kind, branch_id, staff_id) WHERE resolved_at IS NULL DO UPDATE
+SET severity     = EXCLUDED.severity,
+    title        = EXCLUDED.title,
+    detail       = EXCLUDED.detail,
+    value        = EXCLUDED.value,
+    baseline     = EXCLUDED.baseline,
+    last_seen_at = EXCLUDED.last_seen_at
+RETURNING *, (xmax = 0) AS inserted`,
+		a.Kind, a.Severity, a.BranchID, a.StaffID, a.Title, a.Detail, a.Value, a.Baseline, now, now,
+	).Scan(&out).Error
+	if err != nil {
+		return false, err
+	}
+	*a = out.Alert
+	return out.Inserted, nil
+}
+
+// ResolveExcept closes open alerts of kind in a branch whose staff_id is not
+// in stillFiring. Returns how many were resolved.
+func (r *AlertsRepo) ResolveExcept(kind, branchID string, stillFiring []string) (int64, error) {
+	q := r.db.Model(&models.Alert{}).
+		Where("kind = ? AND branch_id = ? AND resolved_at IS NULL", kind, branchID)
+	if len(stillFiring) > 0 {
+		q = q.Where("staff_id NOT IN ?", stillFiring)
+	}
+	res := q.Update("resolved_at", time.Now().UTC())
+	return res.RowsAffected, res.Error
+}
+
+// MarkNotified records that an alert's notification hooks ran.
+func (r *AlertsRepo) MarkNotified(id int64) error {
+	return r.db.Model(&models.Alert{}).Where("id = ?", id).Update("notified_at", time.Now().UTC()).Error
+}
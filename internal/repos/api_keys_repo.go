@@ -0,0 +1,66 @@
+package repos
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/araquach/phorest-datahub/internal/models"
+	"gorm.io/gorm"
+)
+
+type APIKeysRepo struct {
+	db *gorm.DB
+	lg *log.Logger
+}
+
+func NewAPIKeysRepo(db *gorm.DB, lg *log.Logger) *APIKeysRepo {
+	return &APIKeysRepo{db: db, lg: lg}
+}
+
+func (r *APIKeysRepo) Create(k *models.APIKey) error {
+	return r.db.Create(k).Error
+}
+
+// FindActiveByPrefix returns the non-revoked, non-expired key with this prefix, or nil.
+func (r *APIKeysRepo) FindActiveByPrefix(prefix string) (*models.APIKey, error) {
+	var k models.APIKey
+	err := r.db.
+		Where("key_prefix = ? AND revoked_at IS NULL", prefix).
+		Where("expires_at IS NULL OR expires_at > now()").
+		First(&k).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+func (r *APIKeysRepo) List() ([]models.APIKey, error) {
+	var rows []models.APIKey
+	err := r.db.Order("created_at").Find(&rows).Error
+	return rows, err
+}
+
+// Revoke marks a key as revoked; returns false if no active key had that prefix.
+func (r *APIKeysRepo) Revoke(prefix string) (bool, error) {
+	res := r.db.Model(&models.APIKey{}).
+		Where("key_prefix = ? AND revoked_at IS NULL", prefix).
+		Updates(map[string]any{"revoked_at": time.Now().UTC(), "updated_at": time.Now().UTC()})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *APIKeysRepo) TouchLastUsed(id int64) error {
+	return r.db.Model(&models.APIKey{}).
+		Where("id = ?", id).
+		UpdateColumn("last_used_at", time.Now().UTC()).Error
+}
+
+func (r *APIKeysRepo) InsertAudit(a *models.APIAuditLog) error {
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = time.Now().UTC()
+	}
+	return r.db.Create(a).Error
+}
@@ -0,0 +1,45 @@
+package repos
+
+import (
+	"log"
+
+	"github.com/araquach/phorest-datahub/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AppointmentsRepo struct {
+	db *gorm.DB
+	lg *log.Logger
+}
+
+func NewAppointmentsRepo(db *gorm.DB, lg *log.Logger) *AppointmentsRepo {
+	return &AppointmentsRepo{db: db, lg: lg}
+}
+
+// UpsertBatch inserts or updates appointments by appointment_id and returns
+// how many rows were new or changed. A row Phorest hasn't touched since the
+// last sync (same version and updatedAt) is left alone.
+func (r *AppointmentsRepo) UpsertBatch(rows []models.Appointment, batchSize int) (int64, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	res := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "appointment_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"branch_id", "version", "appointment_date", "start_at", "end_at",
+			"client_id", "staff_id", "service_id", "service_name", "room_id", "booking_id",
+			"price", "deposit_amount", "state", "activation_state", "source",
+			"booked_online", "staff_request", "confirmed", "cancelled", "no_show",
+			"created_at_phorest", "updated_at_phorest", "updated_at",
+		}),
+		Where: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: `
+appointments.version IS DISTINCT FROM EXCLUDED.version
+OR appointments.updated_at_phorest IS DISTINCT FROM EXCLUDED.updated_at_phorest`}}},
+	}).CreateInBatches(&rows, batchSize)
+	if res.Error != nil {
+		return 0, res.Error
+	}
+	r.lg.Printf("Upserted appointments: %d (%d changed)", len(rows), res.RowsAffected)
+	return res.RowsAffected, nil
+}
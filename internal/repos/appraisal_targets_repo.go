@@ -0,0 +1,55 @@
+package repos
+
+import (
+	"log"
+	"time"
+
+	"github.com/araquach/phorest-datahub/internal/models"
+	"gorm.io/gorm"
+)
+
+type AppraisalTargetsRepo struct {
+	db *gorm.DB
+	lg *log.Logger
+}
+
+func NewAppraisalTargetsRepo(db *gorm.DB, lg *log.Logger) *AppraisalTargetsRepo {
+	return &AppraisalTargetsRepo{db: db, lg: lg}
+}
+
+// ListForPeriod returns the targets for a stylist whose period overlaps [from, to].
+// When several targets exist for the same metric, the one with the latest period_start wins.
+func (r *AppraisalTargetsRepo) ListForPeriod(staffID, branchID string, from, to time.Time) ([]models.AppraisalTarget, error) {
+	var rows []models.AppraisalTarget
+	err := r.db.Raw(`
+SELECT DISTINCT ON (metric) *
+FROM appraisal_targets
+WHERE staff_id = ?
+  AND branch_id = ?
+  AND period_start <= ?
+  AND period_end >= ?
+ORDER BY metric, period_start DESC`, staffID, branchID, to, from).Scan(&rows).Error
+	return rows, err
+}
+
+// AppraisalDue is a stylist whose target period ends soon.
+type AppraisalDue struct {
+	StaffID   string    `gorm:"column:staff_id"`
+	BranchID  string    `gorm:"column:branch_id"`
+	PeriodEnd time.Time `gorm:"column:period_end"`
+	Targets   int64     `gorm:"column:targets"`
+}
+
+// DueBetween returns, per stylist and branch, the earliest target period_end
+// in [from, to] and how many targets end that day.
+func (r *AppraisalTargetsRepo) DueBetween(from, to time.Time) ([]AppraisalDue, error) {
+	var rows []AppraisalDue
+	err := r.db.Raw(`
+SELECT DISTINCT ON (staff_id, branch_id)
+	staff_id, branch_id, period_end,
+	COUNT(*) OVER (PARTITION BY staff_id, branch_id, period_end) AS targets
+FROM appraisal_targets
+WHERE period_end BETWEEN ? AND ?
+ORDER BY staff_id, branch_id, period_end`, from, to).Scan(&rows).Error
+	return rows, err
+}
@@ -20,9 +20,9 @@ func NewClientsRepo(db *gorm.DB, lg *log.Logger) *ClientsRepo {
 }
 
 // UpsertBatch inserts/updates clients based on client_id, only when EXCLUDED.updated_at_phorest is newer.
-func (r *ClientsRepo) UpsertBatch(rows []models.Client, batchSize int) error {
+func (r *ClientsRepo) UpsertBatch(rows []models.Client, batchSize int) (int64, error) {
 	if len(rows) == 0 {
-		return nil
+		return 0, nil
 	}
 	now := time.Now().UTC()
 
@@ -45,6 +45,8 @@ func (r *ClientsRepo) UpsertBatch(rows []models.Client, batchSize int) error {
 
 	placeholders := make([]string, 0, len(rows))
 	args := make([]any, 0, len(rows)*len(cols))
+	// Rows the ON CONFLICT ... WHERE actually inserted or updated.
+	var changed int64
 
 	flush := func() error {
 		if len(placeholders) == 0 {
@@ -109,10 +111,12 @@ WHERE clients.updated_at_phorest IS NULL
    OR EXCLUDED.updated_at_phorest > clients.updated_at_phorest;`,
 			strings.Join(placeholders, ","),
 		)
-		if err := r.db.Exec(sql, args...).Error; err != nil {
-			return err
+		res := r.db.Exec(sql, args...)
+		if res.Error != nil {
+			return res.Error
 		}
-		r.lg.Printf("Upserted clients: %d", len(placeholders))
+		changed += res.RowsAffected
+		r.lg.Printf("Upserted clients: %d (%d changed)", len(placeholders), res.RowsAffected)
 		placeholders = placeholders[:0]
 		args = args[:0]
 		return nil
@@ -137,9 +141,10 @@ WHERE clients.updated_at_phorest IS NULL
 		)
 		if len(placeholders) >= batchSize {
 			if err := flush(); err != nil {
-				return err
+				return changed, err
 			}
 		}
 	}
-	return flush()
+	err := flush()
+	return changed, err
 }
@@ -0,0 +1,32 @@
+package repos
+
+import (
+	"log"
+
+	"github.com/araquach/phorest-datahub/internal/models"
+	"gorm.io/gorm"
+)
+
+type NotificationDeliveriesRepo struct {
+	db *gorm.DB
+	lg *log.Logger
+}
+
+func NewNotificationDeliveriesRepo(db *gorm.DB, lg *log.Logger) *NotificationDeliveriesRepo {
+	return &NotificationDeliveriesRepo{db: db, lg: lg}
+}
+
+// Insert writes one delivery log row.
+func (r *NotificationDeliveriesRepo) Insert(d *models.NotificationDelivery) error {
+	return r.db.Create(d).Error
+}
+
+// Delivered reports whether an event with key already reached channel.
+func (r *NotificationDeliveriesRepo) Delivered(channel, key string) (bool, error) {
+	var n int64
+	err := r.db.Model(&models.NotificationDelivery{}).
+		Where("channel = ? AND event_key = ? AND status = ?", channel, key, models.DeliveryDelivered).
+		Limit(1).
+		Count(&n).Error
+	return n > 0, err
+}
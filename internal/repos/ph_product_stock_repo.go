@@ -33,32 +33,135 @@ func (r *PhProductStockRepo) GetByProductAndBranch(ctx context.Context, productI
 	return &s, nil
 }
 
+// GetManyForBranch returns the branch's stored stock rows among productIDs,
+// keyed by product ID.
+func (r *PhProductStockRepo) GetManyForBranch(ctx context.Context, branchID string, productIDs []string) (map[string]*models.PhProductStock, error) {
+	out := make(map[string]*models.PhProductStock, len(productIDs))
+	if len(productIDs) == 0 {
+		return out, nil
+	}
+	var rows []models.PhProductStock
+	err := r.db.WithContext(ctx).
+		Where("branch_id = ? AND product_id IN ?", branchID, productIDs).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		out[rows[i].ProductID] = &rows[i]
+	}
+	return out, nil
+}
+
 func (r *PhProductStockRepo) Upsert(ctx context.Context, s *models.PhProductStock) error {
 	// set LastSyncedAt on each upsert
 	s.LastSyncedAt = time.Now()
 
 	return r.db.WithContext(ctx).
-		Clauses(clause.OnConflict{
-			Columns: []clause.Column{{Name: "product_id"}, {Name: "branch_id"}},
-			DoUpdates: clause.AssignmentColumns([]string{
-				"price",
-				"min_quantity",
-				"max_quantity",
-				"quantity_in_stock",
-				"reorder_count",
-				"reorder_cost",
-				"archived",
-				"created_at_ph",
-				"updated_at_ph",
-				"last_synced_at",
-			}),
-		}).
+		Clauses(stockUpsert).
 		Create(s).Error
 }
 
+// UpsertMany upserts stock rows in one statement per 500 rows, stamping
+// LastSyncedAt. (product_id, branch_id) must be unique within ss.
+func (r *PhProductStockRepo) UpsertMany(ctx context.Context, ss []models.PhProductStock) error {
+	if len(ss) == 0 {
+		return nil
+	}
+	now := time.Now()
+	for i := range ss {
+		ss[i].LastSyncedAt = now
+	}
+	return r.db.WithContext(ctx).
+		Omit("Product").
+		Clauses(stockUpsert).
+		CreateInBatches(&ss, 500).Error
+}
+
+var stockUpsert = clause.OnConflict{
+	Columns: []clause.Column{{Name: "product_id"}, {Name: "branch_id"}},
+	DoUpdates: clause.AssignmentColumns([]string{
+		"price",
+		"min_quantity",
+		"max_quantity",
+		"quantity_in_stock",
+		"reorder_count",
+		"reorder_cost",
+		"archived",
+		"created_at_ph",
+		"updated_at_ph",
+		"last_synced_at",
+		"removed_at",
+	}),
+}
+
+// InsertHistoryMany appends history rows.
+func (r *PhProductStockRepo) InsertHistoryMany(ctx context.Context, hs []models.PhProductStockHistory) error {
+	if len(hs) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).CreateInBatches(&hs, 500).Error
+}
+
 func (r *PhProductStockRepo) InsertHistory(ctx context.Context, h *models.PhProductStockHistory) error {
 	if h.SnapshotTime.IsZero() {
 		h.SnapshotTime = time.Now()
 	}
 	return r.db.WithContext(ctx).Create(h).Error
 }
+
+// ListLiveProductIDs returns the branch's stock rows not marked removed.
+func (r *PhProductStockRepo) ListLiveProductIDs(ctx context.Context, branchID string) ([]string, error) {
+	var ids []string
+	err := r.db.WithContext(ctx).Model(&models.PhProductStock{}).
+		Where("branch_id = ? AND removed_at IS NULL", branchID).
+		Order("product_id").
+		Pluck("product_id", &ids).Error
+	return ids, err
+}
+
+// MarkRemoved flags a branch's stock rows as no longer in Phorest (skipping
+// ones already flagged) and records a zero-quantity history snapshot for
+// each, in one transaction. Returns the product IDs newly flagged.
+func (r *PhProductStockRepo) MarkRemoved(ctx context.Context, branchID string, productIDs []string, at time.Time) ([]string, error) {
+	const batchSize = 1000
+	var marked []string
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for start := 0; start < len(productIDs); start += batchSize {
+			end := min(start+batchSize, len(productIDs))
+
+			var ids []string
+			if err := tx.Raw(`
+UPDATE ph_product_stock
+SET removed_at = ?
+WHERE branch_id = ?
+  AND product_id IN ?
+  AND removed_at IS NULL
+RETURNING product_id`, at, branchID, productIDs[start:end]).Scan(&ids).Error; err != nil {
+				return err
+			}
+			if len(ids) == 0 {
+				continue
+			}
+
+			zero := 0.0
+			hist := make([]models.PhProductStockHistory, 0, len(ids))
+			for _, id := range ids {
+				hist = append(hist, models.PhProductStockHistory{
+					ProductID:       id,
+					BranchID:        branchID,
+					SnapshotTime:    at,
+					QuantityInStock: &zero,
+					Source:          "reconcile",
+				})
+			}
+			if err := tx.CreateInBatches(&hist, 500).Error; err != nil {
+				return err
+			}
+			marked = append(marked, ids...)
+		}
+		return nil
+	})
+	return marked, err
+}
@@ -17,26 +17,55 @@ func NewPhProductRepo(db *gorm.DB) *PhProductRepo {
 	return &PhProductRepo{db: db}
 }
 
+// GetMany returns the stored products among ids, keyed by ID.
+func (r *PhProductRepo) GetMany(ctx context.Context, ids []string) (map[string]*models.PhProduct, error) {
+	out := make(map[string]*models.PhProduct, len(ids))
+	if len(ids) == 0 {
+		return out, nil
+	}
+	var rows []models.PhProduct
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		out[rows[i].ID] = &rows[i]
+	}
+	return out, nil
+}
+
 func (r *PhProductRepo) Upsert(ctx context.Context, p *models.PhProduct) error {
 	return r.db.WithContext(ctx).
-		Clauses(clause.OnConflict{
-			Columns: []clause.Column{{Name: "id"}},
-			DoUpdates: clause.AssignmentColumns([]string{
-				"parent_id",
-				"name",
-				"brand_id",
-				"brand_name",
-				"category_id",
-				"category_name",
-				"code",
-				"type_raw",
-				"measurement_qty",
-				"measurement_unit",
-				"archived",
-				"created_at_ph",
-				"updated_at_ph",
-				"updated_at",
-			}),
-		}).
+		Clauses(productUpsert).
 		Create(p).Error
 }
+
+// UpsertMany upserts products in one statement per 500 rows. IDs must be
+// unique within ps.
+func (r *PhProductRepo) UpsertMany(ctx context.Context, ps []models.PhProduct) error {
+	if len(ps) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Clauses(productUpsert).
+		CreateInBatches(&ps, 500).Error
+}
+
+var productUpsert = clause.OnConflict{
+	Columns: []clause.Column{{Name: "id"}},
+	DoUpdates: clause.AssignmentColumns([]string{
+		"parent_id",
+		"name",
+		"brand_id",
+		"brand_name",
+		"category_id",
+		"category_name",
+		"code",
+		"type_raw",
+		"measurement_qty",
+		"measurement_unit",
+		"archived",
+		"created_at_ph",
+		"updated_at_ph",
+		"updated_at",
+	}),
+}
@@ -0,0 +1,84 @@
+package repos
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/araquach/phorest-datahub/internal/models"
+)
+
+type PhServiceRepo struct {
+	db    *gorm.DB
+	runID *int64
+}
+
+func NewPhServiceRepo(db *gorm.DB) *PhServiceRepo {
+	return &PhServiceRepo{db: db}
+}
+
+// ForRun returns a copy that attributes history rows to a sync run.
+func (r *PhServiceRepo) ForRun(runID *int64) *PhServiceRepo {
+	cp := *r
+	cp.runID = runID
+	return &cp
+}
+
+// GetManyForBranch returns the branch's stored services among ids, keyed by ID.
+func (r *PhServiceRepo) GetManyForBranch(ctx context.Context, branchID string, ids []string) (map[string]*models.PhService, error) {
+	out := make(map[string]*models.PhService, len(ids))
+	if len(ids) == 0 {
+		return out, nil
+	}
+	var rows []models.PhService
+	if err := r.db.WithContext(ctx).Where("branch_id = ? AND id IN ?", branchID, ids).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		out[rows[i].ID] = &rows[i]
+	}
+	return out, nil
+}
+
+// UpsertMany upserts services in one statement per 500 rows, stamping
+// last_synced_at. IDs must be unique within ss.
+func (r *PhServiceRepo) UpsertMany(ctx context.Context, ss []models.PhService) error {
+	if len(ss) == 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+	for i := range ss {
+		ss[i].LastSyncedAt = now
+	}
+	return r.db.WithContext(ctx).
+		Clauses(serviceUpsert).
+		CreateInBatches(&ss, 500).Error
+}
+
+// InsertHistoryMany appends catalogue history rows, stamping them with the run.
+func (r *PhServiceRepo) InsertHistoryMany(ctx context.Context, hs []models.PhServiceHistory) error {
+	if len(hs) == 0 {
+		return nil
+	}
+	for i := range hs {
+		hs[i].RunID = r.runID
+	}
+	return r.db.WithContext(ctx).CreateInBatches(&hs, 500).Error
+}
+
+var serviceUpsert = clause.OnConflict{
+	Columns: []clause.Column{{Name: "id"}, {Name: "branch_id"}},
+	DoUpdates: clause.AssignmentColumns([]string{
+		"name",
+		"category_id",
+		"category_name",
+		"duration_minutes",
+		"price",
+		"archived",
+		"created_at_ph",
+		"updated_at_ph",
+		"last_synced_at",
+	}),
+}
@@ -0,0 +1,138 @@
+package repos
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/araquach/phorest-datahub/internal/models"
+	"gorm.io/gorm"
+)
+
+type ProductChangesRepo struct {
+	db    *gorm.DB
+	runID *int64
+}
+
+func NewProductChangesRepo(db *gorm.DB) *ProductChangesRepo {
+	return &ProductChangesRepo{db: db}
+}
+
+// ForRun returns a copy that attributes product_changes rows to a sync run.
+func (r *ProductChangesRepo) ForRun(runID *int64) *ProductChangesRepo {
+	cp := *r
+	cp.runID = runID
+	return &cp
+}
+
+// Insert writes change rows, stamping them with the run.
+func (r *ProductChangesRepo) Insert(ctx context.Context, changes []models.ProductChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	for i := range changes {
+		changes[i].RunID = r.runID
+	}
+	return r.db.WithContext(ctx).CreateInBatches(&changes, 500).Error
+}
+
+// productField is one synced column rendered as text for the change log
+// (nil = NULL).
+type productField struct {
+	name  string
+	value *string
+}
+
+func productFields(p *models.PhProduct) []productField {
+	return []productField{
+		{"parent_id", p.ParentID},
+		{"name", &p.Name},
+		{"brand_id", p.BrandID},
+		{"brand_name", p.BrandName},
+		{"category_id", p.CategoryID},
+		{"category_name", p.CategoryName},
+		{"code", p.Code},
+		{"type_raw", p.TypeRaw},
+		{"measurement_qty", numText(p.MeasurementQty)},
+		{"measurement_unit", p.MeasurementUnit},
+		{"archived", boolText(p.Archived)},
+	}
+}
+
+func stockFields(s *models.PhProductStock) []productField {
+	return []productField{
+		{"price", numText(s.Price)},
+		{"min_quantity", numText(s.MinQuantity)},
+		{"max_quantity", numText(s.MaxQuantity)},
+		{"quantity_in_stock", numText(s.QuantityInStock)},
+		{"reorder_count", numText(s.ReorderCount)},
+		{"reorder_cost", numText(s.ReorderCost)},
+		{"archived", boolText(s.Archived)},
+		{"removed_at", timeText(s.RemovedAt)},
+	}
+}
+
+// ProductDiff lists the ph_products fields that differ between old and cur.
+// A new product (old == nil) has no diff. branchID is the syncing branch.
+func ProductDiff(old, cur *models.PhProduct, branchID string, at time.Time) []models.ProductChange {
+	if old == nil {
+		return nil
+	}
+	return diffFields(models.ProductChangeProduct, cur.ID, branchID, productFields(old), productFields(cur), at)
+}
+
+// StockDiff lists the ph_product_stock fields that differ between old and
+// cur. A new stock row (old == nil) has no diff.
+func StockDiff(old, cur *models.PhProductStock, at time.Time) []models.ProductChange {
+	if old == nil {
+		return nil
+	}
+	return diffFields(models.ProductChangeStock, cur.ProductID, cur.BranchID, stockFields(old), stockFields(cur), at)
+}
+
+func diffFields(entity, productID, branchID string, old, cur []productField, at time.Time) []models.ProductChange {
+	var out []models.ProductChange
+	for i, f := range cur {
+		if eqText(old[i].value, f.value) {
+			continue
+		}
+		out = append(out, models.ProductChange{
+			Entity:    entity,
+			ProductID: productID,
+			BranchID:  branchID,
+			Field:     f.name,
+			OldValue:  old[i].value,
+			NewValue:  f.value,
+			ChangedAt: at,
+		})
+	}
+	return out
+}
+
+func numText(v *float64) *string {
+	if v == nil {
+		return nil
+	}
+	s := strconv.FormatFloat(*v, 'f', -1, 64)
+	return &s
+}
+
+func timeText(v *time.Time) *string {
+	if v == nil {
+		return nil
+	}
+	s := v.UTC().Format(time.RFC3339)
+	return &s
+}
+
+func boolText(v bool) *string {
+	s := strconv.FormatBool(v)
+	return &s
+}
+
+func eqText(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,48 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/araquach/phorest-datahub/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ProductSyncCheckpointsRepo struct {
+	db *gorm.DB
+}
+
+func NewProductSyncCheckpointsRepo(db *gorm.DB) *ProductSyncCheckpointsRepo {
+	return &ProductSyncCheckpointsRepo{db: db}
+}
+
+// Get returns the branch's checkpoint, or nil if none is open.
+func (r *ProductSyncCheckpointsRepo) Get(ctx context.Context, branchID string) (*models.ProductSyncCheckpoint, error) {
+	var cp models.ProductSyncCheckpoint
+	err := r.db.WithContext(ctx).Where("branch_id = ?", branchID).First(&cp).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &cp, nil
+}
+
+// Save writes the checkpoint, replacing the branch's previous one.
+func (r *ProductSyncCheckpointsRepo) Save(ctx context.Context, cp *models.ProductSyncCheckpoint) error {
+	cp.UpdatedAt = time.Now().UTC()
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "branch_id"}},
+			UpdateAll: true,
+		}).
+		Create(cp).Error
+}
+
+// Delete clears the branch's checkpoint once its sync finished.
+func (r *ProductSyncCheckpointsRepo) Delete(ctx context.Context, branchID string) error {
+	return r.db.WithContext(ctx).Where("branch_id = ?", branchID).Delete(&models.ProductSyncCheckpoint{}).Error
+}
@@ -0,0 +1,86 @@
+package repos
+
+import (
+	"log"
+
+	"github.com/araquach/phorest-datahub/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReviewAnalysisRepo struct {
+	db *gorm.DB
+	lg *log.Logger
+}
+
+func NewReviewAnalysisRepo(db *gorm.DB, lg *log.Logger) *ReviewAnalysisRepo {
+	return &ReviewAnalysisRepo{db: db, lg: lg}
+}
+
+// ReviewAnalysisSet is everything stored for one analysed review.
+type ReviewAnalysisSet struct {
+	Analysis models.ReviewAnalysis
+	Topics   []models.ReviewTopic
+	Snippets []models.ReviewSnippet
+}
+
+// ListPending returns live reviews with no analysis, or whose content or the
+// analyzer version changed since they were analysed.
+func (r *ReviewAnalysisRepo) ListPending(version string, limit int) ([]models.Review, error) {
+	var rows []models.Review
+	err := r.db.
+		Table("reviews AS r").
+		Select("r.*").
+		Joins("LEFT JOIN review_analysis a ON a.review_id = r.review_id").
+		Where("r.deleted_at IS NULL").
+		Where("a.review_id IS NULL OR COALESCE(a.content_hash, '') <> COALESCE(r.content_hash, '') OR a.analyzer_version <> ?", version).
+		Order("r.review_id").
+		Limit(limit).
+		Find(&rows).Error
+	return rows, err
+}
+
+// SaveMany replaces the analysis, topics and snippets of each review in one
+// transaction.
+func (r *ReviewAnalysisRepo) SaveMany(sets []ReviewAnalysisSet) error {
+	if len(sets) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(sets))
+	analyses := make([]models.ReviewAnalysis, len(sets))
+	var topics []models.ReviewTopic
+	var snippets []models.ReviewSnippet
+	for i, s := range sets {
+		ids[i] = s.Analysis.ReviewID
+		analyses[i] = s.Analysis
+		topics = append(topics, s.Topics...)
+		snippets = append(snippets, s.Snippets...)
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("review_id IN ?", ids).Delete(&models.ReviewTopic{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("review_id IN ?", ids).Delete(&models.ReviewSnippet{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "review_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"sentiment", "label", "content_hash", "analyzer_version", "analysed_at"}),
+		}).CreateInBatches(&analyses, 500).Error; err != nil {
+			return err
+		}
+		if len(topics) > 0 {
+			if err := tx.CreateInBatches(&topics, 500).Error; err != nil {
+				return err
+			}
+		}
+		if len(snippets) > 0 {
+			if err := tx.CreateInBatches(&snippets, 500).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
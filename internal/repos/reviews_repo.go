@@ -1,7 +1,11 @@
 package repos
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"log"
+	"strconv"
+	"time"
 
 	"github.com/araquach/phorest-datahub/internal/models"
 	"gorm.io/gorm"
@@ -9,23 +13,86 @@ import (
 )
 
 type ReviewsRepo struct {
-	db *gorm.DB
-	lg *log.Logger
+	db    *gorm.DB
+	lg    *log.Logger
+	runID *int64
 }
 
 func NewReviewsRepo(db *gorm.DB, lg *log.Logger) *ReviewsRepo {
 	return &ReviewsRepo{db: db, lg: lg}
 }
 
-// UpsertMany: reviews are immutable, so we can safely DO NOTHING on conflict.
-// We batch inserts to avoid Postgres' 65535-parameter limit.
-func (r *ReviewsRepo) UpsertMany(rows []models.Review) error {
+// ForRun returns a copy that attributes review_changes rows to a sync run.
+func (r *ReviewsRepo) ForRun(runID *int64) *ReviewsRepo {
+	cp := *r
+	cp.runID = runID
+	return &cp
+}
+
+// ReviewUpsertStats says what UpsertMany actually did.
+type ReviewUpsertStats struct {
+	Inserted int64
+	Updated  int64 // edited in Phorest, or reappeared after being marked deleted
+
+	// NewIDs are the reviews that weren't stored before this call.
+	NewIDs []string
+}
+
+// Changed is Inserted + Updated.
+func (s ReviewUpsertStats) Changed() int64 { return s.Inserted + s.Updated }
+
+// reviewField is one Phorest-editable review field, rendered as text for
+// hashing and for the change log.
+type reviewField struct {
+	name  string
+	value string
+}
+
+func reviewFields(rv *models.Review) []reviewField {
+	day := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Format("2006-01-02")
+	}
+	return []reviewField{
+		{"client_id", rv.ClientID},
+		{"client_first_name", rv.ClientFirstName},
+		{"client_last_name", rv.ClientLastName},
+		{"review_date", day(rv.ReviewDate)},
+		{"visit_date", day(rv.VisitDate)},
+		{"staff_id", rv.StaffID},
+		{"staff_first_name", rv.StaffFirstName},
+		{"staff_last_name", rv.StaffLastName},
+		{"text", rv.Text},
+		{"rating", strconv.Itoa(rv.Rating)},
+		{"facebook_review", strconv.FormatBool(rv.FacebookReview)},
+		{"twitter_review", strconv.FormatBool(rv.TwitterReview)},
+	}
+}
+
+// ReviewContentHash hashes the fields Phorest can edit, so unchanged reviews
+// are skipped without a field-by-field compare.
+func ReviewContentHash(rv *models.Review) string {
+	h := sha256.New()
+	for _, f := range reviewFields(rv) {
+		h.Write([]byte(f.value))
+		h.Write([]byte{0x1f})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// UpsertMany inserts new reviews and updates ones whose content hash changed,
+// logging each changed field to review_changes. A review previously marked
+// deleted is restored if Phorest returns it again.
+// We batch to avoid Postgres' 65535-parameter limit; each batch is one transaction.
+func (r *ReviewsRepo) UpsertMany(rows []models.Review) (ReviewUpsertStats, error) {
+	var stats ReviewUpsertStats
 	if len(rows) == 0 {
-		return nil
+		return stats, nil
 	}
 
 	const batchSize = 500 // safely under parameter limit even with many columns
-	total := 0
 
 	for start := 0; start < len(rows); start += batchSize {
 		end := start + batchSize
@@ -34,19 +101,177 @@ func (r *ReviewsRepo) UpsertMany(rows []models.Review) error {
 		}
 		chunk := rows[start:end]
 
-		res := r.db.Clauses(clause.OnConflict{
+		err := r.db.Transaction(func(tx *gorm.DB) error {
+			st, err := r.upsertChunk(tx, chunk)
+			stats.Inserted += st.Inserted
+			stats.Updated += st.Updated
+			stats.NewIDs = append(stats.NewIDs, st.NewIDs...)
+			return err
+		})
+		if err != nil {
+			return stats, err
+		}
+	}
+
+	r.lg.Printf("Upserted reviews (batched): %d (%d new, %d updated)", len(rows), stats.Inserted, stats.Updated)
+	return stats, nil
+}
+
+func (r *ReviewsRepo) upsertChunk(tx *gorm.DB, chunk []models.Review) (ReviewUpsertStats, error) {
+	var stats ReviewUpsertStats
+
+	ids := make([]string, len(chunk))
+	for i := range chunk {
+		ids[i] = chunk[i].ReviewID
+		chunk[i].ContentHash = ReviewContentHash(&chunk[i])
+	}
+
+	var existing []models.Review
+	if err := tx.Where("review_id IN ?", ids).Find(&existing).Error; err != nil {
+		return stats, err
+	}
+	byID := make(map[string]*models.Review, len(existing))
+	for i := range existing {
+		byID[existing[i].ReviewID] = &existing[i]
+	}
+
+	now := time.Now().UTC()
+	var fresh []models.Review
+	var changes []models.ReviewChange
+
+	for i := range chunk {
+		rv := &chunk[i]
+		old, ok := byID[rv.ReviewID]
+		if !ok {
+			fresh = append(fresh, *rv)
+			continue
+		}
+		if old.ContentHash == rv.ContentHash && old.DeletedAt == nil {
+			continue
+		}
+
+		var diff []models.ReviewChange
+		oldFields := reviewFields(old)
+		for j, f := range reviewFields(rv) {
+			if oldFields[j].value != f.value {
+				diff = append(diff, r.change(rv, f.name, strPtr(oldFields[j].value), strPtr(f.value), now))
+			}
+		}
+		if old.DeletedAt != nil {
+			diff = append(diff, r.change(rv, "deleted", strPtr(old.DeletedAt.UTC().Format(time.RFC3339)), nil, now))
+		}
+
+		if len(diff) == 0 {
+			// Rows stored before content hashes existed: just backfill the hash.
+			if err := tx.Model(&models.Review{}).Where("review_id = ?", rv.ReviewID).
+				Update("content_hash", rv.ContentHash).Error; err != nil {
+				return stats, err
+			}
+			continue
+		}
+
+		if err := tx.Model(&models.Review{}).Where("review_id = ?", rv.ReviewID).Updates(map[string]any{
+			"client_id":         rv.ClientID,
+			"client_first_name": rv.ClientFirstName,
+			"client_last_name":  rv.ClientLastName,
+			"review_date":       rv.ReviewDate,
+			"visit_date":        rv.VisitDate,
+			"staff_id":          rv.StaffID,
+			"staff_first_name":  rv.StaffFirstName,
+			"staff_last_name":   rv.StaffLastName,
+			"text":              rv.Text,
+			"rating":            rv.Rating,
+			"facebook_review":   rv.FacebookReview,
+			"twitter_review":    rv.TwitterReview,
+			"content_hash":      rv.ContentHash,
+			"deleted_at":        nil,
+			"updated_at":        now,
+		}).Error; err != nil {
+			return stats, err
+		}
+		stats.Updated++
+		changes = append(changes, diff...)
+	}
+
+	if len(fresh) > 0 {
+		res := tx.Clauses(clause.OnConflict{
 			Columns:   []clause.Column{{Name: "review_id"}},
 			DoNothing: true,
-		}).Create(&chunk)
-
+		}).Create(&fresh)
 		if res.Error != nil {
-			return res.Error
+			return stats, res.Error
+		}
+		stats.Inserted = res.RowsAffected
+		for i := range fresh {
+			stats.NewIDs = append(stats.NewIDs, fresh[i].ReviewID)
+		}
+	}
+
+	if len(changes) > 0 {
+		if err := tx.CreateInBatches(&changes, 500).Error; err != nil {
+			return stats, err
 		}
-		total += len(chunk)
 	}
+	return stats, nil
+}
 
-	r.lg.Printf("Upserted/inserted reviews (batched): %d", total)
-	return nil
+func (r *ReviewsRepo) change(rv *models.Review, field string, oldValue, newValue *string, at time.Time) models.ReviewChange {
+	return models.ReviewChange{
+		ReviewID:  rv.ReviewID,
+		BranchID:  rv.BranchID,
+		Field:     field,
+		OldValue:  oldValue,
+		NewValue:  newValue,
+		RunID:     r.runID,
+		ChangedAt: at,
+	}
+}
+
+func strPtr(s string) *string { return &s }
+
+// MarkDeleted flags a branch's reviews as removed from Phorest (skipping ones
+// already flagged) and logs a "deleted" change for each. Returns how many
+// were newly flagged.
+func (r *ReviewsRepo) MarkDeleted(branchID string, reviewIDs []string) (int64, error) {
+	const batchSize = 1000
+	var marked int64
+
+	for start := 0; start < len(reviewIDs); start += batchSize {
+		end := start + batchSize
+		if end > len(reviewIDs) {
+			end = len(reviewIDs)
+		}
+
+		err := r.db.Transaction(func(tx *gorm.DB) error {
+			now := time.Now().UTC()
+			var flagged []string
+			if err := tx.Raw(`
+UPDATE reviews
+SET deleted_at = ?, updated_at = ?
+WHERE branch_id = ? AND review_id IN ? AND deleted_at IS NULL
+RETURNING review_id`, now, now, branchID, reviewIDs[start:end]).Scan(&flagged).Error; err != nil {
+				return err
+			}
+			if len(flagged) == 0 {
+				return nil
+			}
+
+			at := now.Format(time.RFC3339)
+			changes := make([]models.ReviewChange, 0, len(flagged))
+			for _, id := range flagged {
+				changes = append(changes, r.change(&models.Review{ReviewID: id, BranchID: branchID}, "deleted", nil, &at, now))
+			}
+			if err := tx.CreateInBatches(&changes, 500).Error; err != nil {
+				return err
+			}
+			marked += int64(len(flagged))
+			return nil
+		})
+		if err != nil {
+			return marked, err
+		}
+	}
+	return marked, nil
 }
 
 // Watermark helpers (for incremental fetches by branch)
@@ -60,17 +285,28 @@ func (r *ReviewsRepo) MaxReviewDate(branchID string) (*string, error) {
 	return ts, err
 }
 
-func (r *ReviewsRepo) CountExistingByIDs(branchID string, ids []string) (int64, error) {
-	if len(ids) == 0 {
-		return 0, nil
-	}
-
-	var count int64
-	if err := r.db.
+// ListReviewIDs returns every live (not deleted) review_id for a branch (used by reconcile).
+func (r *ReviewsRepo) ListReviewIDs(branchID string) ([]string, error) {
+	var ids []string
+	err := r.db.
 		Model(&models.Review{}).
-		Where("branch_id = ? AND review_id IN (?)", branchID, ids).
-		Count(&count).Error; err != nil {
-		return 0, err
+		Where("branch_id = ? AND deleted_at IS NULL", branchID).
+		Pluck("review_id", &ids).Error
+	return ids, err
+}
+
+// ListForStaff returns a stylist's reviews with text in [from, to], newest first.
+func (r *ReviewsRepo) ListForStaff(staffID, branchID string, from, to time.Time, limit int) ([]models.Review, error) {
+	q := r.db.
+		Where("staff_id = ? AND branch_id = ? AND deleted_at IS NULL", staffID, branchID).
+		Where("review_date BETWEEN ? AND ?", from, to).
+		Where("COALESCE(text, '') <> ''").
+		Order("review_date DESC, rating DESC")
+	if limit > 0 {
+		q = q.Limit(limit)
 	}
-	return count, nil
+
+	var rows []models.Review
+	err := q.Find(&rows).Error
+	return rows, err
 }
@@ -0,0 +1,70 @@
+package repos
+
+import (
+	"log"
+	"time"
+
+	"github.com/araquach/phorest-datahub/internal/models"
+	"gorm.io/gorm"
+)
+
+type StaffRosterRepo struct {
+	db *gorm.DB
+	lg *log.Logger
+}
+
+func NewStaffRosterRepo(db *gorm.DB, lg *log.Logger) *StaffRosterRepo {
+	return &StaffRosterRepo{db: db, lg: lg}
+}
+
+// ReplaceWindow swaps a branch's timetables, breaks and time off for the
+// days from..to (inclusive) with roster in one transaction. Time off is
+// replaced if it overlaps the window at all, as the API returns it whole.
+func (r *StaffRosterRepo) ReplaceWindow(branchID string, from, to time.Time, roster models.Roster) error {
+	end := to.AddDate(0, 0, 1)
+	now := time.Now().UTC()
+	for i := range roster.Timetables {
+		roster.Timetables[i].SyncedAt = now
+	}
+	for i := range roster.Breaks {
+		roster.Breaks[i].SyncedAt = now
+	}
+	for i := range roster.TimeOff {
+		roster.TimeOff[i].SyncedAt = now
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("branch_id = ? AND work_date BETWEEN ? AND ?", branchID, from, to).
+			Delete(&models.StaffWorkTimetable{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("branch_id = ? AND break_date BETWEEN ? AND ?", branchID, from, to).
+			Delete(&models.StaffBreak{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("branch_id = ? AND start_at < ? AND end_at > ?", branchID, end, from).
+			Delete(&models.StaffTimeOff{}).Error; err != nil {
+			return err
+		}
+
+		if len(roster.Timetables) > 0 {
+			if err := tx.CreateInBatches(&roster.Timetables, 500).Error; err != nil {
+				return err
+			}
+		}
+		if len(roster.Breaks) > 0 {
+			if err := tx.CreateInBatches(&roster.Breaks, 500).Error; err != nil {
+				return err
+			}
+		}
+		if len(roster.TimeOff) > 0 {
+			if err := tx.CreateInBatches(&roster.TimeOff, 500).Error; err != nil {
+				return err
+			}
+		}
+		r.lg.Printf("Replaced roster for %s %s..%s: %d timetable(s), %d break(s), %d time off",
+			branchID, from.Format("2006-01-02"), to.Format("2006-01-02"),
+			len(roster.Timetables), len(roster.Breaks), len(roster.TimeOff))
+		return nil
+	})
+}
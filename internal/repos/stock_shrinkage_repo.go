@@ -0,0 +1,45 @@
+package repos
+
+import (
+	"log"
+	"time"
+
+	"github.com/araquach/phorest-datahub/internal/models"
+	"gorm.io/gorm"
+)
+
+type StockShrinkageRepo struct {
+	db *gorm.DB
+	lg *log.Logger
+}
+
+func NewStockShrinkageRepo(db *gorm.DB, lg *log.Logger) *StockShrinkageRepo {
+	return &StockShrinkageRepo{db: db, lg: lg}
+}
+
+// ReplacePeriod swaps a branch's stored rows for [from, to] with rows in one
+// transaction.
+func (r *StockShrinkageRepo) ReplacePeriod(branchID string, from, to time.Time, rows []models.StockShrinkage) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("branch_id = ? AND period_start = ? AND period_end = ?", branchID, from, to).
+			Delete(&models.StockShrinkage{}).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(&rows, 500).Error
+	})
+}
+
+// List returns the stored rows for a branch and period, biggest shrinkage
+// cost first. flaggedOnly keeps rows flagged as shrinkage.
+func (r *StockShrinkageRepo) List(branchID string, from, to time.Time, flaggedOnly bool) ([]models.StockShrinkage, error) {
+	q := r.db.Where("branch_id = ? AND period_start = ? AND period_end = ?", branchID, from, to)
+	if flaggedOnly {
+		q = q.Where("flagged")
+	}
+	var rows []models.StockShrinkage
+	err := q.Order("shrinkage_cost DESC, shrinkage DESC, brand_name, product_name").Find(&rows).Error
+	return rows, err
+}
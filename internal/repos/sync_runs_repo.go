@@ -0,0 +1,116 @@
+package repos
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/araquach/phorest-datahub/internal/models"
+	"gorm.io/gorm"
+)
+
+type SyncRunsRepo struct {
+	db *gorm.DB
+	lg *log.Logger
+}
+
+func NewSyncRunsRepo(db *gorm.DB, lg *log.Logger) *SyncRunsRepo {
+	return &SyncRunsRepo{db: db, lg: lg}
+}
+
+// Start inserts a new run in the running state and fills in its ID.
+func (r *SyncRunsRepo) Start(run *models.SyncRun) error {
+	run.Status = models.SyncRunRunning
+	if run.StartedAt.IsZero() {
+		run.StartedAt = time.Now().UTC()
+	}
+	if run.BranchID == "" {
+		run.BranchID = "ALL"
+	}
+	return r.db.Create(run).Error
+}
+
+// StartExclusive inserts run like Start unless a running row of one of
+// kinds overlaps its branch ("ALL" overlaps every branch). The check and
+// insert share a transaction holding an advisory lock, so concurrent
+// processes can't both start. Returns the ID of the conflicting run (and
+// inserts nothing) on a clash.
+func (r *SyncRunsRepo) StartExclusive(run *models.SyncRun, kinds []string) (conflictID int64, err error) {
+	run.Status = models.SyncRunRunning
+	if run.StartedAt.IsZero() {
+		run.StartedAt = time.Now().UTC()
+	}
+	if run.BranchID == "" {
+		run.BranchID = "ALL"
+	}
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('sync_runs'))").Error; err != nil {
+			return err
+		}
+		var ids []int64
+		if err := tx.Model(&models.SyncRun{}).
+			Where("status = ? AND kind IN ?", models.SyncRunRunning, kinds).
+			Where("branch_id = ? OR branch_id = 'ALL' OR ? = 'ALL'", run.BranchID, run.BranchID).
+			Order("id").Limit(1).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) > 0 {
+			conflictID = ids[0]
+			return nil
+		}
+		return tx.Create(run).Error
+	})
+	return conflictID, err
+}
+
+// Finish records the terminal status, error text and captured log for a run.
+func (r *SyncRunsRepo) Finish(id int64, status, errText, logText string) error {
+	return r.db.Model(&models.SyncRun{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"status":      status,
+			"error":       errText,
+			"log":         logText,
+			"finished_at": time.Now().UTC(),
+		}).Error
+}
+
+func (r *SyncRunsRepo) Get(id int64) (*models.SyncRun, error) {
+	var run models.SyncRun
+	err := r.db.Where("id = ?", id).First(&run).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// ListRecent returns the newest runs, optionally limited to a set of branches.
+func (r *SyncRunsRepo) ListRecent(branchIDs []string, limit int) ([]models.SyncRun, error) {
+	q := r.db.Omit("log").Order("started_at DESC")
+	if len(branchIDs) > 0 {
+		q = q.Where("branch_id IN ?", branchIDs)
+	}
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	var rows []models.SyncRun
+	err := q.Find(&rows).Error
+	return rows, err
+}
+
+// MarkAbandoned fails any run with the given trigger left "running" by a
+// process that died mid-sync.
+func (r *SyncRunsRepo) MarkAbandoned(triggeredBy string, olderThan time.Duration) (int64, error) {
+	res := r.db.Model(&models.SyncRun{}).
+		Where("status = ? AND triggered_by = ? AND started_at < ?",
+			models.SyncRunRunning, triggeredBy, time.Now().UTC().Add(-olderThan)).
+		Updates(map[string]any{
+			"status":      models.SyncRunFailed,
+			"error":       "abandoned (process exited before the run finished)",
+			"finished_at": time.Now().UTC(),
+		})
+	return res.RowsAffected, res.Error
+}
@@ -19,9 +19,9 @@ func NewItemsRepo(db *gorm.DB, lg *log.Logger) *ItemsRepo {
 	return &ItemsRepo{db: db, lg: lg}
 }
 
-func (r *ItemsRepo) UpsertBatch(rows []models.TransactionItem, batchSize int) error {
+func (r *ItemsRepo) UpsertBatch(rows []models.TransactionItem, batchSize int) (int64, error) {
 	if len(rows) == 0 {
-		return nil
+		return 0, nil
 	}
 	now := time.Now().UTC()
 
@@ -77,6 +77,8 @@ func (r *ItemsRepo) UpsertBatch(rows []models.TransactionItem, batchSize int) er
 
 	placeholders := make([]string, 0, len(rows))
 	args := make([]any, 0, len(rows)*len(cols))
+	// Rows the ON CONFLICT ... WHERE actually inserted or updated.
+	var changed int64
 
 	flush := func() error {
 		if len(placeholders) == 0 {
@@ -184,10 +186,12 @@ WHERE transaction_items.updated_at_phorest IS NULL
 			strings.Join(cols, ", "),
 			strings.Join(placeholders, ","),
 		)
-		if err := r.db.Exec(sql, args...).Error; err != nil {
-			return err
+		res := r.db.Exec(sql, args...)
+		if res.Error != nil {
+			return res.Error
 		}
-		r.lg.Printf("Upserted items: %d", len(placeholders))
+		changed += res.RowsAffected
+		r.lg.Printf("Upserted items: %d (%d changed)", len(placeholders), res.RowsAffected)
 		placeholders = placeholders[:0]
 		args = args[:0]
 		return nil
@@ -245,9 +249,10 @@ WHERE transaction_items.updated_at_phorest IS NULL
 		)
 		if len(placeholders) >= batchSize {
 			if err := flush(); err != nil {
-				return err
+				return changed, err
 			}
 		}
 	}
-	return flush()
+	err := flush()
+	return changed, err
 }
@@ -19,9 +19,9 @@ func NewTransactionsRepo(db *gorm.DB, lg *log.Logger) *TransactionsRepo {
 	return &TransactionsRepo{db: db, lg: lg}
 }
 
-func (r *TransactionsRepo) UpsertBatch(rows []models.Transaction, batchSize int) error {
+func (r *TransactionsRepo) UpsertBatch(rows []models.Transaction, batchSize int) (int64, error) {
 	if len(rows) == 0 {
-		return nil
+		return 0, nil
 	}
 	now := time.Now().UTC()
 
@@ -35,6 +35,8 @@ func (r *TransactionsRepo) UpsertBatch(rows []models.Transaction, batchSize int)
 
 	placeholders := make([]string, 0, len(rows))
 	args := make([]any, 0, len(rows)*len(cols))
+	// Rows the ON CONFLICT ... WHERE actually inserted or updated.
+	var changed int64
 
 	flush := func() error {
 		if len(placeholders) == 0 {
@@ -59,10 +61,12 @@ WHERE transactions.updated_at_phorest IS NULL
 			strings.Join(cols, ", "),
 			strings.Join(placeholders, ","),
 		)
-		if err := r.db.Exec(sql, args...).Error; err != nil {
-			return err
+		res := r.db.Exec(sql, args...)
+		if res.Error != nil {
+			return res.Error
 		}
-		r.lg.Printf("Upserted transactions: %d", len(placeholders))
+		changed += res.RowsAffected
+		r.lg.Printf("Upserted transactions: %d (%d changed)", len(placeholders), res.RowsAffected)
 		placeholders = placeholders[:0]
 		args = args[:0]
 		return nil
@@ -79,9 +83,10 @@ WHERE transactions.updated_at_phorest IS NULL
 		)
 		if len(placeholders) >= batchSize {
 			if err := flush(); err != nil {
-				return err
+				return changed, err
 			}
 		}
 	}
-	return flush()
+	err := flush()
+	return changed, err
 }
@@ -1,20 +1,59 @@
 package repos
 
 import (
+	"errors"
 	"log"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Known watermark entities. Global ones only ever use branch_id "ALL".
+var WatermarkEntities = map[string]bool{
+	"transactions_csv": false,
+	"clients_csv":      true,
+	"reviews_api":      false,
+	"products_api":     false,
+	"staff_api":        false,
+	"branches_api":     true,
+	"appointments_api": false,
+	"roster_api":       false,
+	"services_api":     false,
+
+	// When the periodic look-back sweep last ran (see config.OverlapPolicy).
+	"transactions_csv:sweep": false,
+	"clients_csv:sweep":      true,
+	"appointments_api:sweep": false,
+}
+
+// Who moved a watermark (sync_watermark_history.changed_by).
+const (
+	WatermarkChangedBySync      = "sync"
+	WatermarkChangedByBootstrap = "bootstrap"
+	WatermarkChangedByManual    = "manual"
 )
 
 // WatermarksRepo provides access to the sync_watermarks table.
 type WatermarksRepo struct {
-	db *gorm.DB
-	lg *log.Logger
+	db        *gorm.DB
+	lg        *log.Logger
+	runID     *int64
+	changedBy string
 }
 
 func NewWatermarksRepo(db *gorm.DB, lg *log.Logger) *WatermarksRepo {
-	return &WatermarksRepo{db: db, lg: lg}
+	return &WatermarksRepo{db: db, lg: lg, changedBy: WatermarkChangedBySync}
+}
+
+// ForRun returns a copy that attributes history rows to a sync run and source.
+func (r *WatermarksRepo) ForRun(runID *int64, changedBy string) *WatermarksRepo {
+	cp := *r
+	cp.runID = runID
+	if changedBy != "" {
+		cp.changedBy = changedBy
+	}
+	return &cp
 }
 
 // SyncWatermark matches the *current* sync_watermarks schema.
@@ -29,6 +68,21 @@ type SyncWatermark struct {
 
 func (SyncWatermark) TableName() string { return "sync_watermarks" }
 
+// SyncWatermarkHistory is one change to a watermark.
+type SyncWatermarkHistory struct {
+	ID        int64      `gorm:"primaryKey;column:id"`
+	Entity    string     `gorm:"column:entity"`
+	BranchID  string     `gorm:"column:branch_id"`
+	OldValue  *time.Time `gorm:"column:old_value"`
+	NewValue  *time.Time `gorm:"column:new_value"`
+	RunID     *int64     `gorm:"column:run_id"`
+	ChangedBy string     `gorm:"column:changed_by"`
+	Reason    string     `gorm:"column:reason"`
+	ChangedAt time.Time  `gorm:"column:changed_at"`
+}
+
+func (SyncWatermarkHistory) TableName() string { return "sync_watermark_history" }
+
 // GetLastUpdated returns the last_updated_phorest for (entity, branchID).
 // For global sources like clients, pass branchID = "ALL".
 func (r *WatermarksRepo) GetLastUpdated(entity, branchID string) (*time.Time, error) {
@@ -39,7 +93,7 @@ func (r *WatermarksRepo) GetLastUpdated(entity, branchID string) (*time.Time, er
 		Where("entity = ? AND branch_id = ?", entity, branchID).
 		First(&wm).Error
 
-	if err == gorm.ErrRecordNotFound {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
 	if err != nil {
@@ -48,7 +102,8 @@ func (r *WatermarksRepo) GetLastUpdated(entity, branchID string) (*time.Time, er
 	return wm.LastUpdatedPhorest, nil
 }
 
-// UpsertLastUpdated advances the watermark for (entity, branchID) if candidate is newer.
+// UpsertLastUpdated advances the watermark for (entity, branchID) if candidate is newer,
+// and records the change in sync_watermark_history. It never moves backwards.
 // For global sources like clients, pass branchID = "ALL" (or "") – "" will be normalised.
 func (r *WatermarksRepo) UpsertLastUpdated(entity, branchID string, candidate time.Time) error {
 	if candidate.IsZero() {
@@ -56,17 +111,116 @@ func (r *WatermarksRepo) UpsertLastUpdated(entity, branchID string, candidate ti
 	}
 
 	branchID = normaliseBranchID(branchID)
+	candidate = candidate.UTC()
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		old, err := lockWatermark(tx, entity, branchID)
+		if err != nil {
+			return err
+		}
+		if old != nil && !candidate.After(*old) {
+			return nil
+		}
 
-	r.lg.Printf("💾 Updating watermark for %s/%s → %s",
-		entity, branchID, candidate.UTC().Format(time.RFC3339))
+		r.lg.Printf("💾 Updating watermark for %s/%s → %s",
+			entity, branchID, candidate.Format(time.RFC3339))
 
-	return r.db.Exec(`
+		if err := tx.Exec(`
 INSERT INTO sync_watermarks (entity, branch_id, last_updated_phorest, created_at, updated_at)
 VALUES (?, ?, ?, now(), now())
 ON CONFLICT (entity, branch_id) DO UPDATE
 SET last_updated_phorest = GREATEST(sync_watermarks.last_updated_phorest, EXCLUDED.last_updated_phorest),
     updated_at           = now();
-`, entity, branchID, candidate.UTC()).Error
+`, entity, branchID, candidate).Error; err != nil {
+			return err
+		}
+		return r.insertHistory(tx, entity, branchID, old, &candidate, r.changedBy, "")
+	})
+}
+
+// Set moves the watermark to exactly `to`, forwards or backwards, and records
+// who did it and why. It returns the previous value (nil if there was none).
+func (r *WatermarksRepo) Set(entity, branchID string, to time.Time, changedBy, reason string) (*time.Time, error) {
+	branchID = normaliseBranchID(branchID)
+	to = to.UTC()
+
+	var old *time.Time
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		old, err = lockWatermark(tx, entity, branchID)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Exec(`
+INSERT INTO sync_watermarks (entity, branch_id, last_updated_phorest, created_at, updated_at)
+VALUES (?, ?, ?, now(), now())
+ON CONFLICT (entity, branch_id) DO UPDATE
+SET last_updated_phorest = EXCLUDED.last_updated_phorest,
+    updated_at           = now();
+`, entity, branchID, to).Error; err != nil {
+			return err
+		}
+		return r.insertHistory(tx, entity, branchID, old, &to, changedBy, reason)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.lg.Printf("💾 Watermark %s/%s set → %s (%s)", entity, branchID, to.Format(time.RFC3339), changedBy)
+	return old, nil
+}
+
+// List returns every watermark row, ordered by entity and branch.
+func (r *WatermarksRepo) List() ([]SyncWatermark, error) {
+	var rows []SyncWatermark
+	err := r.db.Order("entity, branch_id").Find(&rows).Error
+	return rows, err
+}
+
+// History returns the newest changes first; empty entity/branchID mean "any".
+func (r *WatermarksRepo) History(entity, branchID string, limit int) ([]SyncWatermarkHistory, error) {
+	q := r.db.Order("changed_at DESC, id DESC")
+	if entity != "" {
+		q = q.Where("entity = ?", entity)
+	}
+	if branchID != "" {
+		q = q.Where("branch_id = ?", branchID)
+	}
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	var rows []SyncWatermarkHistory
+	err := q.Find(&rows).Error
+	return rows, err
+}
+
+// lockWatermark reads the current value and locks the row for the transaction.
+func lockWatermark(tx *gorm.DB, entity, branchID string) (*time.Time, error) {
+	var wm SyncWatermark
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("entity = ? AND branch_id = ?", entity, branchID).
+		First(&wm).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return wm.LastUpdatedPhorest, nil
+}
+
+func (r *WatermarksRepo) insertHistory(tx *gorm.DB, entity, branchID string, oldValue, newValue *time.Time, changedBy, reason string) error {
+	return tx.Create(&SyncWatermarkHistory{
+		Entity:    entity,
+		BranchID:  branchID,
+		OldValue:  oldValue,
+		NewValue:  newValue,
+		RunID:     r.runID,
+		ChangedBy: changedBy,
+		Reason:    reason,
+		ChangedAt: time.Now().UTC(),
+	}).Error
 }
 
 func normaliseBranchID(branchID string) string {
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"sort"
+
+	"github.com/araquach/phorest-datahub/internal/models"
+)
+
+// ColourServicePattern matches (case-insensitively) the service names and
+// categories that use colour products.
+const ColourServicePattern = `colou?r|tint|highlight|balayage|bleach|toner|lightening`
+
+// BackBarTotals is service volume against estimated back-bar product cost.
+type BackBarTotals struct {
+	Services       float64 `json:"services"`
+	Revenue        float64 `json:"revenue"`
+	ProductCost    float64 `json:"product_cost"`
+	CostPerService float64 `json:"cost_per_service"`
+	CostPct        float64 `json:"cost_pct"` // product cost / service revenue, 0..1
+}
+
+func (t *BackBarTotals) add(services, revenue, cost float64) {
+	t.Services += services
+	t.Revenue += revenue
+	t.ProductCost += cost
+}
+
+func (t *BackBarTotals) finish() {
+	if t.Services > 0 {
+		t.CostPerService = t.ProductCost / t.Services
+	}
+	if t.Revenue > 0 {
+		t.CostPct = t.ProductCost / t.Revenue
+	}
+}
+
+// BackBarLine is one stylist's volume of one service with its share of the
+// branch's back-bar product cost.
+type BackBarLine struct {
+	StaffID      string  `gorm:"column:staff_id" json:"staff_id"`
+	StaffName    string  `gorm:"column:staff_name" json:"staff_name"`
+	ServiceID    string  `gorm:"column:service_id" json:"service_id"`
+	ServiceName  string  `gorm:"column:service_name" json:"service_name"`
+	CategoryName string  `gorm:"column:category_name" json:"category_name"`
+	Colour       bool    `gorm:"column:colour" json:"colour"`
+	Services     float64 `gorm:"column:services" json:"services"`
+	Revenue      float64 `gorm:"column:revenue" json:"revenue"`
+	ProductCost  float64 `gorm:"-" json:"product_cost"`
+}
+
+// StaffBackBar is one stylist's back-bar cost, overall and per service.
+type StaffBackBar struct {
+	StaffID   string `json:"staff_id"`
+	StaffName string `json:"staff_name"`
+	BackBarTotals
+	Lines []BackBarLine `json:"lines"`
+}
+
+// ServiceBackBar is one service's back-bar cost across the branch.
+type ServiceBackBar struct {
+	ServiceID    string `json:"service_id"`
+	ServiceName  string `json:"service_name"`
+	CategoryName string `json:"category_name"`
+	Colour       bool   `json:"colour"`
+	BackBarTotals
+}
+
+// BackBarReport estimates a branch's professional product usage per stylist
+// and per service over a period.
+type BackBarReport struct {
+	BranchID string `json:"branch_id"`
+	Period   Period `json:"-"`
+	BackBarTotals
+
+	// Consumption pools: colour products are spread over colour services
+	// only, other professional products over every service.
+	ColourCost       float64 `json:"colour_cost"`
+	ProfessionalCost float64 `json:"professional_cost"`
+	Uncosted         int     `json:"uncosted"` // consumed products with no unit cost (not in the pools)
+
+	Staff     []StaffBackBar   `json:"staff"`
+	ByService []ServiceBackBar `json:"by_service"`
+}
+
+// ForStaff returns one stylist's rollup, or nil when they did no services.
+func (r *BackBarReport) ForStaff(staffID string) *StaffBackBar {
+	for i := range r.Staff {
+		if r.Staff[i].StaffID == staffID {
+			return &r.Staff[i]
+		}
+	}
+	return nil
+}
+
+// BackBarUsage estimates back-bar product cost per stylist and per service.
+// Consumption is the stock decrease of PROFESSIONAL/COLOUR products that
+// retail sales don't explain (see Shrinkage), at reorder_cost or
+// product_cost_price. Phorest doesn't record which service used which
+// product, so the cost is allocated by service volume from
+// transaction_items: colour product cost over colour services
+// (ColourServicePattern), the rest over all services. With no colour
+// services in the period, colour cost joins the general pool.
+func (s *StockService) BackBarUsage(ctx context.Context, branchID string, p Period) (*BackBarReport, error) {
+	usage, err := s.stockUsage(ctx, branchID, p)
+	if err != nil {
+		return nil, err
+	}
+	rep := &BackBarReport{BranchID: branchID, Period: p, Staff: []StaffBackBar{}, ByService: []ServiceBackBar{}}
+	for _, u := range usage {
+		used := u.unexplained()
+		if u.Usage != models.UsageProfessional || used == 0 {
+			continue
+		}
+		if u.UnitCost == nil {
+			rep.Uncosted++
+			continue
+		}
+		if u.Colour {
+			rep.ColourCost += used * *u.UnitCost
+		} else {
+			rep.ProfessionalCost += used * *u.UnitCost
+		}
+	}
+
+	var lines []BackBarLine
+	err = s.db.WithContext(ctx).Raw(`
+SELECT
+	staff_id,
+	TRIM(CONCAT(MAX(staff_first_name), ' ', MAX(staff_last_name)))  AS staff_name,
+	COALESCE(service_id, '')                                         AS service_id,
+	COALESCE(MAX(service_name), '')                                  AS service_name,
+	COALESCE(MAX(service_category_name), '')                         AS category_name,
+	BOOL_OR(COALESCE(service_name, '') ~* @colour
+	     OR COALESCE(service_category_name, '') ~* @colour)          AS colour,
+	COALESCE(SUM(quantity), 0)                                       AS services,
+	COALESCE(SUM(total_amount), 0)                                   AS revenue
+FROM transaction_items
+WHERE branch_id = @branch
+  AND item_type = 'SERVICE'
+  AND purchased_date BETWEEN @from AND @to
+  AND COALESCE(void, 0) = 0
+  AND COALESCE(staff_id, '') <> ''
+GROUP BY staff_id, COALESCE(service_id, '')
+ORDER BY staff_id, revenue DESC`, map[string]any{
+		"branch": branchID,
+		"from":   p.From,
+		"to":     p.To,
+		"colour": ColourServicePattern,
+	}).Scan(&lines).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var all, colour float64
+	for _, l := range lines {
+		all += l.Services
+		if l.Colour {
+			colour += l.Services
+		}
+	}
+	var generalRate, colourRate float64
+	generalPool := rep.ProfessionalCost
+	if colour > 0 {
+		colourRate = rep.ColourCost / colour
+	} else {
+		generalPool += rep.ColourCost
+	}
+	if all > 0 {
+		generalRate = generalPool / all
+	}
+
+	staffIdx := map[string]int{}
+	serviceIdx := map[string]int{}
+	for _, l := range lines {
+		l.ProductCost = l.Services * generalRate
+		if l.Colour {
+			l.ProductCost += l.Services * colourRate
+		}
+		rep.add(l.Services, l.Revenue, l.ProductCost)
+
+		i, ok := staffIdx[l.StaffID]
+		if !ok {
+			i = len(rep.Staff)
+			staffIdx[l.StaffID] = i
+			rep.Staff = append(rep.Staff, StaffBackBar{StaffID: l.StaffID, StaffName: l.StaffName})
+		}
+		rep.Staff[i].add(l.Services, l.Revenue, l.ProductCost)
+		rep.Staff[i].Lines = append(rep.Staff[i].Lines, l)
+
+		j, ok := serviceIdx[l.ServiceID]
+		if !ok {
+			j = len(rep.ByService)
+			serviceIdx[l.ServiceID] = j
+			rep.ByService = append(rep.ByService, ServiceBackBar{
+				ServiceID: l.ServiceID, ServiceName: l.ServiceName, CategoryName: l.CategoryName, Colour: l.Colour,
+			})
+		}
+		rep.ByService[j].add(l.Services, l.Revenue, l.ProductCost)
+	}
+
+	rep.finish()
+	for i := range rep.Staff {
+		rep.Staff[i].finish()
+	}
+	for i := range rep.ByService {
+		rep.ByService[i].finish()
+	}
+	sort.SliceStable(rep.Staff, func(i, j int) bool { return rep.Staff[i].ProductCost > rep.Staff[j].ProductCost })
+	sort.SliceStable(rep.ByService, func(i, j int) bool { return rep.ByService[i].ProductCost > rep.ByService[j].ProductCost })
+	return rep, nil
+}
@@ -1 +1,342 @@
 package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Period is an inclusive date range (From..To, both dates).
+type Period struct {
+	From time.Time
+	To   time.Time
+}
+
+// NewPeriod normalises both ends to midnight UTC.
+func NewPeriod(from, to time.Time) Period {
+	return Period{From: truncateDay(from), To: truncateDay(to)}
+}
+
+// Days is the number of calendar days covered by the period.
+func (p Period) Days() int {
+	return int(p.To.Sub(p.From).Hours()/24) + 1
+}
+
+// Previous returns the period of the same length immediately before p.
+func (p Period) Previous() Period {
+	to := p.From.AddDate(0, 0, -1)
+	return Period{From: to.AddDate(0, 0, -(p.Days() - 1)), To: to}
+}
+
+func (p Period) String() string {
+	return p.From.Format("2006-01-02") + " – " + p.To.Format("2006-01-02")
+}
+
+func truncateDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// MetricKind tells renderers how to format a KPI value.
+type MetricKind string
+
+const (
+	KindMoney   MetricKind = "money"
+	KindCount   MetricKind = "count"
+	KindPercent MetricKind = "percent"
+	KindRating  MetricKind = "rating"
+)
+
+// MetricDef describes one KPI that can be reported on and targeted.
+type MetricDef struct {
+	Key   string
+	Label string
+	Kind  MetricKind
+}
+
+// KPIMetrics is the ordered list of metrics shown in appraisal reports.
+// Keys are also the valid values for appraisal_targets.metric.
+var KPIMetrics = []MetricDef{
+	{Key: "total_revenue", Label: "Total revenue", Kind: KindMoney},
+	{Key: "service_revenue", Label: "Service revenue", Kind: KindMoney},
+	{Key: "retail_revenue", Label: "Retail revenue", Kind: KindMoney},
+	{Key: "service_count", Label: "Services performed", Kind: KindCount},
+	{Key: "product_units", Label: "Retail units sold", Kind: KindCount},
+	{Key: "transactions", Label: "Transactions", Kind: KindCount},
+	{Key: "unique_clients", Label: "Unique clients", Kind: KindCount},
+	{Key: "avg_ticket", Label: "Average ticket", Kind: KindMoney},
+	{Key: "retail_attach_rate", Label: "Retail attach rate", Kind: KindPercent},
+	{Key: "requested_rate", Label: "Requested rate", Kind: KindPercent},
+	{Key: "review_count", Label: "Reviews", Kind: KindCount},
+	{Key: "avg_rating", Label: "Average rating", Kind: KindRating},
+}
+
+// StaffKPIs is the per-stylist KPI snapshot for one period.
+type StaffKPIs struct {
+	StaffID            string  `gorm:"column:staff_id" json:"staff_id"`
+	ServiceRevenue     float64 `gorm:"column:service_revenue" json:"service_revenue"`
+	RetailRevenue      float64 `gorm:"column:retail_revenue" json:"retail_revenue"`
+	TotalRevenue       float64 `gorm:"column:total_revenue" json:"total_revenue"`
+	ServiceCount       float64 `gorm:"column:service_count" json:"service_count"`
+	ProductUnits       float64 `gorm:"column:product_units" json:"product_units"`
+	Transactions       int64   `gorm:"column:transactions" json:"transactions"`
+	UniqueClients      int64   `gorm:"column:unique_clients" json:"unique_clients"`
+	RetailTransactions int64   `gorm:"column:retail_transactions" json:"retail_transactions"`
+	ServiceLines       int64   `gorm:"column:service_lines" json:"service_lines"`
+	RequestedServices  int64   `gorm:"column:requested_services" json:"requested_services"`
+	ReviewCount        int64   `gorm:"-" json:"review_count"`
+	AvgRating          float64 `gorm:"-" json:"avg_rating"`
+}
+
+// AvgTicket is revenue per transaction.
+func (k StaffKPIs) AvgTicket() float64 {
+	if k.Transactions == 0 {
+		return 0
+	}
+	return k.TotalRevenue / float64(k.Transactions)
+}
+
+// RetailAttachRate is the share of transactions that included a retail product.
+func (k StaffKPIs) RetailAttachRate() float64 {
+	if k.Transactions == 0 {
+		return 0
+	}
+	return float64(k.RetailTransactions) / float64(k.Transactions)
+}
+
+// RequestedRate is the share of service lines where the client asked for this stylist.
+func (k StaffKPIs) RequestedRate() float64 {
+	if k.ServiceLines == 0 {
+		return 0
+	}
+	return float64(k.RequestedServices) / float64(k.ServiceLines)
+}
+
+// Metric returns the value for a KPIMetrics key.
+func (k StaffKPIs) Metric(key string) (float64, bool) {
+	switch key {
+	case "total_revenue":
+		return k.TotalRevenue, true
+	case "service_revenue":
+		return k.ServiceRevenue, true
+	case "retail_revenue":
+		return k.RetailRevenue, true
+	case "service_count":
+		return k.ServiceCount, true
+	case "product_units":
+		return k.ProductUnits, true
+	case "transactions":
+		return float64(k.Transactions), true
+	case "unique_clients":
+		return float64(k.UniqueClients), true
+	case "avg_ticket":
+		return k.AvgTicket(), true
+	case "retail_attach_rate":
+		return k.RetailAttachRate(), true
+	case "requested_rate":
+		return k.RequestedRate(), true
+	case "review_count":
+		return float64(k.ReviewCount), true
+	case "avg_rating":
+		return k.AvgRating, true
+	}
+	return 0, false
+}
+
+// ServiceRevenue is one row of the "top services" table.
+type ServiceRevenue struct {
+	ServiceName string  `gorm:"column:service_name" json:"service_name"`
+	Count       float64 `gorm:"column:service_count" json:"count"`
+	Revenue     float64 `gorm:"column:revenue" json:"revenue"`
+}
+
+// ProductSales is one row of the "retail products sold" table.
+type ProductSales struct {
+	ProductName string  `gorm:"column:product_name" json:"product_name"`
+	BrandName   string  `gorm:"column:product_brand_name" json:"brand_name"`
+	Units       float64 `gorm:"column:units" json:"units"`
+	Revenue     float64 `gorm:"column:revenue" json:"revenue"`
+}
+
+// KPIService computes appraisal KPIs from transaction_items and reviews.
+// Voided lines are always excluded.
+type KPIService struct {
+	db *gorm.DB
+	lg *log.Logger
+}
+
+func NewKPIService(db *gorm.DB, lg *log.Logger) *KPIService {
+	return &KPIService{db: db, lg: lg}
+}
+
+// StaffKPIs returns the KPIs for one stylist in one branch (zero values if no activity).
+func (s *KPIService) StaffKPIs(ctx context.Context, staffID, branchID string, p Period) (StaffKPIs, error) {
+	rows, err := s.kpisByStaff(ctx, branchID, staffID, p)
+	if err != nil {
+		return StaffKPIs{}, err
+	}
+	if len(rows) == 0 {
+		return StaffKPIs{StaffID: staffID}, nil
+	}
+	return rows[0], nil
+}
+
+// BranchKPIs returns one KPI row per stylist with activity in the branch.
+func (s *KPIService) BranchKPIs(ctx context.Context, branchID string, p Period) ([]StaffKPIs, error) {
+	return s.kpisByStaff(ctx, branchID, "", p)
+}
+
+// BranchAverages returns the mean of each KPI across active stylists in the branch.
+func (s *KPIService) BranchAverages(ctx context.Context, branchID string, p Period) (map[string]float64, error) {
+	rows, err := s.BranchKPIs(ctx, branchID, p)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]float64, len(KPIMetrics))
+	if len(rows) == 0 {
+		return out, nil
+	}
+	for _, m := range KPIMetrics {
+		var sum float64
+		n := 0
+		for _, r := range rows {
+			// Ratings are only meaningful for stylists who were actually reviewed.
+			if m.Key == "avg_rating" && r.ReviewCount == 0 {
+				continue
+			}
+			v, _ := r.Metric(m.Key)
+			sum += v
+			n++
+		}
+		if n > 0 {
+			out[m.Key] = sum / float64(n)
+		}
+	}
+	return out, nil
+}
+
+func (s *KPIService) kpisByStaff(ctx context.Context, branchID, staffID string, p Period) ([]StaffKPIs, error) {
+	q := `
+SELECT
+	staff_id,
+	COALESCE(SUM(CASE WHEN item_type = 'SERVICE' THEN total_amount ELSE 0 END), 0) AS service_revenue,
+	COALESCE(SUM(CASE WHEN item_type = 'PRODUCT' THEN total_amount ELSE 0 END), 0) AS retail_revenue,
+	COALESCE(SUM(total_amount), 0)                                                 AS total_revenue,
+	COALESCE(SUM(CASE WHEN item_type = 'SERVICE' THEN quantity ELSE 0 END), 0)     AS service_count,
+	COALESCE(SUM(CASE WHEN item_type = 'PRODUCT' THEN quantity ELSE 0 END), 0)     AS product_units,
+	COUNT(DISTINCT transaction_id)                                                 AS transactions,
+	COUNT(DISTINCT NULLIF(client_id, ''))                                          AS unique_clients,
+	COUNT(DISTINCT CASE WHEN item_type = 'PRODUCT' THEN transaction_id END)        AS retail_transactions,
+	COUNT(*) FILTER (WHERE item_type = 'SERVICE')                                  AS service_lines,
+	COUNT(*) FILTER (WHERE item_type = 'SERVICE' AND is_requested_staff = 1)       AS requested_services
+FROM transaction_items
+WHERE branch_id = ?
+  AND purchased_date BETWEEN ? AND ?
+  AND COALESCE(void, 0) = 0
+  AND COALESCE(staff_id, '') <> ''`
+	args := []any{branchID, p.From, p.To}
+	if staffID != "" {
+		q += "\n  AND staff_id = ?"
+		args = append(args, staffID)
+	}
+	q += "\nGROUP BY staff_id\nORDER BY total_revenue DESC"
+
+	var rows []StaffKPIs
+	if err := s.db.WithContext(ctx).Raw(q, args...).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	// Merge in review stats (a stylist can have reviews but no sales in a period, and vice versa).
+	type reviewRow struct {
+		StaffID     string  `gorm:"column:staff_id"`
+		ReviewCount int64   `gorm:"column:review_count"`
+		AvgRating   float64 `gorm:"column:avg_rating"`
+	}
+	rq := `
+SELECT staff_id, COUNT(*) AS review_count, COALESCE(AVG(rating), 0) AS avg_rating
+FROM reviews
+WHERE branch_id = ?
+  AND review_date BETWEEN ? AND ?
+  AND deleted_at IS NULL
+  AND COALESCE(staff_id, '') <> ''`
+	rargs := []any{branchID, p.From, p.To}
+	if staffID != "" {
+		rq += "\n  AND staff_id = ?"
+		rargs = append(rargs, staffID)
+	}
+	rq += "\nGROUP BY staff_id"
+
+	var reviews []reviewRow
+	if err := s.db.WithContext(ctx).Raw(rq, rargs...).Scan(&reviews).Error; err != nil {
+		return nil, err
+	}
+
+	idx := make(map[string]int, len(rows))
+	for i := range rows {
+		idx[rows[i].StaffID] = i
+	}
+	for _, rv := range reviews {
+		i, ok := idx[rv.StaffID]
+		if !ok {
+			rows = append(rows, StaffKPIs{StaffID: rv.StaffID})
+			i = len(rows) - 1
+			idx[rv.StaffID] = i
+		}
+		rows[i].ReviewCount = rv.ReviewCount
+		rows[i].AvgRating = rv.AvgRating
+	}
+
+	return rows, nil
+}
+
+// TopServices returns the stylist's services ranked by revenue.
+func (s *KPIService) TopServices(ctx context.Context, staffID, branchID string, p Period, limit int) ([]ServiceRevenue, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	var rows []ServiceRevenue
+	err := s.db.WithContext(ctx).Raw(`
+SELECT
+	service_name,
+	COALESCE(SUM(quantity), 0)     AS service_count,
+	COALESCE(SUM(total_amount), 0) AS revenue
+FROM transaction_items
+WHERE branch_id = ?
+  AND staff_id = ?
+  AND item_type = 'SERVICE'
+  AND purchased_date BETWEEN ? AND ?
+  AND COALESCE(void, 0) = 0
+  AND COALESCE(service_name, '') <> ''
+GROUP BY service_name
+ORDER BY revenue DESC
+LIMIT ?`, branchID, staffID, p.From, p.To, limit).Scan(&rows).Error
+	return rows, err
+}
+
+// RetailProducts returns the retail products the stylist sold, ranked by revenue.
+func (s *KPIService) RetailProducts(ctx context.Context, staffID, branchID string, p Period, limit int) ([]ProductSales, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	var rows []ProductSales
+	err := s.db.WithContext(ctx).Raw(`
+SELECT
+	product_name,
+	MAX(product_brand_name)        AS product_brand_name,
+	COALESCE(SUM(quantity), 0)     AS units,
+	COALESCE(SUM(total_amount), 0) AS revenue
+FROM transaction_items
+WHERE branch_id = ?
+  AND staff_id = ?
+  AND item_type = 'PRODUCT'
+  AND purchased_date BETWEEN ? AND ?
+  AND COALESCE(void, 0) = 0
+  AND COALESCE(product_name, '') <> ''
+GROUP BY product_name
+ORDER BY revenue DESC
+LIMIT ?`, branchID, staffID, p.From, p.To, limit).Scan(&rows).Error
+	return rows, err
+}
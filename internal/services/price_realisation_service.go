@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// PriceRealisationService compares what services sold for with their list
+// price in the synced catalogue.
+type PriceRealisationService struct {
+	db *gorm.DB
+	lg *log.Logger
+}
+
+func NewPriceRealisationService(db *gorm.DB, lg *log.Logger) *PriceRealisationService {
+	return &PriceRealisationService{db: db, lg: lg}
+}
+
+// RealisationTotals is realised service revenue against list value. Lines
+// with no list price count in Services and Unpriced only.
+type RealisationTotals struct {
+	Services    float64 `json:"services"`
+	Unpriced    float64 `json:"unpriced"`
+	ListValue   float64 `json:"list_value"`
+	Realised    float64 `json:"realised"`    // of the priced lines
+	Discount    float64 `json:"discount"`    // list value - realised
+	Realisation float64 `json:"realisation"` // realised / list value, 0..1 (above 1 when sold over list)
+}
+
+func (t *RealisationTotals) add(o RealisationTotals) {
+	t.Services += o.Services
+	t.Unpriced += o.Unpriced
+	t.ListValue += o.ListValue
+	t.Realised += o.Realised
+}
+
+func (t *RealisationTotals) finish() {
+	t.Discount = t.ListValue - t.Realised
+	t.Realisation = 0
+	if t.ListValue > 0 {
+		t.Realisation = t.Realised / t.ListValue
+	}
+}
+
+// RealisationLine is one stylist's sales of one service.
+type RealisationLine struct {
+	StaffID      string `gorm:"column:staff_id" json:"staff_id"`
+	StaffName    string `gorm:"column:staff_name" json:"staff_name"`
+	ServiceID    string `gorm:"column:service_id" json:"service_id"`
+	ServiceName  string `gorm:"column:service_name" json:"service_name"`
+	CategoryName string `gorm:"column:category_name" json:"category_name"`
+	RealisationTotals
+}
+
+// StaffRealisation is one stylist's price realisation, overall and per service.
+type StaffRealisation struct {
+	StaffID   string `json:"staff_id"`
+	StaffName string `json:"staff_name"`
+	RealisationTotals
+	Lines []RealisationLine `json:"lines"`
+}
+
+// PriceRealisationReport is a branch's price realisation per stylist over a period.
+type PriceRealisationReport struct {
+	BranchID string `json:"branch_id"`
+	Period   Period `json:"-"`
+	RealisationTotals
+	Staff []StaffRealisation `json:"staff"`
+}
+
+// ForStaff returns one stylist's realisation, or nil when they sold no services.
+func (r *PriceRealisationReport) ForStaff(staffID string) *StaffRealisation {
+	for i := range r.Staff {
+		if r.Staff[i].StaffID == staffID {
+			return &r.Staff[i]
+		}
+	}
+	return nil
+}
+
+// PriceRealisation sums each stylist's service lines (dv_service_items)
+// against the list price in force on the day of sale, or the current list
+// price for sales before the catalogue's first snapshot.
+func (s *PriceRealisationService) PriceRealisation(ctx context.Context, branchID string, p Period) (*PriceRealisationReport, error) {
+	var lines []struct {
+		RealisationLine
+		PricedRealised float64 `gorm:"column:priced_realised"`
+	}
+	err := s.db.WithContext(ctx).Raw(`
+SELECT
+	staff_id,
+	MAX(staff_name)                                                     AS staff_name,
+	COALESCE(service_id, '')                                            AS service_id,
+	COALESCE(MAX(service_name), '')                                     AS service_name,
+	COALESCE(MAX(category_name), '')                                    AS category_name,
+	SUM(quantity)                                                       AS services,
+	COALESCE(SUM(quantity) FILTER (WHERE list_price IS NULL), 0)        AS unpriced,
+	COALESCE(SUM(list_price * quantity), 0)                             AS list_value,
+	COALESCE(SUM(realised) FILTER (WHERE list_price IS NOT NULL), 0)    AS priced_realised
+FROM dv_service_items
+WHERE branch_id = @branch
+  AND purchased_date BETWEEN @from AND @to
+  AND COALESCE(staff_id, '') <> ''
+GROUP BY staff_id, COALESCE(service_id, '')
+ORDER BY staff_id, list_value DESC`, map[string]any{
+		"branch": branchID,
+		"from":   p.From,
+		"to":     p.To,
+	}).Scan(&lines).Error
+	if err != nil {
+		return nil, err
+	}
+
+	rep := &PriceRealisationReport{BranchID: branchID, Period: p, Staff: []StaffRealisation{}}
+	idx := map[string]int{}
+	for _, row := range lines {
+		l := row.RealisationLine
+		l.Realised = row.PricedRealised
+		l.finish()
+
+		i, ok := idx[l.StaffID]
+		if !ok {
+			i = len(rep.Staff)
+			idx[l.StaffID] = i
+			rep.Staff = append(rep.Staff, StaffRealisation{StaffID: l.StaffID, StaffName: l.StaffName})
+		}
+		rep.Staff[i].add(l.RealisationTotals)
+		rep.Staff[i].Lines = append(rep.Staff[i].Lines, l)
+		rep.add(l.RealisationTotals)
+	}
+	for i := range rep.Staff {
+		rep.Staff[i].finish()
+	}
+	rep.finish()
+	return rep, nil
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Windows used by RatingTrends. The baseline is the stylist's own history
+// before the recent window, so a stylist is compared with themselves.
+const (
+	RecentWindowDays   = 30
+	TrendWindowDays    = 90
+	BaselineWindowDays = 365
+)
+
+// RatingTrend is one stylist's rolling review stats as of a date.
+type RatingTrend struct {
+	StaffID       string   `gorm:"column:staff_id" json:"staff_id"`
+	Count30       int64    `gorm:"column:count_30" json:"count_30"`
+	Avg30         *float64 `gorm:"column:avg_30" json:"avg_30"`
+	Low30         int64    `gorm:"column:low_30" json:"low_30"` // 1–2 star reviews
+	Count90       int64    `gorm:"column:count_90" json:"count_90"`
+	Avg90         *float64 `gorm:"column:avg_90" json:"avg_90"`
+	BaselineCount int64    `gorm:"column:baseline_count" json:"baseline_count"`
+	BaselineAvg   *float64 `gorm:"column:baseline_avg" json:"baseline_avg"`
+	BaselineSD    *float64 `gorm:"column:baseline_sd" json:"baseline_sd"`
+}
+
+// RecentRating is one of a stylist's latest reviews, newest first.
+type RecentRating struct {
+	StaffID    string    `gorm:"column:staff_id"`
+	ReviewID   string    `gorm:"column:review_id"`
+	Rating     int       `gorm:"column:rating"`
+	ReviewDate time.Time `gorm:"column:review_date"`
+}
+
+// RatingTrendsService computes rolling review averages per stylist from
+// reviews (deleted reviews excluded).
+type RatingTrendsService struct {
+	db *gorm.DB
+	lg *log.Logger
+}
+
+func NewRatingTrendsService(db *gorm.DB, lg *log.Logger) *RatingTrendsService {
+	return &RatingTrendsService{db: db, lg: lg}
+}
+
+// BranchTrends returns one row per stylist reviewed in the branch during the
+// last TrendWindowDays+BaselineWindowDays up to asOf.
+func (s *RatingTrendsService) BranchTrends(ctx context.Context, branchID string, asOf time.Time) ([]RatingTrend, error) {
+	asOf = truncateDay(asOf)
+	recentFrom := asOf.AddDate(0, 0, -(RecentWindowDays - 1))
+	trendFrom := asOf.AddDate(0, 0, -(TrendWindowDays - 1))
+	baseFrom := recentFrom.AddDate(0, 0, -BaselineWindowDays)
+
+	q := `
+SELECT
+	staff_id,
+	COUNT(*) FILTER (WHERE review_date >= @recent)                     AS count_30,
+	AVG(rating) FILTER (WHERE review_date >= @recent)                  AS avg_30,
+	COUNT(*) FILTER (WHERE review_date >= @recent AND rating <= 2)     AS low_30,
+	COUNT(*) FILTER (WHERE review_date >= @trend)                      AS count_90,
+	AVG(rating) FILTER (WHERE review_date >= @trend)                   AS avg_90,
+	COUNT(*) FILTER (WHERE review_date < @recent)                      AS baseline_count,
+	AVG(rating) FILTER (WHERE review_date < @recent)                   AS baseline_avg,
+	STDDEV_SAMP(rating) FILTER (WHERE review_date < @recent)           AS baseline_sd
+FROM reviews
+WHERE branch_id = @branch
+  AND review_date BETWEEN @base AND @asof
+  AND deleted_at IS NULL
+  AND rating > 0
+  AND COALESCE(staff_id, '') <> ''
+GROUP BY staff_id
+ORDER BY staff_id`
+
+	var rows []RatingTrend
+	err := s.db.WithContext(ctx).Raw(q, map[string]any{
+		"branch": branchID,
+		"recent": recentFrom,
+		"trend":  trendFrom,
+		"base":   baseFrom,
+		"asof":   asOf,
+	}).Scan(&rows).Error
+	return rows, err
+}
+
+// LatestRatings returns each stylist's n most recent rated reviews up to
+// asOf, newest first.
+func (s *RatingTrendsService) LatestRatings(ctx context.Context, branchID string, asOf time.Time, n int) ([]RecentRating, error) {
+	q := `
+SELECT staff_id, review_id, rating, review_date
+FROM (
+	SELECT staff_id, review_id, rating, review_date,
+	       ROW_NUMBER() OVER (PARTITION BY staff_id ORDER BY review_date DESC, review_id DESC) AS rn
+	FROM reviews
+	WHERE branch_id = ?
+	  AND review_date <= ?
+	  AND deleted_at IS NULL
+	  AND rating > 0
+	  AND COALESCE(staff_id, '') <> ''
+) latest
+WHERE rn <= ?
+ORDER BY staff_id, rn`
+
+	var rows []RecentRating
+	err := s.db.WithContext(ctx).Raw(q, branchID, truncateDay(asOf), n).Scan(&rows).Error
+	return rows, err
+}
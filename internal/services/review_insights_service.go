@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// TopicInsight is how a stylist's reviews talked about one topic in a period.
+type TopicInsight struct {
+	StaffID      string  `gorm:"column:staff_id" json:"-"`
+	Topic        string  `gorm:"column:topic" json:"topic"`
+	Reviews      int64   `gorm:"column:reviews" json:"reviews"`
+	AvgSentiment float64 `gorm:"column:avg_sentiment" json:"avg_sentiment"`
+	Praise       int64   `gorm:"column:praise" json:"praise"`
+	Complaints   int64   `gorm:"column:complaints" json:"complaints"`
+}
+
+// SnippetQuote is a praise or complaint sentence for the report.
+type SnippetQuote struct {
+	StaffID    string  `gorm:"column:staff_id" json:"-"`
+	Kind       string  `gorm:"column:kind" json:"kind"`
+	Topic      string  `gorm:"column:topic" json:"topic"`
+	Text       string  `gorm:"column:text" json:"text"`
+	Score      float64 `gorm:"column:score" json:"score"`
+	ReviewDate string  `gorm:"column:review_date" json:"review_date"`
+	Rating     int     `gorm:"column:rating" json:"rating"`
+}
+
+// StaffReviewInsights rolls review analysis up per stylist for one period.
+type StaffReviewInsights struct {
+	StaffID      string         `gorm:"column:staff_id" json:"staff_id"`
+	Reviews      int64          `gorm:"column:reviews" json:"reviews"`
+	AvgSentiment float64        `gorm:"column:avg_sentiment" json:"avg_sentiment"`
+	Positive     int64          `gorm:"column:positive" json:"positive"`
+	Neutral      int64          `gorm:"column:neutral" json:"neutral"`
+	Negative     int64          `gorm:"column:negative" json:"negative"`
+	Topics       []TopicInsight `gorm:"-" json:"topics"`
+	Praise       []SnippetQuote `gorm:"-" json:"praise"`
+	Complaints   []SnippetQuote `gorm:"-" json:"complaints"`
+}
+
+// ReviewInsightsService rolls the stored review analysis (see
+// internal/analysis) up per stylist. Deleted reviews are always excluded.
+type ReviewInsightsService struct {
+	db *gorm.DB
+	lg *log.Logger
+
+	// MaxQuotes is how many praise and complaint snippets to return per stylist.
+	MaxQuotes int
+}
+
+func NewReviewInsightsService(db *gorm.DB, lg *log.Logger) *ReviewInsightsService {
+	return &ReviewInsightsService{db: db, lg: lg, MaxQuotes: 3}
+}
+
+// StaffInsights returns the rollup for one stylist (zero values if no analysed reviews).
+func (s *ReviewInsightsService) StaffInsights(ctx context.Context, staffID, branchID string, p Period) (StaffReviewInsights, error) {
+	rows, err := s.insightsByStaff(ctx, branchID, staffID, p)
+	if err != nil {
+		return StaffReviewInsights{}, err
+	}
+	if len(rows) == 0 {
+		return StaffReviewInsights{StaffID: staffID}, nil
+	}
+	return rows[0], nil
+}
+
+// BranchInsights returns one rollup per stylist with analysed reviews in the branch.
+func (s *ReviewInsightsService) BranchInsights(ctx context.Context, branchID string, p Period) ([]StaffReviewInsights, error) {
+	return s.insightsByStaff(ctx, branchID, "", p)
+}
+
+// reviewScope is the shared WHERE for every rollup query (reviews aliased r).
+const reviewScope = `
+WHERE r.branch_id = ?
+  AND r.review_date BETWEEN ? AND ?
+  AND r.deleted_at IS NULL
+  AND COALESCE(r.staff_id, '') <> ''`
+
+func (s *ReviewInsightsService) insightsByStaff(ctx context.Context, branchID, staffID string, p Period) ([]StaffReviewInsights, error) {
+	scope := reviewScope
+	args := []any{branchID, p.From, p.To}
+	if staffID != "" {
+		scope += "\n  AND r.staff_id = ?"
+		args = append(args, staffID)
+	}
+	db := s.db.WithContext(ctx)
+
+	var rows []StaffReviewInsights
+	q := `
+SELECT
+	r.staff_id,
+	COUNT(*)                                      AS reviews,
+	COALESCE(AVG(a.sentiment), 0)                 AS avg_sentiment,
+	COUNT(*) FILTER (WHERE a.label = 'positive')  AS positive,
+	COUNT(*) FILTER (WHERE a.label = 'neutral')   AS neutral,
+	COUNT(*) FILTER (WHERE a.label = 'negative')  AS negative
+FROM reviews r
+JOIN review_analysis a ON a.review_id = r.review_id` + scope + `
+GROUP BY r.staff_id
+ORDER BY reviews DESC`
+	if err := db.Raw(q, args...).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return rows, nil
+	}
+
+	var topics []TopicInsight
+	q = `
+SELECT
+	r.staff_id,
+	t.topic,
+	COUNT(*)                        AS reviews,
+	COALESCE(AVG(t.sentiment), 0)   AS avg_sentiment,
+	COALESCE(SUM(sn.praise), 0)     AS praise,
+	COALESCE(SUM(sn.complaints), 0) AS complaints
+FROM reviews r
+JOIN review_topics t ON t.review_id = r.review_id
+LEFT JOIN (
+	SELECT review_id, topic,
+	       COUNT(*) FILTER (WHERE kind = 'praise')    AS praise,
+	       COUNT(*) FILTER (WHERE kind = 'complaint') AS complaints
+	FROM review_snippets
+	GROUP BY review_id, topic
+) sn ON sn.review_id = t.review_id AND sn.topic = t.topic` + scope + `
+GROUP BY r.staff_id, t.topic
+ORDER BY r.staff_id, reviews DESC, t.topic`
+	if err := db.Raw(q, args...).Scan(&topics).Error; err != nil {
+		return nil, err
+	}
+
+	var quotes []SnippetQuote
+	q = `
+SELECT staff_id, kind, topic, text, score, review_date, rating
+FROM (
+	SELECT
+		r.staff_id, sn.kind, sn.topic, sn.text, sn.score,
+		r.review_date::text AS review_date, r.rating,
+		ROW_NUMBER() OVER (
+			PARTITION BY r.staff_id, sn.kind
+			ORDER BY ABS(sn.score) DESC, r.review_date DESC, sn.id
+		) AS rn
+	FROM reviews r
+	-- a sentence tagged with two topics is stored twice; quote it once
+	JOIN (
+		SELECT DISTINCT ON (review_id, kind, text) *
+		FROM review_snippets
+		ORDER BY review_id, kind, text, id
+	) sn ON sn.review_id = r.review_id` + scope + `
+) ranked
+WHERE rn <= ?
+ORDER BY staff_id, kind, rn`
+	if err := db.Raw(q, append(args, s.MaxQuotes)...).Scan(&quotes).Error; err != nil {
+		return nil, err
+	}
+
+	byStaff := make(map[string]*StaffReviewInsights, len(rows))
+	for i := range rows {
+		byStaff[rows[i].StaffID] = &rows[i]
+	}
+	for _, t := range topics {
+		if st := byStaff[t.StaffID]; st != nil {
+			st.Topics = append(st.Topics, t)
+		}
+	}
+	for _, sq := range quotes {
+		st := byStaff[sq.StaffID]
+		if st == nil {
+			continue
+		}
+		if sq.Kind == "complaint" {
+			st.Complaints = append(st.Complaints, sq)
+		} else {
+			st.Praise = append(st.Praise, sq)
+		}
+	}
+	return rows, nil
+}
@@ -1 +1,63 @@
 package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/araquach/phorest-datahub/internal/models"
+	"gorm.io/gorm"
+)
+
+// StaffService answers read-side questions about stylists (lookup, branch listings).
+type StaffService struct {
+	db *gorm.DB
+	lg *log.Logger
+}
+
+func NewStaffService(db *gorm.DB, lg *log.Logger) *StaffService {
+	return &StaffService{db: db, lg: lg}
+}
+
+// Find returns the staff row for (staffID, branchID).
+// If branchID is empty, the first non-archived row for that staff member is used.
+func (s *StaffService) Find(ctx context.Context, staffID, branchID string) (*models.Staff, error) {
+	q := s.db.WithContext(ctx).Where("staff_id = ?", staffID)
+	if branchID != "" {
+		q = q.Where("branch_id = ?", branchID)
+	}
+
+	var st models.Staff
+	err := q.Order("archived ASC, id ASC").First(&st).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("staff %s not found (branch=%q)", staffID, branchID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// ListByBranch returns all active staff for a branch, ordered by name.
+func (s *StaffService) ListByBranch(ctx context.Context, branchID string) ([]models.Staff, error) {
+	var rows []models.Staff
+	err := s.db.WithContext(ctx).
+		Where("branch_id = ? AND archived = false", branchID).
+		Order("first_name, last_name").
+		Find(&rows).Error
+	return rows, err
+}
+
+// Branch returns the synced branch row, or nil if branches haven't been synced yet.
+func (s *StaffService) Branch(ctx context.Context, branchID string) (*models.Branch, error) {
+	var b models.Branch
+	err := s.db.WithContext(ctx).Where("branch_id = ?", branchID).First(&b).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
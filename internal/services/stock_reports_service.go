@@ -0,0 +1,278 @@
+package services
+
+import (
+	"context"
+	"time"
+)
+
+// ---------- Valuation ----------
+
+// StockValueLine is one product's stock on hand in a branch at the end of a day.
+type StockValueLine struct {
+	ProductID    string     `gorm:"column:product_id" json:"product_id"`
+	ProductName  string     `gorm:"column:product_name" json:"product_name"`
+	BrandName    string     `gorm:"column:brand_name" json:"brand_name"`
+	CategoryName string     `gorm:"column:category_name" json:"category_name"`
+	Quantity     float64    `gorm:"column:quantity" json:"quantity"`
+	UnitCost     *float64   `gorm:"column:unit_cost" json:"unit_cost"`
+	UnitPrice    *float64   `gorm:"column:unit_price" json:"unit_price"`
+	CostValue    float64    `gorm:"column:cost_value" json:"cost_value"`
+	RetailValue  float64    `gorm:"column:retail_value" json:"retail_value"`
+	StockDate    *time.Time `gorm:"column:stock_date" json:"stock_date"` // the day the quantity was last recorded
+}
+
+// BrandValuation totals a brand's lines.
+type BrandValuation struct {
+	BrandName   string  `json:"brand_name"`
+	Products    int     `json:"products"`
+	Units       float64 `json:"units"`
+	CostValue   float64 `json:"cost_value"`
+	RetailValue float64 `json:"retail_value"`
+}
+
+// StockValuation is a branch's stock value at cost and at retail as of the
+// end of AsOf.
+type StockValuation struct {
+	BranchID    string           `json:"branch_id"`
+	AsOf        time.Time        `json:"as_of"`
+	Products    int              `json:"products"`
+	Units       float64          `json:"units"`
+	CostValue   float64          `json:"cost_value"`
+	RetailValue float64          `json:"retail_value"`
+	Uncosted    int              `json:"uncosted"` // lines with stock but no reorder_cost
+	Brands      []BrandValuation `json:"brands"`
+	Lines       []StockValueLine `json:"lines"`
+}
+
+// Valuation values a branch's stock at the end of asOf from each product's
+// last dv_daily_stock_levels row on or before that day. History doesn't
+// keep cost prices, so cost is the current reorder_cost; retail uses the
+// snapshot price, falling back to the current price. Negative quantities
+// count as zero.
+func (s *StockService) Valuation(ctx context.Context, branchID string, asOf time.Time) (*StockValuation, error) {
+	asOf = truncateDay(asOf)
+	q := `
+WITH last AS (
+	SELECT DISTINCT ON (d.product_id)
+		d.product_id, d.stock_date, d.quantity_in_stock, d.price
+	FROM dv_daily_stock_levels d
+	WHERE d.branch_id = @branch
+	  AND d.stock_date <= @asof
+	ORDER BY d.product_id, d.stock_date DESC
+)
+SELECT
+	l.product_id,
+	p.name                                          AS product_name,
+	COALESCE(p.brand_name, '')                      AS brand_name,
+	COALESCE(p.category_name, '')                   AS category_name,
+	GREATEST(COALESCE(l.quantity_in_stock, 0), 0)   AS quantity,
+	st.reorder_cost                                 AS unit_cost,
+	COALESCE(l.price, st.price)                     AS unit_price,
+	GREATEST(COALESCE(l.quantity_in_stock, 0), 0) * COALESCE(st.reorder_cost, 0)     AS cost_value,
+	GREATEST(COALESCE(l.quantity_in_stock, 0), 0) * COALESCE(l.price, st.price, 0)   AS retail_value,
+	l.stock_date
+FROM last l
+JOIN ph_products p ON p.id = l.product_id
+LEFT JOIN ph_product_stock st ON st.product_id = l.product_id AND st.branch_id = @branch
+WHERE COALESCE(l.quantity_in_stock, 0) > 0
+ORDER BY COALESCE(p.brand_name, ''), p.name, l.product_id`
+
+	var lines []StockValueLine
+	err := s.db.WithContext(ctx).Raw(q, map[string]any{
+		"branch": branchID,
+		"asof":   asOf,
+	}).Scan(&lines).Error
+	if err != nil {
+		return nil, err
+	}
+
+	v := &StockValuation{BranchID: branchID, AsOf: asOf, Brands: []BrandValuation{}, Lines: lines}
+	for _, l := range lines {
+		if n := len(v.Brands); n == 0 || v.Brands[n-1].BrandName != l.BrandName {
+			v.Brands = append(v.Brands, BrandValuation{BrandName: l.BrandName})
+		}
+		b := &v.Brands[len(v.Brands)-1]
+		b.Products++
+		b.Units += l.Quantity
+		b.CostValue += l.CostValue
+		b.RetailValue += l.RetailValue
+
+		v.Products++
+		v.Units += l.Quantity
+		v.CostValue += l.CostValue
+		v.RetailValue += l.RetailValue
+		if l.UnitCost == nil {
+			v.Uncosted++
+		}
+	}
+	return v, nil
+}
+
+// ---------- Movements ----------
+
+// StockMovement is one product's stock change in a branch over a period.
+// Received and Removed add up the rises and falls between consecutive synced
+// snapshots; Reconciled is the stock written off by reconcile zeroing
+// (negative when a product marked removed came back), so
+// Opening + Received − Removed − Reconciled = Closing.
+type StockMovement struct {
+	ProductID   string  `gorm:"column:product_id" json:"product_id"`
+	ProductName string  `gorm:"column:product_name" json:"product_name"`
+	BrandName   string  `gorm:"column:brand_name" json:"brand_name"`
+	Opening     float64 `gorm:"column:opening" json:"opening"`
+	Closing     float64 `gorm:"column:closing" json:"closing"`
+	Net         float64 `gorm:"column:net" json:"net"`
+	Received    float64 `gorm:"column:received" json:"received"`
+	Removed     float64 `gorm:"column:removed" json:"removed"`
+	Reconciled  float64 `gorm:"column:reconciled" json:"reconciled"`
+	Snapshots   int64   `gorm:"column:snapshots" json:"snapshots"` // history rows in the period
+	FirstSeen   bool    `gorm:"column:first_seen" json:"first_seen"`
+}
+
+// stockMovesCTE defines h (the branch's history rows before @end with the
+// previous quantity) and moves (rises and falls between consecutive
+// snapshots from @start on, per product). The zero rows MarkRemoved writes
+// (source 'reconcile') aren't stock movements: they are skipped when
+// summing rises and falls (move_prev_qty is the previous synced quantity),
+// but still count for closing quantities.
+const stockMovesCTE = `h AS (
+	SELECT
+		product_id, snapshot_time, id,
+		COALESCE(quantity_in_stock, 0) AS qty,
+		source = 'reconcile'           AS reconcile,
+		LAG(COALESCE(quantity_in_stock, 0)) OVER (PARTITION BY product_id ORDER BY snapshot_time, id) AS prev_qty,
+		LAG(COALESCE(quantity_in_stock, 0)) OVER (PARTITION BY product_id, source = 'reconcile' ORDER BY snapshot_time, id) AS move_prev_qty
+	FROM ph_product_stock_history
+	WHERE branch_id = @branch
+	  AND snapshot_time < @end
+),
+moves AS (
+	SELECT
+		product_id,
+		COUNT(*)                                                                                     AS snapshots,
+		COALESCE(SUM(GREATEST(qty - move_prev_qty, 0)) FILTER (WHERE move_prev_qty IS NOT NULL), 0) AS received,
+		COALESCE(SUM(GREATEST(move_prev_qty - qty, 0)) FILTER (WHERE move_prev_qty IS NOT NULL), 0) AS removed
+	FROM h
+	WHERE snapshot_time >= @start
+	  AND NOT reconcile
+	GROUP BY product_id
+)`
+
+// stockMovementsSQL computes per-product movements for one branch over
+// [@start, @end). A product first synced during the period opens at its
+// first recorded quantity (FirstSeen).
+const stockMovementsSQL = `
+WITH ` + stockMovesCTE + `,
+opening AS (
+	SELECT DISTINCT ON (product_id) product_id, qty
+	FROM h
+	WHERE snapshot_time < @start
+	ORDER BY product_id, snapshot_time DESC, id DESC
+),
+first_in AS (
+	SELECT DISTINCT ON (product_id) product_id, qty
+	FROM h
+	WHERE snapshot_time >= @start AND prev_qty IS NULL
+	ORDER BY product_id, snapshot_time, id
+),
+closing AS (
+	SELECT DISTINCT ON (product_id) product_id, qty
+	FROM h
+	ORDER BY product_id, snapshot_time DESC, id DESC
+)
+SELECT
+	c.product_id,
+	p.name                                         AS product_name,
+	COALESCE(p.brand_name, '')                     AS brand_name,
+	COALESCE(o.qty, f.qty, 0)                      AS opening,
+	c.qty                                          AS closing,
+	c.qty - COALESCE(o.qty, f.qty, 0)              AS net,
+	COALESCE(m.received, 0)                        AS received,
+	COALESCE(m.removed, 0)                         AS removed,
+	COALESCE(o.qty, f.qty, 0) + COALESCE(m.received, 0) - COALESCE(m.removed, 0) - c.qty AS reconciled,
+	COALESCE(m.snapshots, 0)                       AS snapshots,
+	(o.product_id IS NULL)                         AS first_seen
+FROM closing c
+JOIN ph_products p ON p.id = c.product_id
+LEFT JOIN opening o  ON o.product_id = c.product_id
+LEFT JOIN first_in f ON f.product_id = c.product_id
+LEFT JOIN moves m    ON m.product_id = c.product_id`
+
+// Movements returns opening, closing and net stock per product for the
+// period. With movedOnly, products whose stock didn't change are left out.
+func (s *StockService) Movements(ctx context.Context, branchID string, p Period, movedOnly bool) ([]StockMovement, error) {
+	q := stockMovementsSQL
+	if movedOnly {
+		q += "\nWHERE COALESCE(m.received, 0) <> 0 OR COALESCE(m.removed, 0) <> 0 OR c.qty <> COALESCE(o.qty, f.qty, 0)"
+	}
+	q += "\nORDER BY COALESCE(p.brand_name, ''), p.name, c.product_id"
+
+	var rows []StockMovement
+	err := s.db.WithContext(ctx).Raw(q, map[string]any{
+		"branch": branchID,
+		"start":  p.From,
+		"end":    p.To.AddDate(0, 0, 1),
+	}).Scan(&rows).Error
+	return rows, err
+}
+
+// ---------- Dead stock ----------
+
+// DeadStockLine is a product with stock on hand but no retail sales for a while.
+type DeadStockLine struct {
+	ProductID       string     `gorm:"column:product_id" json:"product_id"`
+	ProductName     string     `gorm:"column:product_name" json:"product_name"`
+	BrandName       string     `gorm:"column:brand_name" json:"brand_name"`
+	CategoryName    string     `gorm:"column:category_name" json:"category_name"`
+	QuantityInStock float64    `gorm:"column:quantity_in_stock" json:"quantity_in_stock"`
+	CostValue       float64    `gorm:"column:cost_value" json:"cost_value"`
+	RetailValue     float64    `gorm:"column:retail_value" json:"retail_value"`
+	LastSold        *time.Time `gorm:"column:last_sold" json:"last_sold"` // nil = never sold (in our data)
+	DaysSinceSale   *int       `gorm:"column:days_since_sale" json:"days_since_sale"`
+}
+
+// DeadStock lists live products the branch currently holds that haven't sold
+// through transaction_items (non-void PRODUCT lines) in the days up to asOf,
+// largest cost value first.
+func (s *StockService) DeadStock(ctx context.Context, branchID string, days int, asOf time.Time) ([]DeadStockLine, error) {
+	asOf = truncateDay(asOf)
+	q := `
+WITH last_sale AS (
+	SELECT product_id, MAX(purchased_date) AS last_sold
+	FROM transaction_items
+	WHERE branch_id = @branch
+	  AND item_type = 'PRODUCT'
+	  AND COALESCE(void, 0) = 0
+	  AND COALESCE(product_id, '') <> ''
+	  AND purchased_date <= @asof
+	GROUP BY product_id
+)
+SELECT
+	st.product_id,
+	p.name                                               AS product_name,
+	COALESCE(p.brand_name, '')                           AS brand_name,
+	COALESCE(p.category_name, '')                        AS category_name,
+	st.quantity_in_stock,
+	st.quantity_in_stock * COALESCE(st.reorder_cost, 0)  AS cost_value,
+	st.quantity_in_stock * COALESCE(st.price, 0)         AS retail_value,
+	ls.last_sold,
+	(CAST(@asof AS date) - ls.last_sold)                  AS days_since_sale
+FROM ph_product_stock st
+JOIN ph_products p ON p.id = st.product_id
+LEFT JOIN last_sale ls ON ls.product_id = st.product_id
+WHERE st.branch_id = @branch
+  AND st.archived = false
+  AND st.removed_at IS NULL
+  AND p.archived = false
+  AND st.quantity_in_stock > 0
+  AND (ls.last_sold IS NULL OR ls.last_sold < @since)
+ORDER BY cost_value DESC, p.name`
+
+	var rows []DeadStockLine
+	err := s.db.WithContext(ctx).Raw(q, map[string]any{
+		"branch": branchID,
+		"asof":   asOf,
+		"since":  asOf.AddDate(0, 0, -(days - 1)),
+	}).Scan(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"log"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReorderLine is one product at or below its minimum stock level in a branch.
+type ReorderLine struct {
+	BranchID        string   `gorm:"column:branch_id" json:"branch_id"`
+	ProductID       string   `gorm:"column:product_id" json:"product_id"`
+	ProductName     string   `gorm:"column:product_name" json:"product_name"`
+	BrandName       string   `gorm:"column:brand_name" json:"brand_name"`
+	CategoryName    string   `gorm:"column:category_name" json:"category_name"`
+	Code            string   `gorm:"column:code" json:"code"`
+	QuantityInStock float64  `gorm:"column:quantity_in_stock" json:"quantity_in_stock"`
+	MinQuantity     float64  `gorm:"column:min_quantity" json:"min_quantity"`
+	MaxQuantity     *float64 `gorm:"column:max_quantity" json:"max_quantity"`
+	ReorderCount    *float64 `gorm:"column:reorder_count" json:"reorder_count"`
+	UnitCost        *float64 `gorm:"column:reorder_cost" json:"unit_cost"`
+
+	// SuggestedQty tops the stock up to MaxQuantity; LineCost is
+	// SuggestedQty × UnitCost (nil when Phorest has no reorder cost).
+	SuggestedQty float64  `gorm:"-" json:"suggested_qty"`
+	LineCost     *float64 `gorm:"-" json:"line_cost"`
+}
+
+// ReorderBrand groups a branch's reorder lines for one brand (one supplier
+// order, in practice).
+type ReorderBrand struct {
+	BrandName string        `json:"brand_name"`
+	Lines     []ReorderLine `json:"lines"`
+	Units     float64       `json:"units"`
+	Cost      float64       `json:"cost"`
+}
+
+// ReorderReport is the reorder list for one branch.
+type ReorderReport struct {
+	BranchID    string         `json:"branch_id"`
+	GeneratedAt time.Time      `json:"generated_at"`
+	Brands      []ReorderBrand `json:"brands"`
+	Lines       int            `json:"lines"`
+	Units       float64        `json:"units"`
+	Cost        float64        `json:"cost"`
+	Uncosted    int            `json:"uncosted"` // lines with no reorder_cost (not in Cost)
+}
+
+// StockService reads current stock from ph_product_stock / ph_products.
+type StockService struct {
+	db *gorm.DB
+	lg *log.Logger
+}
+
+func NewStockService(db *gorm.DB, lg *log.Logger) *StockService {
+	return &StockService{db: db, lg: lg}
+}
+
+// Reorder lists the branch's live products whose quantity is at or below a
+// set minimum, with a suggested order quantity and cost, grouped by brand
+// (brand, then product name). Products without a minimum are never listed.
+func (s *StockService) Reorder(ctx context.Context, branchID string) (*ReorderReport, error) {
+	q := `
+SELECT
+	st.branch_id,
+	st.product_id,
+	p.name                            AS product_name,
+	COALESCE(p.brand_name, '')        AS brand_name,
+	COALESCE(p.category_name, '')     AS category_name,
+	COALESCE(p.code, '')              AS code,
+	COALESCE(st.quantity_in_stock, 0) AS quantity_in_stock,
+	st.min_quantity,
+	st.max_quantity,
+	st.reorder_count,
+	st.reorder_cost
+FROM ph_product_stock st
+JOIN ph_products p ON p.id = st.product_id
+WHERE st.branch_id = ?
+  AND st.archived = false
+  AND st.removed_at IS NULL
+  AND p.archived = false
+  AND st.min_quantity > 0
+  AND COALESCE(st.quantity_in_stock, 0) <= st.min_quantity
+ORDER BY COALESCE(p.brand_name, ''), p.name, st.product_id`
+
+	var lines []ReorderLine
+	if err := s.db.WithContext(ctx).Raw(q, branchID).Scan(&lines).Error; err != nil {
+		return nil, err
+	}
+
+	rep := &ReorderReport{BranchID: branchID, GeneratedAt: time.Now().UTC(), Brands: []ReorderBrand{}}
+	for i := range lines {
+		l := &lines[i]
+		l.SuggestedQty = SuggestedOrderQty(l.QuantityInStock, l.MinQuantity, l.MaxQuantity, l.ReorderCount)
+		if l.UnitCost != nil {
+			c := l.SuggestedQty * *l.UnitCost
+			l.LineCost = &c
+		}
+
+		if n := len(rep.Brands); n == 0 || rep.Brands[n-1].BrandName != l.BrandName {
+			rep.Brands = append(rep.Brands, ReorderBrand{BrandName: l.BrandName})
+		}
+		b := &rep.Brands[len(rep.Brands)-1]
+		b.Lines = append(b.Lines, *l)
+		b.Units += l.SuggestedQty
+		rep.Units += l.SuggestedQty
+		if l.LineCost != nil {
+			b.Cost += *l.LineCost
+			rep.Cost += *l.LineCost
+		} else {
+			rep.Uncosted++
+		}
+		rep.Lines++
+	}
+	return rep, nil
+}
+
+// SuggestedOrderQty is how many whole units bring qty back up to max. With
+// no usable max it falls back to Phorest's reorder count, and failing that
+// to one unit above the minimum.
+func SuggestedOrderQty(qty, minQty float64, maxQty, reorderCount *float64) float64 {
+	if qty < 0 {
+		qty = 0 // sales booked ahead of deliveries; there's nothing below zero to replace
+	}
+	switch {
+	case maxQty != nil && *maxQty > minQty:
+		return math.Ceil(*maxQty - qty)
+	case reorderCount != nil && *reorderCount > 0:
+		return math.Ceil(*reorderCount)
+	}
+	return math.Ceil(minQty-qty) + 1
+}
@@ -0,0 +1,27 @@
+package services
+
+import "testing"
+
+func TestSuggestedOrderQty(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name         string
+		qty, min     float64
+		max, reorder *float64
+		want         float64
+	}{
+		{"up to max", 2, 3, f(10), f(6), 8},
+		{"fractional rounds up", 2.5, 3, f(10), nil, 8},
+		{"negative stock counts as zero", -4, 3, f(10), nil, 10},
+		{"max not above min uses reorder count", 1, 5, f(5), f(6), 6},
+		{"no max uses reorder count", 1, 5, nil, f(4), 4},
+		{"nothing set tops up past min", 1, 5, nil, nil, 5},
+		{"zero reorder count ignored", 0, 2, nil, f(0), 3},
+	}
+	for _, tt := range tests {
+		if got := SuggestedOrderQty(tt.qty, tt.min, tt.max, tt.reorder); got != tt.want {
+			t.Errorf("%s: SuggestedOrderQty = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
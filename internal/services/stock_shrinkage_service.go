@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/araquach/phorest-datahub/internal/models"
+)
+
+// stockUsageSQL gathers per-product stock decreases and retail sales for one
+// branch over [@start, @end).
+const stockUsageSQL = `
+WITH ` + stockMovesCTE + `,
+sales AS (
+	SELECT
+		product_id,
+		SUM(COALESCE(quantity, 0))  AS sold,
+		AVG(product_cost_price)     AS sale_cost
+	FROM transaction_items
+	WHERE branch_id = @branch
+	  AND item_type = 'PRODUCT'
+	  AND COALESCE(void, 0) = 0
+	  AND COALESCE(product_id, '') <> ''
+	  AND purchased_date >= @start
+	  AND purchased_date < @end
+	GROUP BY product_id
+),
+prods AS (
+	SELECT product_id FROM moves
+	UNION
+	SELECT product_id FROM sales
+)
+SELECT
+	x.product_id,
+	p.name                                              AS product_name,
+	COALESCE(p.brand_name, '')                          AS brand_name,
+	CASE WHEN p.type_raw ILIKE '%PROFESSIONAL%' OR p.type_raw ILIKE '%COLOUR%'
+	     THEN 'professional' ELSE 'retail' END          AS usage,
+	COALESCE(p.type_raw ILIKE '%COLOUR%', false)        AS colour,
+	COALESCE(m.received, 0)                             AS received,
+	COALESCE(m.removed, 0)                              AS removed,
+	GREATEST(COALESCE(s.sold, 0), 0)                    AS sold,
+	COALESCE(st.reorder_cost, s.sale_cost)              AS unit_cost
+FROM prods x
+JOIN ph_products p ON p.id = x.product_id
+LEFT JOIN moves m ON m.product_id = x.product_id
+LEFT JOIN sales s ON s.product_id = x.product_id
+LEFT JOIN ph_product_stock st ON st.product_id = x.product_id AND st.branch_id = @branch
+ORDER BY COALESCE(p.brand_name, ''), p.name, x.product_id`
+
+// stockUsage is one product's decreases and retail sales over a period.
+type stockUsage struct {
+	ProductID   string   `gorm:"column:product_id"`
+	ProductName string   `gorm:"column:product_name"`
+	BrandName   string   `gorm:"column:brand_name"`
+	Usage       string   `gorm:"column:usage"`
+	Colour      bool     `gorm:"column:colour"`
+	Received    float64  `gorm:"column:received"`
+	Removed     float64  `gorm:"column:removed"`
+	Sold        float64  `gorm:"column:sold"`
+	UnitCost    *float64 `gorm:"column:unit_cost"`
+}
+
+// unexplained is the decrease retail sales don't account for.
+func (u stockUsage) unexplained() float64 { return math.Max(u.Removed-u.Sold, 0) }
+
+func (s *StockService) stockUsage(ctx context.Context, branchID string, p Period) ([]stockUsage, error) {
+	var rows []stockUsage
+	err := s.db.WithContext(ctx).Raw(stockUsageSQL, map[string]any{
+		"branch": branchID,
+		"start":  p.From,
+		"end":    p.To.AddDate(0, 0, 1),
+	}).Scan(&rows).Error
+	return rows, err
+}
+
+// Shrinkage reconciles each product's stock decreases in the branch over the
+// period against its retail sales. Decreases that sales don't explain count
+// as professional use for back-bar products (type PROFESSIONAL or COLOUR)
+// and as shrinkage otherwise; any shrinkage flags the row. Sales beyond the
+// recorded decreases (usually a missed snapshot) are kept as UnmatchedSales.
+// Unit cost is reorder_cost, falling back to the sales' product_cost_price.
+func (s *StockService) Shrinkage(ctx context.Context, branchID string, p Period) ([]models.StockShrinkage, error) {
+	in, err := s.stockUsage(ctx, branchID, p)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	rows := make([]models.StockShrinkage, 0, len(in))
+	for _, x := range in {
+		row := models.StockShrinkage{
+			BranchID:       branchID,
+			ProductID:      x.ProductID,
+			PeriodStart:    p.From,
+			PeriodEnd:      p.To,
+			ProductName:    x.ProductName,
+			BrandName:      x.BrandName,
+			Usage:          x.Usage,
+			Received:       x.Received,
+			Removed:        x.Removed,
+			Sold:           x.Sold,
+			UnmatchedSales: math.Max(x.Sold-x.Removed, 0),
+			UnitCost:       x.UnitCost,
+			ComputedAt:     now,
+		}
+		unexplained := x.unexplained()
+		if x.Usage == models.UsageProfessional {
+			row.ProfessionalUse = unexplained
+		} else {
+			row.Shrinkage = unexplained
+		}
+		if x.UnitCost != nil {
+			row.ProfessionalCost = row.ProfessionalUse * *x.UnitCost
+			row.ShrinkageCost = row.Shrinkage * *x.UnitCost
+		}
+		row.Flagged = row.Shrinkage > 0
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// ShrinkageTotals adds up a set of shrinkage rows.
+type ShrinkageTotals struct {
+	Products         int     `json:"products"`
+	Flagged          int     `json:"flagged"`
+	Removed          float64 `json:"removed"`
+	Sold             float64 `json:"sold"`
+	ProfessionalUse  float64 `json:"professional_use"`
+	Shrinkage        float64 `json:"shrinkage"`
+	ProfessionalCost float64 `json:"professional_cost"`
+	ShrinkageCost    float64 `json:"shrinkage_cost"`
+}
+
+func (t *ShrinkageTotals) add(r models.StockShrinkage) {
+	t.Products++
+	if r.Flagged {
+		t.Flagged++
+	}
+	t.Removed += r.Removed
+	t.Sold += r.Sold
+	t.ProfessionalUse += r.ProfessionalUse
+	t.Shrinkage += r.Shrinkage
+	t.ProfessionalCost += r.ProfessionalCost
+	t.ShrinkageCost += r.ShrinkageCost
+}
+
+// BrandShrinkage is one brand's totals.
+type BrandShrinkage struct {
+	BrandName string `json:"brand_name"`
+	ShrinkageTotals
+}
+
+// ShrinkageSummary totals a branch's shrinkage rows overall and per brand,
+// brands with the most shrinkage cost first.
+type ShrinkageSummary struct {
+	ShrinkageTotals
+	Brands []BrandShrinkage `json:"brands"`
+}
+
+func SummariseShrinkage(rows []models.StockShrinkage) ShrinkageSummary {
+	sum := ShrinkageSummary{Brands: []BrandShrinkage{}}
+	idx := map[string]int{}
+	for _, r := range rows {
+		sum.add(r)
+		i, ok := idx[r.BrandName]
+		if !ok {
+			i = len(sum.Brands)
+			idx[r.BrandName] = i
+			sum.Brands = append(sum.Brands, BrandShrinkage{BrandName: r.BrandName})
+		}
+		sum.Brands[i].add(r)
+	}
+	sort.SliceStable(sum.Brands, func(i, j int) bool {
+		return sum.Brands[i].ShrinkageCost > sum.Brands[j].ShrinkageCost
+	})
+	return sum
+}
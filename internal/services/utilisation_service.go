@@ -0,0 +1,347 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/araquach/phorest-datahub/internal/models"
+	"gorm.io/gorm"
+)
+
+// UtilisationService measures stylists' booked time against their rostered time.
+type UtilisationService struct {
+	db *gorm.DB
+	lg *log.Logger
+}
+
+func NewUtilisationService(db *gorm.DB, lg *log.Logger) *UtilisationService {
+	return &UtilisationService{db: db, lg: lg}
+}
+
+// UtilisationTotals is rostered vs booked time, in minutes.
+type UtilisationTotals struct {
+	RosteredMinutes  float64 `json:"rostered_minutes"`
+	BreakMinutes     float64 `json:"break_minutes"`
+	TimeOffMinutes   float64 `json:"time_off_minutes"`
+	AvailableMinutes float64 `json:"available_minutes"` // rostered minus breaks and time off
+	BookedMinutes    float64 `json:"booked_minutes"`    // non-cancelled appointments, no-shows included
+	NoShowMinutes    float64 `json:"no_show_minutes"`
+	Utilisation      float64 `json:"utilisation"` // booked / available (0..1, above 1 when overbooked)
+}
+
+func (t *UtilisationTotals) add(o UtilisationTotals) {
+	t.RosteredMinutes += o.RosteredMinutes
+	t.BreakMinutes += o.BreakMinutes
+	t.TimeOffMinutes += o.TimeOffMinutes
+	t.AvailableMinutes += o.AvailableMinutes
+	t.BookedMinutes += o.BookedMinutes
+	t.NoShowMinutes += o.NoShowMinutes
+}
+
+func (t *UtilisationTotals) finish() {
+	t.Utilisation = 0
+	if t.AvailableMinutes > 0 {
+		t.Utilisation = t.BookedMinutes / t.AvailableMinutes
+	}
+}
+
+// UtilisationDay is one stylist's day. Estimated means the booked minutes
+// come from transaction items because no appointments were synced that day.
+type UtilisationDay struct {
+	Date      string `json:"date"`
+	Estimated bool   `json:"estimated"`
+	UtilisationTotals
+}
+
+// UtilisationWeek is one stylist's Monday–Sunday week.
+type UtilisationWeek struct {
+	WeekStart string `json:"week_start"`
+	UtilisationTotals
+}
+
+// StaffUtilisation is one stylist's utilisation over the period, by day and week.
+type StaffUtilisation struct {
+	StaffID   string `json:"staff_id"`
+	StaffName string `json:"staff_name"`
+	UtilisationTotals
+	Days  []UtilisationDay  `json:"days"`
+	Weeks []UtilisationWeek `json:"weeks"`
+}
+
+// UtilisationReport is a branch's utilisation per stylist over a period.
+type UtilisationReport struct {
+	BranchID string `json:"branch_id"`
+	Period   Period `json:"-"`
+	UtilisationTotals
+	Staff []StaffUtilisation `json:"staff"`
+}
+
+// ForStaff returns one stylist's utilisation, or nil when they were neither
+// rostered nor booked.
+func (r *UtilisationReport) ForStaff(staffID string) *StaffUtilisation {
+	for i := range r.Staff {
+		if r.Staff[i].StaffID == staffID {
+			return &r.Staff[i]
+		}
+	}
+	return nil
+}
+
+// bookedDay is one stylist's booked minutes on one day.
+type bookedDay struct {
+	StaffID   string    `gorm:"column:staff_id"`
+	Day       time.Time `gorm:"column:day"`
+	Booked    float64   `gorm:"column:booked"`
+	NoShow    float64   `gorm:"column:no_show"`
+	Estimated bool      `gorm:"column:estimated"`
+}
+
+// bookedSQL sums appointment minutes per stylist and day. On days with no
+// synced appointments for the branch, service lines in transaction_items
+// are timed at their service's average appointment length instead, or its
+// catalogue duration in ph_services when it was never booked (lines that
+// can't be timed either way are skipped).
+const bookedSQL = `
+WITH appts AS (
+	SELECT
+		staff_id,
+		appointment_date                                                        AS day,
+		SUM(EXTRACT(EPOCH FROM end_at - start_at) / 60)                         AS booked,
+		COALESCE(SUM(EXTRACT(EPOCH FROM end_at - start_at) / 60) FILTER (WHERE no_show), 0) AS no_show
+	FROM appointments
+	WHERE branch_id = @branch
+	  AND appointment_date BETWEEN @from AND @to
+	  AND NOT cancelled
+	  AND COALESCE(staff_id, '') <> ''
+	GROUP BY staff_id, appointment_date
+),
+appt_days AS (
+	SELECT DISTINCT appointment_date AS day
+	FROM appointments
+	WHERE branch_id = @branch
+	  AND appointment_date BETWEEN @from AND @to
+),
+appt_len AS (
+	SELECT service_id, AVG(EXTRACT(EPOCH FROM end_at - start_at) / 60) AS minutes
+	FROM appointments
+	WHERE branch_id = @branch
+	  AND NOT cancelled
+	  AND end_at > start_at
+	  AND COALESCE(service_id, '') <> ''
+	GROUP BY service_id
+),
+service_len AS (
+	SELECT COALESCE(a.service_id, s.id) AS service_id, COALESCE(a.minutes, s.duration_minutes) AS minutes
+	FROM appt_len a
+	FULL JOIN (
+		SELECT id, duration_minutes FROM ph_services
+		WHERE branch_id = @branch AND duration_minutes > 0
+	) s ON s.id = a.service_id
+),
+items AS (
+	SELECT
+		ti.staff_id,
+		ti.purchased_date                                 AS day,
+		SUM(COALESCE(NULLIF(ti.quantity, 0), 1) * sl.minutes) AS booked
+	FROM transaction_items ti
+	JOIN service_len sl ON sl.service_id = ti.service_id
+	WHERE ti.branch_id = @branch
+	  AND ti.item_type = 'SERVICE'
+	  AND COALESCE(ti.void, 0) = 0
+	  AND COALESCE(ti.staff_id, '') <> ''
+	  AND ti.purchased_date BETWEEN @from AND @to
+	  AND ti.purchased_date NOT IN (SELECT day FROM appt_days)
+	GROUP BY ti.staff_id, ti.purchased_date
+)
+SELECT staff_id, day, booked, no_show, false AS estimated FROM appts
+UNION ALL
+SELECT staff_id, day, booked, 0, true FROM items`
+
+// Utilisation computes each stylist's booked minutes over available rostered
+// minutes per day and week. Available time is the work timetable minus
+// breaks and time off falling inside it (overlaps counted once).
+func (s *UtilisationService) Utilisation(ctx context.Context, branchID string, p Period) (*UtilisationReport, error) {
+	db := s.db.WithContext(ctx)
+	end := p.To.AddDate(0, 0, 1)
+
+	var shifts []models.StaffWorkTimetable
+	if err := db.Where("branch_id = ? AND work_date BETWEEN ? AND ?", branchID, p.From, p.To).
+		Order("staff_id, start_at").Find(&shifts).Error; err != nil {
+		return nil, err
+	}
+	var breaks []models.StaffBreak
+	if err := db.Where("branch_id = ? AND break_date BETWEEN ? AND ?", branchID, p.From, p.To).
+		Find(&breaks).Error; err != nil {
+		return nil, err
+	}
+	var timeOff []models.StaffTimeOff
+	if err := db.Where("branch_id = ? AND start_at < ? AND end_at > ?", branchID, end, p.From).
+		Find(&timeOff).Error; err != nil {
+		return nil, err
+	}
+	var booked []bookedDay
+	if err := db.Raw(bookedSQL, map[string]any{
+		"branch": branchID,
+		"from":   p.From,
+		"to":     p.To,
+	}).Scan(&booked).Error; err != nil {
+		return nil, err
+	}
+	var staff []models.Staff
+	if err := db.Where("branch_id = ?", branchID).Find(&staff).Error; err != nil {
+		return nil, err
+	}
+
+	type key struct{ staff, day string }
+	days := map[key]*UtilisationDay{}
+	get := func(staffID string, d time.Time) *UtilisationDay {
+		k := key{staffID, d.Format("2006-01-02")}
+		if days[k] == nil {
+			days[k] = &UtilisationDay{Date: k.day}
+		}
+		return days[k]
+	}
+
+	blocksBy := map[key][]span{}
+	for _, b := range breaks {
+		k := key{b.StaffID, b.BreakDate.Format("2006-01-02")}
+		blocksBy[k] = append(blocksBy[k], span{b.StartAt, b.EndAt})
+	}
+	offBy := map[string][]span{}
+	for _, t := range timeOff {
+		offBy[t.StaffID] = append(offBy[t.StaffID], span{t.StartAt, t.EndAt})
+	}
+
+	for _, sh := range shifts {
+		shift := span{sh.StartAt, sh.EndAt}
+		d := get(sh.StaffID, sh.WorkDate)
+		d.RosteredMinutes += shift.minutes()
+
+		var blocks []span
+		for _, b := range blocksBy[key{sh.StaffID, d.Date}] {
+			d.BreakMinutes += shift.overlap(b).minutes()
+			blocks = append(blocks, b)
+		}
+		for _, t := range offBy[sh.StaffID] {
+			d.TimeOffMinutes += shift.overlap(t).minutes()
+			blocks = append(blocks, t)
+		}
+		d.AvailableMinutes += shift.minutes() - shift.covered(blocks)
+	}
+	for _, b := range booked {
+		d := get(b.StaffID, b.Day)
+		d.BookedMinutes += b.Booked
+		d.NoShowMinutes += b.NoShow
+		d.Estimated = d.Estimated || b.Estimated
+	}
+
+	names := map[string]string{}
+	for _, st := range staff {
+		names[st.StaffID] = strings.TrimSpace(st.FirstName + " " + st.LastName)
+	}
+
+	rep := &UtilisationReport{BranchID: branchID, Period: p, Staff: []StaffUtilisation{}}
+	byStaff := map[string]*StaffUtilisation{}
+	keys := make([]key, 0, len(days))
+	for k := range days {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].staff != keys[j].staff {
+			return keys[i].staff < keys[j].staff
+		}
+		return keys[i].day < keys[j].day
+	})
+	for _, k := range keys {
+		su := byStaff[k.staff]
+		if su == nil {
+			su = &StaffUtilisation{StaffID: k.staff, StaffName: names[k.staff]}
+			byStaff[k.staff] = su
+		}
+		d := days[k]
+		d.finish()
+		su.add(d.UtilisationTotals)
+		su.Days = append(su.Days, *d)
+
+		day, _ := time.Parse("2006-01-02", d.Date)
+		week := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7)).Format("2006-01-02")
+		if n := len(su.Weeks); n == 0 || su.Weeks[n-1].WeekStart != week {
+			su.Weeks = append(su.Weeks, UtilisationWeek{WeekStart: week})
+		}
+		su.Weeks[len(su.Weeks)-1].add(d.UtilisationTotals)
+	}
+
+	for _, su := range byStaff {
+		for i := range su.Weeks {
+			su.Weeks[i].finish()
+		}
+		su.finish()
+		rep.add(su.UtilisationTotals)
+		rep.Staff = append(rep.Staff, *su)
+	}
+	rep.finish()
+	sort.Slice(rep.Staff, func(i, j int) bool {
+		if rep.Staff[i].StaffName != rep.Staff[j].StaffName {
+			return rep.Staff[i].StaffName < rep.Staff[j].StaffName
+		}
+		return rep.Staff[i].StaffID < rep.Staff[j].StaffID
+	})
+	return rep, nil
+}
+
+// span is a wall-clock interval.
+type span struct{ from, to time.Time }
+
+func (s span) minutes() float64 {
+	if !s.to.After(s.from) {
+		return 0
+	}
+	return s.to.Sub(s.from).Minutes()
+}
+
+// overlap is the part of o inside s (empty when they don't meet).
+func (s span) overlap(o span) span {
+	out := s
+	if o.from.After(out.from) {
+		out.from = o.from
+	}
+	if o.to.Before(out.to) {
+		out.to = o.to
+	}
+	return out
+}
+
+// covered is how many of s's minutes fall inside any of blocks.
+func (s span) covered(blocks []span) float64 {
+	clipped := make([]span, 0, len(blocks))
+	for _, b := range blocks {
+		if c := s.overlap(b); c.minutes() > 0 {
+			clipped = append(clipped, c)
+		}
+	}
+	sort.Slice(clipped, func(i, j int) bool { return clipped[i].from.Before(clipped[j].from) })
+
+	var total float64
+	var cur *span
+	for i := range clipped {
+		c := clipped[i]
+		switch {
+		case cur == nil:
+			cur = &c
+		case !c.from.After(cur.to):
+			if c.to.After(cur.to) {
+				cur.to = c.to
+			}
+		default:
+			total += cur.minutes()
+			cur = &c
+		}
+	}
+	if cur != nil {
+		total += cur.minutes()
+	}
+	return total
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSpanCovered(t *testing.T) {
+	at := func(h, m int) time.Time { return time.Date(2025, 3, 3, h, m, 0, 0, time.UTC) }
+	shift := span{at(9, 0), at(17, 0)}
+
+	tests := []struct {
+		name   string
+		blocks []span
+		want   float64
+	}{
+		{"none", nil, 0},
+		{"one inside", []span{{at(12, 0), at(12, 30)}}, 30},
+		{"clipped to the shift", []span{{at(8, 0), at(9, 30)}, {at(16, 45), at(18, 0)}}, 45},
+		{"overlaps counted once", []span{{at(12, 0), at(13, 0)}, {at(12, 30), at(13, 30)}}, 90},
+		{"touching spans merge", []span{{at(10, 0), at(11, 0)}, {at(11, 0), at(11, 15)}}, 75},
+		{"nested", []span{{at(10, 0), at(14, 0)}, {at(11, 0), at(12, 0)}}, 240},
+		{"outside the shift", []span{{at(18, 0), at(19, 0)}}, 0},
+		{"whole day off", []span{{at(0, 0), at(24, 0)}}, 480},
+	}
+	for _, tt := range tests {
+		if got := shift.covered(tt.blocks); got != tt.want {
+			t.Errorf("%s: covered = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSpanOverlap(t *testing.T) {
+	at := func(h int) time.Time { return time.Date(2025, 3, 3, h, 0, 0, 0, time.UTC) }
+	s := span{at(9), at(17)}
+	if got := s.overlap(span{at(15), at(20)}).minutes(); got != 120 {
+		t.Errorf("partial overlap = %v minutes, want 120", got)
+	}
+	if got := s.overlap(span{at(18), at(20)}).minutes(); got != 0 {
+		t.Errorf("disjoint overlap = %v minutes, want 0", got)
+	}
+}
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS appraisal_targets;
@@ -0,0 +1,17 @@
+-- Per-stylist targets used by the appraisal report (target attainment section)
+CREATE TABLE appraisal_targets (
+                                   id           BIGSERIAL PRIMARY KEY,
+                                   staff_id     TEXT NOT NULL,
+                                   branch_id    TEXT NOT NULL,
+                                   metric       TEXT NOT NULL,            -- e.g. 'service_revenue', 'retail_revenue', 'avg_rating'
+                                   period_start DATE NOT NULL,
+                                   period_end   DATE NOT NULL,
+                                   target_value NUMERIC NOT NULL,
+                                   created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+                                   updated_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+                                   UNIQUE (staff_id, branch_id, metric, period_start, period_end)
+);
+
+CREATE INDEX idx_appraisal_targets_staff_period
+    ON appraisal_targets (staff_id, branch_id, period_start, period_end);
@@ -0,0 +1,32 @@
+-- API keys for cmd/appraisals-api. Only the SHA-256 of the key is stored;
+-- key_prefix is the public part used to look a key up.
+CREATE TABLE api_keys (
+                          id           BIGSERIAL PRIMARY KEY,
+                          name         TEXT NOT NULL,
+                          key_prefix   TEXT NOT NULL UNIQUE,
+                          key_hash     TEXT NOT NULL,
+                          scopes       TEXT NOT NULL DEFAULT '',   -- comma-separated, e.g. 'read:kpis,read:clients-pii'
+                          branch_ids   TEXT NOT NULL DEFAULT '',   -- comma-separated Phorest branch IDs; '' = all branches
+                          expires_at   TIMESTAMPTZ,
+                          revoked_at   TIMESTAMPTZ,
+                          last_used_at TIMESTAMPTZ,
+                          created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+                          updated_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- One row per API request (authenticated or not)
+CREATE TABLE api_audit_log (
+                               id          BIGSERIAL PRIMARY KEY,
+                               api_key_id  BIGINT REFERENCES api_keys(id) ON DELETE SET NULL,
+                               key_prefix  TEXT,
+                               method      TEXT NOT NULL,
+                               path        TEXT NOT NULL,
+                               query       TEXT,
+                               status      INT NOT NULL,
+                               duration_ms BIGINT NOT NULL,
+                               remote_addr TEXT,
+                               created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_api_audit_log_key_time ON api_audit_log (api_key_id, created_at);
+CREATE INDEX idx_api_audit_log_time ON api_audit_log (created_at);
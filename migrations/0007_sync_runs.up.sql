@@ -0,0 +1,18 @@
+-- One row per Runner sync invocation (CLI, cron or API-triggered)
+CREATE TABLE sync_runs (
+                           id           BIGSERIAL PRIMARY KEY,
+                           kind         TEXT NOT NULL,              -- 'transactions', 'reviews', 'products', ...
+                           branch_id    TEXT NOT NULL DEFAULT 'ALL',
+                           window_from  DATE,
+                           window_to    DATE,
+                           triggered_by TEXT NOT NULL DEFAULT 'cli', -- 'cli', 'api'
+                           requested_by TEXT,                        -- API key prefix when triggered_by = 'api'
+                           status       TEXT NOT NULL,               -- 'running', 'succeeded', 'failed', 'cancelled'
+                           error        TEXT,
+                           log          TEXT,
+                           started_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+                           finished_at  TIMESTAMPTZ
+);
+
+CREATE INDEX idx_sync_runs_kind_branch_started ON sync_runs (kind, branch_id, started_at DESC);
+CREATE INDEX idx_sync_runs_status ON sync_runs (status);
@@ -0,0 +1,17 @@
+-- Audit trail for sync_watermarks: one row per change, whether a sync advanced
+-- it or someone moved it by hand (appraisals-watermarks set|rewind).
+CREATE TABLE sync_watermark_history (
+                                        id         BIGSERIAL PRIMARY KEY,
+                                        entity     TEXT NOT NULL,
+                                        branch_id  TEXT NOT NULL,
+                                        old_value  TIMESTAMPTZ,                 -- NULL when the watermark was first created
+                                        new_value  TIMESTAMPTZ,
+                                        run_id     BIGINT REFERENCES sync_runs(id) ON DELETE SET NULL,
+                                        changed_by TEXT NOT NULL,               -- 'sync', 'bootstrap', 'manual'
+                                        reason     TEXT,
+                                        changed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_sync_watermark_history_entity_branch
+    ON sync_watermark_history (entity, branch_id, changed_at DESC);
+CREATE INDEX idx_sync_watermark_history_run ON sync_watermark_history (run_id);
@@ -0,0 +1,2 @@
+-- Which strategy a run used (e.g. reviews: incremental / full / latest / reconcile)
+ALTER TABLE sync_runs ADD COLUMN strategy TEXT;
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS review_changes;
+
+DROP INDEX IF EXISTS idx_reviews_deleted_at;
+ALTER TABLE reviews
+    DROP COLUMN IF EXISTS deleted_at,
+    DROP COLUMN IF EXISTS content_hash;
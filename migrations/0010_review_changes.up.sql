@@ -0,0 +1,23 @@
+-- Reviews are edited in Phorest (text, rating, staff attribution) and
+-- sometimes removed. content_hash lets the sync spot edits cheaply;
+-- deleted_at is set by the reconcile pass when Phorest stops returning one.
+ALTER TABLE reviews
+    ADD COLUMN content_hash TEXT,
+    ADD COLUMN deleted_at   TIMESTAMPTZ;
+
+CREATE INDEX idx_reviews_deleted_at ON reviews (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- One row per changed field per review per sync.
+CREATE TABLE review_changes (
+                                id         BIGSERIAL PRIMARY KEY,
+                                review_id  TEXT NOT NULL,
+                                branch_id  TEXT NOT NULL,
+                                field      TEXT NOT NULL,           -- 'rating', 'staff_id', 'text', ..., 'deleted'
+                                old_value  TEXT,
+                                new_value  TEXT,
+                                run_id     BIGINT REFERENCES sync_runs(id) ON DELETE SET NULL,
+                                changed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_review_changes_review ON review_changes (review_id, changed_at DESC);
+CREATE INDEX idx_review_changes_branch_changed ON review_changes (branch_id, changed_at DESC);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS review_snippets;
+DROP TABLE IF EXISTS review_topics;
+DROP TABLE IF EXISTS review_analysis;
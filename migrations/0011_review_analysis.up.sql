@@ -0,0 +1,33 @@
+-- Offline text analysis of reviews (internal/analysis). One row per review;
+-- re-analysed when the review's content_hash or the lexicon version changes.
+CREATE TABLE review_analysis (
+                                 review_id        TEXT PRIMARY KEY,
+                                 sentiment        NUMERIC(5,3) NOT NULL,   -- -1..1
+                                 label            TEXT NOT NULL,           -- 'positive', 'neutral', 'negative'
+                                 content_hash     TEXT,                    -- reviews.content_hash that was analysed
+                                 analyzer_version TEXT NOT NULL,
+                                 analysed_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Topics a review mentions and how it talks about them.
+CREATE TABLE review_topics (
+                               review_id TEXT NOT NULL REFERENCES review_analysis(review_id) ON DELETE CASCADE,
+                               topic     TEXT NOT NULL,                   -- 'colour', 'cut', 'friendliness', 'wait_time', 'price'
+                               mentions  INT NOT NULL,
+                               sentiment NUMERIC(5,3) NOT NULL,
+                               PRIMARY KEY (review_id, topic)
+);
+
+CREATE INDEX idx_review_topics_topic ON review_topics (topic);
+
+-- Quotable praise/complaint sentences.
+CREATE TABLE review_snippets (
+                                 id        BIGSERIAL PRIMARY KEY,
+                                 review_id TEXT NOT NULL REFERENCES review_analysis(review_id) ON DELETE CASCADE,
+                                 kind      TEXT NOT NULL,                   -- 'praise', 'complaint'
+                                 topic     TEXT NOT NULL,                   -- a topic above, or 'general'
+                                 text      TEXT NOT NULL,
+                                 score     NUMERIC(5,3) NOT NULL
+);
+
+CREATE INDEX idx_review_snippets_review ON review_snippets (review_id);
@@ -0,0 +1,21 @@
+-- Alerts raised by monitors (e.g. review rating drops). At most one open
+-- alert per kind/branch/staff: re-detections update it, and it is resolved
+-- once the condition clears.
+CREATE TABLE alerts (
+                        id           BIGSERIAL PRIMARY KEY,
+                        kind         TEXT NOT NULL,                -- 'rating_drop', 'low_rating_run', ...
+                        severity     TEXT NOT NULL,                -- 'warning', 'critical'
+                        branch_id    TEXT NOT NULL,
+                        staff_id     TEXT NOT NULL DEFAULT '',     -- '' for branch-level alerts
+                        title        TEXT NOT NULL,
+                        detail       TEXT,
+                        value        NUMERIC(10,3),                -- the observed metric (e.g. 30-day avg rating)
+                        baseline     NUMERIC(10,3),                -- what it was compared with
+                        raised_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+                        last_seen_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+                        notified_at  TIMESTAMPTZ,
+                        resolved_at  TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX uq_alerts_open ON alerts (kind, branch_id, staff_id) WHERE resolved_at IS NULL;
+CREATE INDEX idx_alerts_branch_raised ON alerts (branch_id, raised_at DESC);
@@ -0,0 +1,22 @@
+-- Delivery log for outbound notifications: one row per event per channel,
+-- written once delivery succeeded or the retries ran out.
+CREATE TABLE notification_deliveries (
+                                         id           BIGSERIAL PRIMARY KEY,
+                                         event_type   TEXT NOT NULL,                -- 'sync.failed', 'data.stale', ...
+                                         event_key    TEXT NOT NULL DEFAULT '',     -- dedupe key; '' = always deliver
+                                         channel      TEXT NOT NULL,                -- channel name from NOTIFY_CONFIG
+                                         severity     TEXT NOT NULL,
+                                         branch_id    TEXT NOT NULL DEFAULT '',
+                                         staff_id     TEXT NOT NULL DEFAULT '',
+                                         title        TEXT NOT NULL,
+                                         payload      JSONB NOT NULL,
+                                         status       TEXT NOT NULL,                -- 'delivered', 'failed'
+                                         attempts     INT NOT NULL DEFAULT 0,
+                                         last_error   TEXT,
+                                         created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+                                         delivered_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_notification_deliveries_created ON notification_deliveries (created_at DESC);
+CREATE INDEX idx_notification_deliveries_key ON notification_deliveries (channel, event_key)
+    WHERE status = 'delivered' AND event_key <> '';
@@ -0,0 +1,30 @@
+-- Stock loss reconciliation per branch/product/period. Decreases between
+-- ph_product_stock_history snapshots are matched against retail sales in
+-- transaction_items; what sales don't explain is professional use for
+-- back-bar (PROFESSIONAL / COLOUR) products and shrinkage otherwise.
+-- Re-running a period replaces its rows.
+CREATE TABLE stock_shrinkage (
+                                 branch_id         TEXT NOT NULL,
+                                 product_id        TEXT NOT NULL,
+                                 period_start      DATE NOT NULL,
+                                 period_end        DATE NOT NULL,       -- inclusive
+                                 product_name      TEXT NOT NULL,
+                                 brand_name        TEXT NOT NULL DEFAULT '',
+                                 usage             TEXT NOT NULL,       -- 'retail', 'professional'
+                                 received          NUMERIC NOT NULL DEFAULT 0,
+                                 removed           NUMERIC NOT NULL DEFAULT 0,  -- stock decreases
+                                 sold              NUMERIC NOT NULL DEFAULT 0,  -- retail units sold
+                                 professional_use  NUMERIC NOT NULL DEFAULT 0,
+                                 shrinkage         NUMERIC NOT NULL DEFAULT 0,
+                                 unmatched_sales   NUMERIC NOT NULL DEFAULT 0,  -- sold beyond recorded decreases
+                                 unit_cost         NUMERIC,
+                                 professional_cost NUMERIC NOT NULL DEFAULT 0,
+                                 shrinkage_cost    NUMERIC NOT NULL DEFAULT 0,
+                                 flagged           BOOLEAN NOT NULL DEFAULT FALSE,
+                                 computed_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+                                 PRIMARY KEY (branch_id, product_id, period_start, period_end)
+);
+
+CREATE INDEX idx_stock_shrinkage_period ON stock_shrinkage (branch_id, period_start, period_end);
+CREATE INDEX idx_stock_shrinkage_flagged ON stock_shrinkage (branch_id, period_end DESC) WHERE flagged;
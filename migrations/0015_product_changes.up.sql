@@ -0,0 +1,18 @@
+-- Change-data-capture for ph_products and ph_product_stock: one row per
+-- changed field per product per sync (price, reorder cost, min/max,
+-- archived, ...). Product master changes carry the branch whose sync saw
+-- them. New rows aren't logged; their first values are in the tables.
+CREATE TABLE product_changes (
+                                 id         BIGSERIAL PRIMARY KEY,
+                                 entity     TEXT NOT NULL,           -- 'product' (ph_products), 'stock' (ph_product_stock)
+                                 product_id TEXT NOT NULL,
+                                 branch_id  TEXT NOT NULL,
+                                 field      TEXT NOT NULL,           -- column name, e.g. 'price', 'reorder_cost', 'archived'
+                                 old_value  TEXT,
+                                 new_value  TEXT,
+                                 run_id     BIGINT REFERENCES sync_runs(id) ON DELETE SET NULL,
+                                 changed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_product_changes_product ON product_changes (product_id, changed_at DESC);
+CREATE INDEX idx_product_changes_branch_field ON product_changes (branch_id, field, changed_at DESC);
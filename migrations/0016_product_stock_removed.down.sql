@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_ph_product_stock_removed;
+ALTER TABLE ph_product_stock
+    DROP COLUMN IF EXISTS removed_at;
@@ -0,0 +1,7 @@
+-- Set by the products reconcile when Phorest stops listing a product for a
+-- branch; cleared if it comes back. Removed rows are left out of stock
+-- reports, and a zero-quantity history row takes them out of valuations.
+ALTER TABLE ph_product_stock
+    ADD COLUMN removed_at TIMESTAMPTZ;
+
+CREATE INDEX idx_ph_product_stock_removed ON ph_product_stock (branch_id) WHERE removed_at IS NOT NULL;
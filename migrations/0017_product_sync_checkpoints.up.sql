@@ -0,0 +1,17 @@
+-- Where an interrupted products sync got to. Each API page is written in
+-- one transaction together with this row, so a rerun resumes after the
+-- last committed page with the same window. Deleted when the branch finishes.
+CREATE TABLE product_sync_checkpoints (
+                                          branch_id      TEXT PRIMARY KEY,
+                                          product_type   TEXT NOT NULL DEFAULT '',  -- PRODUCT_TYPE_FILTER in effect
+                                          reconcile      BOOLEAN NOT NULL DEFAULT FALSE,
+                                          updated_after  TIMESTAMPTZ,                -- NULL = unfiltered listing
+                                          updated_before TIMESTAMPTZ,
+                                          next_page      INT NOT NULL,
+                                          total_pages    INT NOT NULL DEFAULT 0,
+                                          processed      INT NOT NULL DEFAULT 0,
+                                          max_updated_at TIMESTAMPTZ,                -- newest product updatedAt committed so far
+                                          run_id         BIGINT REFERENCES sync_runs(id) ON DELETE SET NULL,
+                                          started_at     TIMESTAMPTZ NOT NULL,
+                                          updated_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,34 @@
+-- Appointments from the Phorest appointment API (one row per booked service).
+-- start_at/end_at are the branch's wall-clock times, stored without a zone.
+CREATE TABLE appointments (
+                              appointment_id     TEXT PRIMARY KEY,
+                              branch_id          TEXT NOT NULL,
+                              version            BIGINT,
+                              appointment_date   DATE NOT NULL,
+                              start_at           TIMESTAMP NOT NULL,
+                              end_at             TIMESTAMP NOT NULL,
+                              client_id          TEXT,
+                              staff_id           TEXT,
+                              service_id         TEXT,
+                              service_name       TEXT,
+                              room_id            TEXT,
+                              booking_id         TEXT,
+                              price              NUMERIC(12,2),
+                              deposit_amount     NUMERIC(12,2),
+                              state              TEXT,                           -- BOOKED, CHECKED_IN, PAID, ...
+                              activation_state   TEXT,                           -- ACTIVE, CANCELED, RESERVED
+                              source             TEXT,                           -- booking channel as sent by Phorest
+                              booked_online      BOOLEAN NOT NULL DEFAULT FALSE,
+                              staff_request      BOOLEAN NOT NULL DEFAULT FALSE,
+                              confirmed          BOOLEAN NOT NULL DEFAULT FALSE,
+                              cancelled          BOOLEAN NOT NULL DEFAULT FALSE,
+                              no_show            BOOLEAN NOT NULL DEFAULT FALSE,
+                              created_at_phorest TIMESTAMPTZ,
+                              updated_at_phorest TIMESTAMPTZ,
+                              created_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+                              updated_at         TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_appointments_branch_date ON appointments (branch_id, appointment_date);
+CREATE INDEX idx_appointments_staff_date ON appointments (staff_id, appointment_date);
+CREATE INDEX idx_appointments_client ON appointments (client_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS staff_time_off;
+DROP TABLE IF EXISTS staff_breaks;
+DROP TABLE IF EXISTS staff_work_timetables;
@@ -0,0 +1,41 @@
+-- Staff rosters from the Phorest API. Each sync replaces a branch's rows
+-- in the window it fetched. Times are the branch's wall clock, no zone.
+
+-- Rostered working time (a split shift is two rows)
+CREATE TABLE staff_work_timetables (
+                                       id         BIGSERIAL PRIMARY KEY,
+                                       branch_id  TEXT NOT NULL,
+                                       staff_id   TEXT NOT NULL,
+                                       work_date  DATE NOT NULL,
+                                       start_at   TIMESTAMP NOT NULL,
+                                       end_at     TIMESTAMP NOT NULL,
+                                       synced_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX idx_staff_work_timetables_branch_date ON staff_work_timetables (branch_id, work_date);
+CREATE INDEX idx_staff_work_timetables_staff_date ON staff_work_timetables (staff_id, work_date);
+
+-- Breaks within rostered time
+CREATE TABLE staff_breaks (
+                              id         BIGSERIAL PRIMARY KEY,
+                              branch_id  TEXT NOT NULL,
+                              staff_id   TEXT NOT NULL,
+                              break_date DATE NOT NULL,
+                              start_at   TIMESTAMP NOT NULL,
+                              end_at     TIMESTAMP NOT NULL,
+                              label      TEXT,
+                              paid       BOOLEAN NOT NULL DEFAULT FALSE,
+                              synced_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX idx_staff_breaks_branch_date ON staff_breaks (branch_id, break_date);
+
+-- Holidays, sickness, training... (may span several days)
+CREATE TABLE staff_time_off (
+                                id         BIGSERIAL PRIMARY KEY,
+                                branch_id  TEXT NOT NULL,
+                                staff_id   TEXT NOT NULL,
+                                start_at   TIMESTAMP NOT NULL,
+                                end_at     TIMESTAMP NOT NULL,
+                                reason     TEXT,
+                                synced_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX idx_staff_time_off_branch_start ON staff_time_off (branch_id, start_at);
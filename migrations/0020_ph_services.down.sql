@@ -0,0 +1,4 @@
+DROP VIEW IF EXISTS dv_service_items;
+DROP INDEX IF EXISTS idx_transaction_items_branch_service;
+DROP TABLE IF EXISTS ph_service_history;
+DROP TABLE IF EXISTS ph_services;
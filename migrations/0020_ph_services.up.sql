@@ -0,0 +1,75 @@
+-- 1) Service catalogue per branch
+CREATE TABLE ph_services (
+                             id               TEXT NOT NULL,              -- serviceId from Phorest
+                             branch_id        TEXT NOT NULL,
+                             name             TEXT NOT NULL,
+                             category_id      TEXT,
+                             category_name    TEXT,
+                             duration_minutes INT,
+                             price            NUMERIC,                    -- list price
+                             archived         BOOLEAN NOT NULL DEFAULT FALSE,
+                             created_at_ph    TIMESTAMPTZ,
+                             updated_at_ph    TIMESTAMPTZ,
+                             last_synced_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+                             PRIMARY KEY (id, branch_id)
+);
+
+CREATE INDEX idx_ph_services_category ON ph_services(branch_id, category_name);
+
+
+-- 2) Catalogue history (append-only): a row whenever name, category,
+--    duration, list price or archived status changes
+CREATE TABLE ph_service_history (
+                                    id               BIGSERIAL PRIMARY KEY,
+                                    service_id       TEXT NOT NULL,
+                                    branch_id        TEXT NOT NULL,
+                                    snapshot_time    TIMESTAMPTZ NOT NULL DEFAULT now(),
+                                    name             TEXT NOT NULL,
+                                    category_name    TEXT,
+                                    duration_minutes INT,
+                                    price            NUMERIC,
+                                    archived         BOOLEAN NOT NULL DEFAULT FALSE,
+                                    run_id           BIGINT REFERENCES sync_runs(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_ph_service_hist_branch_service_time
+    ON ph_service_history (branch_id, service_id, snapshot_time);
+
+CREATE INDEX idx_transaction_items_branch_service
+    ON transaction_items (branch_id, service_id);
+
+
+-- 3) Service lines with the catalogue entry and the list price in force on
+--    the day they were sold (the current price when the sale predates the
+--    first synced snapshot)
+CREATE VIEW dv_service_items AS
+SELECT
+    ti.id                                AS item_id,
+    ti.branch_id,
+    ti.transaction_id,
+    ti.purchased_date,
+    ti.staff_id,
+    TRIM(CONCAT(ti.staff_first_name, ' ', ti.staff_last_name)) AS staff_name,
+    ti.service_id,
+    COALESCE(s.name, ti.service_name)    AS service_name,
+    COALESCE(s.category_name, ti.service_category_name) AS category_name,
+    s.duration_minutes,
+    COALESCE(NULLIF(ti.quantity, 0), 1)  AS quantity,
+    COALESCE(h.price, s.price)           AS list_price,
+    h.price IS NOT NULL                  AS list_price_at_sale,
+    COALESCE(ti.total_amount, 0)         AS realised
+FROM transaction_items ti
+LEFT JOIN ph_services s
+       ON s.id = ti.service_id AND s.branch_id = ti.branch_id
+LEFT JOIN LATERAL (
+    SELECT price
+    FROM ph_service_history sh
+    WHERE sh.branch_id = ti.branch_id
+      AND sh.service_id = ti.service_id
+      AND sh.snapshot_time < ti.purchased_date + 1
+    ORDER BY sh.snapshot_time DESC
+    LIMIT 1
+) h ON TRUE
+WHERE ti.item_type = 'SERVICE'
+  AND COALESCE(ti.void, 0) = 0;